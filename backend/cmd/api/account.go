@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/password"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+const (
+	minPasswordLength    = 8
+	verificationTokenTTL = 72 * time.Hour
+	passwordResetTTL     = 45 * time.Minute
+)
+
+// accountLinkEmail renders the account emails: a greeting, one paragraph and
+// a single call-to-action button.
+func accountLinkEmail(name, heading, message, buttonLabel, link, footer string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+  body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+  .container { max-width: 600px; margin: 0 auto; padding: 20px; border: 1px solid #e0e0e0; border-radius: 8px; }
+  h1 { color: #00a5ad; font-size: 22px; }
+  .button { display: inline-block; padding: 10px 20px; background-color: #00a5ad; color: #ffffff !important; text-decoration: none; border-radius: 6px; }
+  .muted { color: #777; font-size: 13px; }
+</style>
+</head>
+<body>
+<div class="container">
+  <h1>%s</h1>
+  <p>Hi %s,</p>
+  <p>%s</p>
+  <p><a class="button" href="%s">%s</a></p>
+  <p class="muted">If the button doesn't work, paste this link into your browser:<br>%s</p>
+  <p class="muted">%s</p>
+  <p>Warmly,<br>I Dream of Home Rescue Team</p>
+</div>
+</body>
+</html>`,
+		html.EscapeString(heading), html.EscapeString(name), message,
+		html.EscapeString(link), html.EscapeString(buttonLabel), html.EscapeString(link),
+		footer,
+	)
+}
+
+// sendAccountEmail sends through the mailer, or just logs in development.
+func (app *application) sendAccountEmail(to, subject, body string) error {
+	if app.config.smtp.password == "" || app.config.smtp.username == "" {
+		app.logger.Info("Development Mode: Simulating sending email", "recipient", to, "subject", subject)
+		return nil
+	}
+	return app.mailer.Send(to, subject, body, nil)
+}
+
+// frontendLink builds a link to a frontend page with the token in the query.
+func (app *application) frontendLink(path, token string) string {
+	return strings.TrimRight(app.config.frontendURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// sendVerificationEmail emails a link confirming the user's address. changed
+// says whether an existing account just switched to it.
+func (app *application) sendVerificationEmail(user *data.User, changed bool) error {
+	token, err := app.models.Tokens.New(user.ID, data.ScopeVerification, verificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := app.frontendLink("/verify-email", token)
+	if app.config.env == "development" {
+		app.logger.Info("Verification link", "email", user.Email, "link", link)
+	}
+
+	message := "Please confirm this is your email address so you can sign in."
+	footer := "This link expires in 3 days. If you didn't create an account you can ignore this email."
+	if changed {
+		message = "The email address on your account was changed to this one. Please confirm it's yours."
+		footer = "This link expires in 3 days. If you didn't make this change, contact us."
+	}
+
+	body := accountLinkEmail(user.Name, "Confirm your email address", message, "Confirm email", link, footer)
+
+	return app.sendAccountEmail(user.Email, "Confirm your email address", body)
+}
+
+// verifyEmailHandler consumes a verification token and marks the address as
+// confirmed.
+func (app *application) verifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Token string `json:"token"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Token != "", "token", "must be provided")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	userID, err := app.models.Tokens.Consume(data.ScopeVerification, input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired verification token")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	verified, err := app.models.Users.MarkEmailVerified(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if verified {
+		app.logger.Info("Email verified", "user_id", userID)
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "email address verified"})
+}
+
+// resendVerificationHandler always answers the same way so it can't be used
+// to discover which addresses have accounts.
+func (app *application) resendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email string `json:"email"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(validator.Matches(input.Email, validator.EmailRX), "email", "must be a valid email address")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := app.models.Users.GetByEmail(input.Email)
+	switch {
+	case err == nil && user.EmailVerifiedAt == nil:
+		// Only the newest link should work
+		if err := app.models.Tokens.DeleteAllForUser(data.ScopeVerification, user.ID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if err := app.sendVerificationEmail(user, false); err != nil {
+			app.logger.Error("Failed to send verification email", "user_id", user.ID, "error", err)
+		}
+	case err != nil && !errors.Is(err, data.ErrRecordNotFound):
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusAccepted, envelope{"message": "if that address needs verifying, an email is on its way"})
+}
+
+// requestPasswordResetHandler emails a reset link to active accounts. The
+// response is identical whether or not the address exists.
+func (app *application) requestPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email string `json:"email"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(validator.Matches(input.Email, validator.EmailRX), "email", "must be a valid email address")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := app.models.Users.GetByEmail(input.Email)
+	switch {
+	case err == nil && user.Activated:
+		token, err := app.models.Tokens.New(user.ID, data.ScopePasswordReset, passwordResetTTL)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		link := app.frontendLink("/reset-password", token)
+		if app.config.env == "development" {
+			app.logger.Info("Password reset link", "email", user.Email, "link", link)
+		}
+
+		body := accountLinkEmail(user.Name, "Reset your password",
+			"We received a request to reset the password for your account. Choose a new one using the button below.",
+			"Reset password", link, "This link expires in 45 minutes and can only be used once. If you didn't ask for a reset, you can ignore this email; your password won't change.")
+
+		if err := app.sendAccountEmail(user.Email, "Reset your password", body); err != nil {
+			app.logger.Error("Failed to send password reset email", "user_id", user.ID, "error", err)
+		}
+	case err != nil && !errors.Is(err, data.ErrRecordNotFound):
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusAccepted, envelope{"message": "if an account exists for that address, a reset link is on its way"})
+}
+
+// resetPasswordHandler sets a new password from a reset token and signs the
+// user out everywhere.
+func (app *application) resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Token != "", "token", "must be provided")
+	v.Check(len(input.Password) >= minPasswordLength, "password", fmt.Sprintf("must be at least %d characters", minPasswordLength))
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	userID, err := app.models.Tokens.Consume(data.ScopePasswordReset, input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired password reset token")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user, err := app.models.Users.Get(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	user.PasswordHash, err = password.HashPassword(input.Password)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Users.Update(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = app.models.Sessions.DeleteAllForUser(user.ID, ""); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if err = app.models.Tokens.DeleteAllForUser(data.ScopePasswordReset, user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Proving control of the mailbox is enough to lift a brute-force lock
+	if user.Locked(time.Now()) && !app.unlockUser(w, r, user, false) {
+		return
+	}
+
+	app.logger.Info("Password reset", "user_id", user.ID)
+
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "password updated, please sign in again"})
+}
@@ -128,6 +128,8 @@ func (app *application) submitAdoptionApplication(w http.ResponseWriter, r *http
   </div>
 `)
 
+		sb.WriteString(returnFlagsHTML(app.adopterReturnFlags(safeStr(input.Email), safeStr(input.PhoneNumber))))
+
 		sb.WriteString(`
 		<h2>Personal Information</h2>`)
 		fmt.Fprintf(&sb, `<div class="field"><span class="label">Name:</span> %s %s</div>`, input.FirstName, input.LastName)
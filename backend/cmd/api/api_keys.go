@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+func (app *application) listAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := app.models.APIKeys.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{
+		"apiKeys": keys,
+		"scopes":  data.APIKeyScopes,
+	})
+}
+
+// createAPIKeyHandler returns the plaintext key once; only its hash is kept.
+func (app *application) createAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name          string   `json:"name"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int      `json:"expiresInDays"` // 0 = never
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Name != "", "name", "must be provided")
+	v.Check(len(input.Name) <= 100, "name", "must not be more than 100 characters")
+	v.Check(len(input.Scopes) > 0, "scopes", "must contain at least one scope")
+	for _, scope := range input.Scopes {
+		v.Check(data.IsPermittedValue(scope, data.APIKeyScopes...), "scopes", "contains an unknown scope: "+scope)
+	}
+	v.Check(validator.Unique(input.Scopes), "scopes", "must not contain duplicate values")
+	v.Check(input.ExpiresInDays >= 0 && input.ExpiresInDays <= 730, "expiresInDays", "must be between 0 and 730")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	createdBy := app.contextGetUser(r)
+	key := &data.APIKey{
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		CreatedBy: &createdBy,
+	}
+	if input.ExpiresInDays > 0 {
+		expires := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		key.ExpiresAt = &expires
+	}
+
+	plaintext, err := app.models.APIKeys.Insert(key)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.audit(r, data.AuditCreate, "api_key", key.ID, nil, key)
+
+	app.logger.Info("API key created", "key_id", key.ID, "name", key.Name, "scopes", key.Scopes, "by", createdBy)
+
+	app.JSONResponse(w, http.StatusCreated, envelope{
+		"apiKey": key,
+		"key":    plaintext,
+	})
+}
+
+func (app *application) revokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.APIKeys.Revoke(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, "revoke", "api_key", id, nil, nil)
+
+	app.logger.Info("API key revoked", "key_id", id, "by", app.contextGetUser(r))
+
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "api key revoked"})
+}
+
+// getKennelCardHandler serves what the e-ink card on a kennel door shows:
+// the public profile plus the care notes volunteers need at the kennel.
+func (app *application) getKennelCardHandler(w http.ResponseWriter, r *http.Request) {
+	pet, err := app.models.Pets.Get(r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"card": pet.KennelCard()})
+}
@@ -59,6 +59,7 @@ func (app *application) listApplicationsHandler(w http.ResponseWriter, r *http.R
 		app.serverErrorResponse(w, r, err)
 		return
 	}
+	app.flagApplications(applications)
 
 	err = app.writeJSON(w, http.StatusOK, envelope{"applications": applications, "metadata": metadata}, nil)
 	if err != nil {
@@ -84,6 +85,12 @@ func (app *application) updateApplicationStatusHandler(w http.ResponseWriter, r
 		return
 	}
 
+	if app.preconditionFailed(w, r, int(application.Version)) {
+		return
+	}
+
+	before := *application
+
 	var input struct {
 		Status string `json:"status"`
 	}
@@ -95,6 +102,7 @@ func (app *application) updateApplicationStatusHandler(w http.ResponseWriter, r
 	}
 
 	v := validator.New()
+	previousStatus := application.Status
 	if input.Status != "" {
 		application.Status = input.Status
 	}
@@ -132,12 +140,17 @@ func (app *application) updateApplicationStatusHandler(w http.ResponseWriter, r
 				Birthday:              volunteerData.Birthday,
 				EmergencyContactName:  volunteerData.EmergencyContactName,
 				EmergencyContactPhone: volunteerData.EmergencyContactPhone,
+				ParentName:            volunteerData.ParentName,
+				ParentEmail:           volunteerData.ParentEmail,
+				ParentPhone:           volunteerData.ParentPhone,
 				VolunteerExperience:   volunteerData.VolunteerExperience,
 				InterestReason:        volunteerData.InterestReason,
 				Skills:                []string{},
 				Badges:                []string{},
 			}
-			_ = app.models.Volunteers.InsertGetId(newVolunteer)
+			if err := app.models.Volunteers.InsertGetId(newVolunteer); err == nil {
+				app.audit(r, data.AuditCreate, "volunteer", newVolunteer.ID, nil, newVolunteer)
+			}
 			app.logger.Info("Auto-created volunteer from approved application", "id", newVolunteer.ID)
 		} else {
 			app.logger.Error("Failed to unmarshal volunteer data for automation", "error", err)
@@ -156,6 +169,8 @@ func (app *application) updateApplicationStatusHandler(w http.ResponseWriter, r
 			// Find Pet
 			pet, err := app.models.Pets.GetByName(adoptionData.CatPreferenceName)
 			if err == nil && pet != nil {
+				petBefore := *pet
+
 				// Update Status
 				var detailsMap map[string]interface{}
 				_ = json.Unmarshal(pet.Details, &detailsMap)
@@ -170,6 +185,7 @@ func (app *application) updateApplicationStatusHandler(w http.ResponseWriter, r
 				if err != nil {
 					app.logger.Error("Failed to auto-update pet status", "pet", pet.Name, "error", err)
 				} else {
+					app.audit(r, data.AuditUpdate, "pet", pet.ID, &petBefore, pet)
 					app.logger.Info("Auto-updated pet status to adopted", "pet", pet.Name)
 				}
 			} else {
@@ -189,6 +205,15 @@ func (app *application) updateApplicationStatusHandler(w http.ResponseWriter, r
 		return
 	}
 
+	app.audit(r, data.AuditUpdate, "application", application.ID, &before, application)
+
+	// Automation: If approving a foster application, create the foster home and send onboarding materials.
+	// Only once the approval is saved, so a conflicting edit can't leave a foster home behind.
+	if application.Type == "foster" && application.Status == "approved" && previousStatus != "approved" {
+		app.approveFosterApplication(r, application)
+	}
+
+	app.setETag(w, int(application.Version))
 	err = app.writeJSON(w, http.StatusOK, envelope{"application": application}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -311,6 +336,17 @@ func (app *application) resendApplicationEmailHandler(w http.ResponseWriter, r *
 			subject = "New Surrender Application (Resent)"
 		}
 
+	case "foster":
+		var d data.FosterApplication
+		if err := json.Unmarshal(application.Data, &d); err == nil {
+			subject = fmt.Sprintf("New Foster Application: %s %s", d.FirstName, d.LastName)
+			if sig := signatureBytes(d.SignatureData); sig != nil {
+				attachments["signature.png"] = sig
+			}
+		} else {
+			subject = "New Foster Application (Resent)"
+		}
+
 	default:
 		subject = fmt.Sprintf("Application Details (Resent): %s", application.Type)
 	}
@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"net/http"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
 )
 
 type contextKey string
@@ -10,6 +12,9 @@ type contextKey string
 const (
 	requestIDKey contextKey = "requestID"
 	userIDKey    contextKey = "userID"
+	userRoleKey  contextKey = "userRole"
+	sessionKey   contextKey = "session"
+	apiKeyKey    contextKey = "apiKey"
 )
 
 func (app *application) contextSetRequestID(r *http.Request, id string) *http.Request {
@@ -37,3 +42,42 @@ func (app *application) contextGetUser(r *http.Request) string {
 	}
 	return userID
 }
+
+func (app *application) contextSetRole(r *http.Request, role string) *http.Request {
+	ctx := context.WithValue(r.Context(), userRoleKey, role)
+	return r.WithContext(ctx)
+}
+
+func (app *application) contextGetRole(r *http.Request) string {
+	role, ok := r.Context().Value(userRoleKey).(string)
+	if !ok {
+		return ""
+	}
+	return role
+}
+
+func (app *application) contextSetSession(r *http.Request, session *data.Session) *http.Request {
+	ctx := context.WithValue(r.Context(), sessionKey, session)
+	return r.WithContext(ctx)
+}
+
+func (app *application) contextGetSession(r *http.Request) *data.Session {
+	session, ok := r.Context().Value(sessionKey).(*data.Session)
+	if !ok {
+		return nil
+	}
+	return session
+}
+
+func (app *application) contextSetAPIKey(r *http.Request, key *data.APIKey) *http.Request {
+	ctx := context.WithValue(r.Context(), apiKeyKey, key)
+	return r.WithContext(ctx)
+}
+
+func (app *application) contextGetAPIKey(r *http.Request) *data.APIKey {
+	key, ok := r.Context().Value(apiKeyKey).(*data.APIKey)
+	if !ok {
+		return nil
+	}
+	return key
+}
@@ -79,6 +79,12 @@ func (app *application) generateContractHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	app.audit(r, data.AuditCreate, "contract", application.ID, nil, envelope{
+		"applicationId": contract.ApplicationID,
+		"type":          contract.Type,
+		"expiresAt":     contract.ExpiresAt,
+	})
+
 	contractURL := fmt.Sprintf("https://adoption-os.com/contract/%s", token)
 
 	app.writeJSON(w, http.StatusCreated, envelope{
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/cconner57/adoption-os/backend/internal/data"
@@ -99,6 +100,8 @@ func (app *application) submitContractHandler(w http.ResponseWriter, r *http.Req
 	// But Pet struct has `Details json.RawMessage`.
 	// Let's modify the map.
 
+	wasAdopted := strings.EqualFold(pet.Status(), "adopted")
+
 	var detailsMap map[string]interface{}
 	if len(pet.Details) > 0 {
 		if err := json.Unmarshal(pet.Details, &detailsMap); err != nil {
@@ -134,5 +137,9 @@ func (app *application) submitContractHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if newPetStatus == "adopted" && !wasAdopted {
+		app.trackMicrochipRegistration(pet)
+	}
+
 	app.writeJSON(w, http.StatusOK, envelope{"message": "Contract submitted successfully", "status": newAppStatus}, nil)
 }
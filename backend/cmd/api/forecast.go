@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/services/forecast"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// forecastHandler projects the population over the next weeks (4 to 12,
+// default 8) so staff can see when to stop taking surrenders or plan an
+// adoption event.
+func (app *application) forecastHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	weeks := app.readInt(qs, "weeks", 8, v)
+	species := app.readString(qs, "species", "")
+
+	v.Check(weeks >= forecast.MinWeeks && weeks <= forecast.MaxWeeks, "weeks", "must be between 4 and 12")
+	if species != "" {
+		v.Check(validator.PermittedValue(species, data.Species...), "species", "must be cat or dog")
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	flows, err := app.models.Pets.Flow()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	census, err := app.models.Pets.Census(species)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"forecast": forecast.Project(flows, census, species, time.Now(), weeks)})
+}
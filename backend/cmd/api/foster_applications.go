@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/services/scoring"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+func (app *application) submitFosterApplication(w http.ResponseWriter, r *http.Request) {
+	var input data.FosterApplication
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Honeypot Check
+	if input.FaxNumber != "" {
+		app.logger.Warn("Bot detected: honeypot populated", "field", "fax_number", "ip", r.RemoteAddr)
+		// Fake success
+		app.JSONResponse(w, http.StatusCreated, map[string]string{"message": "Foster application submitted successfully"})
+		return
+	}
+
+	v := validator.New()
+	if data.ValidateFosterApplication(v, &input); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	score := scoring.Foster(&input)
+	input.Score = &score
+
+	body := fosterApplicationEmail(&input)
+
+	// Save to Database
+	appRecord := &data.Application{
+		Type:         "foster",
+		Status:       "pending",
+		Data:         []byte("{}"),
+		OriginalHTML: &body,
+	}
+
+	jsonData, err := json.Marshal(input)
+	if err == nil {
+		appRecord.Data = jsonData
+	}
+
+	err = app.models.Applications.Insert(appRecord)
+	if err != nil {
+		app.logger.Error("Failed to persist foster application", "error", err)
+	}
+
+	attachments := make(map[string][]byte)
+	if logoBytes := app.getLogoBytes(); logoBytes != nil {
+		attachments["logo.jpg"] = logoBytes
+	}
+	if sig := signatureBytes(input.SignatureData); sig != nil {
+		attachments["signature.png"] = sig
+	}
+
+	subject := fmt.Sprintf("New Foster Application: %s %s (score %d/%d)", input.FirstName, input.LastName, score.Total, score.Max)
+	err = app.mailer.Send(app.config.smtp.sender, subject, body, attachments)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Push notifications reach public subscribers too, so no applicant details
+	app.notifier.SendToAll("New foster application received")
+
+	app.JSONResponse(w, http.StatusCreated, map[string]string{"message": "Foster application submitted successfully"})
+}
+
+// signatureBytes decodes a "data:image/png;base64,..." signature, or returns
+// nil if there isn't a usable one.
+func signatureBytes(signatureData *string) []byte {
+	if signatureData == nil || *signatureData == "" {
+		return nil
+	}
+	parts := strings.Split(*signatureData, ",")
+	if len(parts) != 2 {
+		return nil
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	return sigBytes
+}
+
+// fosterApplicationEmail renders the staff notification, which is also kept
+// as the application's original HTML.
+func fosterApplicationEmail(a *data.FosterApplication) string {
+	yesNo := func(b bool) string {
+		if b {
+			return "Yes"
+		}
+		return "No"
+	}
+	yesNoPtr := func(b *bool) string {
+		if b == nil {
+			return "N/A"
+		}
+		return yesNo(*b)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+  body { font-family: Arial, sans-serif; color: #333; line-height: 1.6; }
+  .container { max-width: 600px; margin: 0 auto; padding: 20px; border: 1px solid #e0e0e0; border-radius: 8px; }
+  .header { text-align: center; margin-bottom: 30px; }
+  .logo { max-width: 150px; height: auto; }
+  h1 { color: #00a5ad; font-size: 24px; text-align: center; }
+  h2 { color: #00a5ad; font-size: 18px; border-bottom: 2px solid #00a5ad; padding-bottom: 5px; margin-top: 25px; }
+  .field { margin-bottom: 10px; }
+  .label { font-weight: bold; color: #555; }
+  .flag { color: #b00020; }
+  .footer { margin-top: 30px; font-size: 12px; color: #333; text-align: center; border-top: 1px solid #eee; padding-top: 10px; }
+</style>
+</head>
+<body>
+<div class="container">
+  <div class="header">
+    <img src="cid:logo.jpg" alt="IDOHR Logo" class="logo">
+  </div>
+  <h1>New Foster Application</h1>
+`)
+
+	field := func(label, value string) {
+		fmt.Fprintf(&sb, `<div class="field"><span class="label">%s:</span> %s</div>`, label, html.EscapeString(value))
+	}
+
+	if a.Score != nil {
+		fmt.Fprintf(&sb, `<h2>Score: %d / %d</h2>`, a.Score.Total, a.Score.Max)
+		for _, f := range a.Score.Factors {
+			field(f.Name, fmt.Sprintf("%d / %d", f.Points, f.Max))
+		}
+		for _, flag := range a.Score.Flags {
+			fmt.Fprintf(&sb, `<div class="field flag">&#9888; %s</div>`, html.EscapeString(flag))
+		}
+	}
+
+	sb.WriteString(`<h2>Contact</h2>`)
+	field("Name", a.FirstName+" "+a.LastName)
+	field("Email", a.Email)
+	field("Phone", a.PhoneNumber)
+	field("Address", fmt.Sprintf("%s, %s, %s %s", a.Address, a.City, a.State, a.Zip))
+
+	sb.WriteString(`<h2>Home Setup</h2>`)
+	field("Home Type", a.HomeType)
+	field("Own or Rent", a.HomeOwnership)
+	if a.HomeOwnership == "rent" {
+		field("Landlord Approval", yesNoPtr(a.LandlordApproval))
+		field("Landlord", strings.TrimSpace(a.LandlordName+" "+a.LandlordPhone))
+	}
+	for _, m := range a.HouseholdMembers {
+		field("Household", fmt.Sprintf("%d × %s %s", m.Count, m.Age, m.Gender))
+	}
+	field("Hours Home Alone", fmt.Sprintf("%d", a.HoursHomeAlone))
+	field("Isolation Room", yesNo(a.HasIsolationRoom))
+	if a.IsolationRoomDescription != "" {
+		field("Isolation Room Details", a.IsolationRoomDescription)
+	}
+
+	sb.WriteString(`<h2>Experience</h2>`)
+	field("Fostered Before", yesNo(a.FosteredBefore))
+	if a.FosterExperience != "" {
+		field("Foster Experience", a.FosterExperience)
+	}
+	field("Bottle Baby Experience", yesNo(a.BottleBabyExperience))
+	if a.BottleBabyDetails != "" {
+		field("Bottle Baby Details", a.BottleBabyDetails)
+	}
+	field("Other Pets", a.OtherPets)
+	field("Resident Pets Vaccinated", yesNoPtr(a.ResidentPetsVaccinated))
+	field("Can Transport to Vet", yesNo(a.CanTransportToVet))
+
+	sb.WriteString(`<h2>Preferences</h2>`)
+	field("Capacity", fmt.Sprintf("%d", a.Capacity))
+	field("Species", strings.Join(a.SpeciesPreferences, ", "))
+	field("Ages", strings.Join(a.AgePreferences, ", "))
+
+	sb.WriteString(`<h2>Agreement</h2>`)
+	field("Signed By", a.NameFull)
+	field("Date", a.SignatureDate)
+
+	fmt.Fprintf(&sb, `
+  <div class="footer">
+    This application was submitted via the I Dream of Home Rescue Foster Form.<br>
+    %s
+  </div>
+</div>
+</body>
+</html>`, time.Now().Format("Jan 02, 2006"))
+
+	return sb.String()
+}
+
+// approveFosterApplication creates the foster home for a newly approved
+// application, emails the onboarding packet and invites them to the portal.
+// Failures are logged rather than failing the status change.
+func (app *application) approveFosterApplication(r *http.Request, application *data.Application) {
+	var d data.FosterApplication
+	if err := json.Unmarshal(application.Data, &d); err != nil {
+		app.logger.Error("Failed to unmarshal foster data for automation", "error", err)
+		return
+	}
+
+	foster := d.FosterHome()
+	foster.Notes = fmt.Sprintf("Created from foster application #%d.", application.ID)
+	if d.Score != nil {
+		foster.Notes += fmt.Sprintf(" Score %d/%d.", d.Score.Total, d.Score.Max)
+		for _, flag := range d.Score.Flags {
+			foster.Notes += " " + flag + "."
+		}
+	}
+
+	v := validator.New()
+	if data.ValidateFosterHome(v, foster); !v.Valid() {
+		app.logger.Error("Approved foster application does not make a valid foster home", "appId", application.ID, "errors", v.Errors)
+		return
+	}
+
+	if err := app.models.Fosters.Insert(foster); err != nil {
+		app.logger.Error("Failed to auto-create foster home", "appId", application.ID, "error", err)
+		return
+	}
+	app.audit(r, data.AuditCreate, "foster_home", foster.ID, nil, foster)
+	app.logger.Info("Auto-created foster home from approved application", "id", foster.ID)
+
+	go app.sendFosterOnboardingEmail(&d)
+
+	field, message, err := app.inviteProblem(r, foster.Email, data.RoleFoster)
+	if err != nil || field != "" {
+		app.logger.Warn("Skipped foster portal invite", "fosterId", foster.ID, "reason", message, "error", err)
+		return
+	}
+	if _, err := app.createInvite(r, foster.Email, data.RoleFoster, 7, &foster.ID); err != nil {
+		app.logger.Error("Failed to invite foster to portal", "fosterId", foster.ID, "error", err)
+	}
+}
+
+func (app *application) sendFosterOnboardingEmail(d *data.FosterApplication) {
+	if d.Email == "" {
+		app.logger.Warn("Cannot send foster onboarding email: missing recipient email")
+		return
+	}
+
+	attachments := make(map[string][]byte)
+	if logoBytes := app.getLogoBytes(); logoBytes != nil {
+		attachments["logo.jpg"] = logoBytes
+	}
+
+	subject := fmt.Sprintf("Welcome to IDOHR Fostering, %s!", d.FirstName)
+
+	body := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+  body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+  .container { max-width: 600px; margin: 0 auto; padding: 20px; border: 1px solid #e0e0e0; border-radius: 8px; }
+  .header { text-align: center; margin-bottom: 30px; }
+  .logo { max-width: 150px; height: auto; margin-bottom: 20px; }
+  h1 { color: #00a5ad; }
+  h2 { color: #00a5ad; font-size: 18px; }
+  .content { font-size: 16px; }
+</style>
+</head>
+<body>
+<div class="container">
+  <div class="header">
+    <img src="cid:logo.jpg" alt="IDOHR Logo" class="logo">
+    <h1>Welcome, Foster Family!</h1>
+  </div>
+
+  <div class="content">
+    <p>Dear %s,</p>
+    <p>Congratulations! Your foster application has been approved.</p>
+
+    <h2>Next steps</h2>
+    <ol>
+      <li><strong>Set up your foster portal.</strong> You'll receive a separate email with a link to create your login. The portal is where you log weights, notes and photos for the cats in your care.</li>
+      <li><strong>Prepare your space.</strong> Every new foster starts in a quiet, separate room away from resident pets for at least two weeks.</li>
+      <li><strong>Meet your coordinator.</strong> Our foster coordinator will reach out to schedule a short orientation and match you with your first foster.</li>
+    </ol>
+
+    <h2>What we provide</h2>
+    <p>Food, litter, supplies and all veterinary care are covered by the rescue. Please only use our approved vets, and contact your coordinator right away if your foster seems unwell.</p>
+
+    <p>Thank you for opening your home. Fosters save lives!</p>
+
+    <p>Warmly,<br>I Dream of Home Rescue Team</p>
+  </div>
+</div>
+</body>
+</html>`, html.EscapeString(d.FirstName))
+
+	app.logger.Info("Sending foster onboarding email", "recipient", d.Email)
+	if err := app.mailer.Send(d.Email, subject, body, attachments); err != nil {
+		app.logger.Error("Failed to send foster onboarding email", "error", err)
+	}
+}
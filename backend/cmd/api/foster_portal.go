@@ -0,0 +1,378 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+	"github.com/google/uuid"
+)
+
+// portalFoster returns the foster home for the signed-in portal user,
+// writing the error response itself when it returns nil.
+func (app *application) portalFoster(w http.ResponseWriter, r *http.Request) *data.FosterHome {
+	foster, err := app.models.Fosters.GetForUser(app.contextGetUser(r))
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.JSONError(w, http.StatusForbidden, "No foster home is linked to this account")
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil
+	}
+
+	return foster
+}
+
+// portalPetID returns the {id} pet if it is currently placed with foster.
+// Pets elsewhere are reported as not found rather than forbidden.
+func (app *application) portalPetID(w http.ResponseWriter, r *http.Request, foster *data.FosterHome) (string, bool) {
+	petID := r.PathValue("id")
+
+	placed, err := app.models.Fosters.HasPet(foster.ID, petID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return "", false
+	}
+	if !placed {
+		app.notFoundResponse(w, r)
+		return "", false
+	}
+
+	return petID, true
+}
+
+// getFosterPortalHandler is the portal home page: the foster's details, the
+// pets with them now and their recent submissions.
+func (app *application) getFosterPortalHandler(w http.ResponseWriter, r *http.Request) {
+	foster := app.portalFoster(w, r)
+	if foster == nil {
+		return
+	}
+
+	pets, err := app.models.Fosters.Placements(foster.ID, false)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	updates, _, err := app.models.FosterUpdates.GetAll(data.FosterUpdateFilter{FosterID: foster.ID}, data.Filters{
+		Page: 1, PageSize: 20, Sort: "-created_at", SortSafelist: []string{"-created_at"},
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"foster": foster, "pets": pets, "updates": updates})
+}
+
+// listPortalUpdatesHandler lists the foster's own submissions.
+func (app *application) listPortalUpdatesHandler(w http.ResponseWriter, r *http.Request) {
+	foster := app.portalFoster(w, r)
+	if foster == nil {
+		return
+	}
+
+	qs := r.URL.Query()
+	v := validator.New()
+
+	filter := data.FosterUpdateFilter{
+		FosterID: foster.ID,
+		PetID:    app.readString(qs, "pet_id", ""),
+		Status:   app.readString(qs, "status", ""),
+	}
+
+	filters := data.Filters{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "page_size", 20, v),
+		Sort:         "-created_at",
+		SortSafelist: []string{"-created_at"},
+	}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	updates, metadata, err := app.models.FosterUpdates.GetAll(filter, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"updates": updates, "metadata": metadata})
+}
+
+// submitPortalUpdateHandler queues a weight entry or behavior note.
+func (app *application) submitPortalUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	foster := app.portalFoster(w, r)
+	if foster == nil {
+		return
+	}
+	petID, ok := app.portalPetID(w, r, foster)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Kind      string   `json:"kind"`
+		WeightLbs *float64 `json:"weightLbs"`
+		Note      string   `json:"note"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Note = strings.TrimSpace(input.Note)
+
+	v := validator.New()
+	v.Check(validator.PermittedValue(input.Kind, data.FosterUpdateWeight, data.FosterUpdateNote), "kind", "must be weight or note")
+	switch input.Kind {
+	case data.FosterUpdateWeight:
+		v.Check(input.WeightLbs != nil, "weightLbs", "must be provided")
+		if input.WeightLbs != nil {
+			v.Check(*input.WeightLbs > 0 && *input.WeightLbs < 100, "weightLbs", "must be between 0 and 100 lbs")
+		}
+	case data.FosterUpdateNote:
+		v.Check(input.Note != "", "note", "must be provided")
+		input.WeightLbs = nil
+	}
+	v.Check(len(input.Note) <= 2000, "note", "must be 2000 characters or fewer")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	submittedBy := app.contextGetUser(r)
+	update := &data.FosterUpdate{
+		FosterID:    foster.ID,
+		PetID:       petID,
+		Kind:        input.Kind,
+		WeightLbs:   input.WeightLbs,
+		Note:        input.Note,
+		SubmittedBy: &submittedBy,
+	}
+
+	if err := app.models.FosterUpdates.Insert(update); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusCreated, envelope{"update": update})
+}
+
+// uploadPortalPhotoHandler queues a photo. It goes through the same
+// processing as admin uploads but is only added to the pet's photos once
+// approved; the random file name keeps it unlisted until then.
+func (app *application) uploadPortalPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	foster := app.portalFoster(w, r)
+	if foster == nil {
+		return
+	}
+	petID, ok := app.portalPetID(w, r, foster)
+	if !ok {
+		return
+	}
+
+	// Block uploads in local development
+	if app.config.env != "production" {
+		app.badRequestResponse(w, r, fmt.Errorf("uploads are disabled in %s environment", app.config.env))
+		return
+	}
+
+	file, err := app.readImageUpload(w, r, "photo")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	note := strings.TrimSpace(r.FormValue("note"))
+	if len(note) > 2000 {
+		app.failedValidationResponse(w, r, map[string]string{"note": "must be 2000 characters or fewer"})
+		return
+	}
+
+	// Structure: [ASSETS_DIR]/pets/[id]/foster-updates/
+	dir := filepath.Join(app.config.assetsDir, "pets", petID, "foster-updates")
+	result, err := data.SaveAndProcessImage(file, uuid.New().String()+".jpg", dir)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	largeURL := fmt.Sprintf("pets/%s/foster-updates/%s", petID, result.LargePath)
+	thumbURL := fmt.Sprintf("pets/%s/foster-updates/%s", petID, result.ThumbnailPath)
+
+	submittedBy := app.contextGetUser(r)
+	update := &data.FosterUpdate{
+		FosterID:     foster.ID,
+		PetID:        petID,
+		Kind:         data.FosterUpdatePhoto,
+		Note:         note,
+		PhotoURL:     &largeURL,
+		ThumbnailURL: &thumbURL,
+		SubmittedBy:  &submittedBy,
+	}
+
+	if err := app.models.FosterUpdates.Insert(update); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusCreated, envelope{"update": update})
+}
+
+// listFosterUpdatesHandler is the coordinator review queue. It defaults to
+// pending submissions, oldest first.
+func (app *application) listFosterUpdatesHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	filter := data.FosterUpdateFilter{
+		Status:   app.readString(qs, "status", data.FosterUpdatePending),
+		FosterID: int64(app.readInt(qs, "foster_id", 0, v)),
+		PetID:    app.readString(qs, "pet_id", ""),
+		Kind:     app.readString(qs, "kind", ""),
+	}
+	if filter.Status == "all" {
+		filter.Status = ""
+	}
+
+	filters := data.Filters{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "page_size", 20, v),
+		Sort:         app.readString(qs, "sort", "created_at"),
+		SortSafelist: []string{"created_at", "-created_at"},
+	}
+
+	if filter.Status != "" {
+		v.Check(validator.PermittedValue(filter.Status, data.FosterUpdateStatuses...), "status", "invalid status")
+	}
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	updates, metadata, err := app.models.FosterUpdates.GetAll(filter, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"updates": updates, "metadata": metadata})
+}
+
+func (app *application) approveFosterUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	app.reviewFosterUpdate(w, r, data.FosterUpdateApproved)
+}
+
+func (app *application) rejectFosterUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	app.reviewFosterUpdate(w, r, data.FosterUpdateRejected)
+}
+
+func (app *application) reviewFosterUpdate(w http.ResponseWriter, r *http.Request, status string) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Note string `json:"note"`
+	}
+
+	// The note is optional, so an empty body is fine
+	if r.ContentLength != 0 {
+		if err := app.readJSON(w, r, &input); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	reviewer := app.contextGetUser(r)
+
+	// Approving writes to the pet, so keep its before state for the audit log
+	var petBefore *data.Pet
+	if status == data.FosterUpdateApproved {
+		if pending, err := app.models.FosterUpdates.Get(id); err == nil {
+			petBefore, _ = app.models.Pets.Get(pending.PetID)
+		}
+	}
+
+	var update *data.FosterUpdate
+	if status == data.FosterUpdateApproved {
+		update, err = app.models.FosterUpdates.Approve(id, reviewer, input.Note)
+	} else {
+		update, err = app.models.FosterUpdates.Reject(id, reviewer, input.Note)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrUpdateReviewed):
+			app.JSONError(w, http.StatusConflict, "This update has already been reviewed")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	action := "approve"
+	if status == data.FosterUpdateRejected {
+		action = "reject"
+	}
+	app.audit(r, action, "foster_update", update.ID, nil, update)
+
+	if petBefore != nil {
+		if pet, err := app.models.Pets.Get(update.PetID); err == nil {
+			app.audit(r, data.AuditUpdate, "pet", pet.ID, petBefore, pet)
+		} else {
+			app.logger.Error("Failed to load pet for foster update audit", "pet_id", update.PetID, "error", err)
+		}
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"update": update})
+}
+
+// inviteFosterHandler emails a foster home a portal invitation.
+func (app *application) inviteFosterHandler(w http.ResponseWriter, r *http.Request) {
+	foster := app.getFoster(w, r)
+	if foster == nil {
+		return
+	}
+
+	v := validator.New()
+	v.Check(foster.UserID == nil, "email", "this foster home already has a portal login")
+	v.Check(foster.Status == data.FosterStatusApproved, "status", "only approved foster homes can be invited")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	field, message, err := app.inviteProblem(r, foster.Email, data.RoleFoster)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if field != "" {
+		v.AddError(field, message)
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	invite, err := app.createInvite(r, foster.Email, data.RoleFoster, 7, &foster.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusCreated, envelope{"invite": invite})
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+func (app *application) createFosterHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name               string   `json:"name"`
+		Email              string   `json:"email"`
+		Phone              string   `json:"phone"`
+		Address            string   `json:"address"`
+		City               string   `json:"city"`
+		Zip                string   `json:"zip"`
+		Status             string   `json:"status"`
+		Capacity           *int     `json:"capacity"`
+		SpeciesPreferences []string `json:"speciesPreferences"`
+		AgePreferences     []string `json:"agePreferences"`
+		OtherPets          string   `json:"otherPets"`
+		Notes              string   `json:"notes"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	foster := &data.FosterHome{
+		Name:               input.Name,
+		Email:              input.Email,
+		Phone:              input.Phone,
+		Address:            input.Address,
+		City:               input.City,
+		Zip:                input.Zip,
+		Status:             input.Status,
+		Capacity:           1,
+		SpeciesPreferences: input.SpeciesPreferences,
+		AgePreferences:     input.AgePreferences,
+		OtherPets:          input.OtherPets,
+		Notes:              input.Notes,
+	}
+	if foster.Status == "" {
+		foster.Status = data.FosterStatusPending
+	}
+	if input.Capacity != nil {
+		foster.Capacity = *input.Capacity
+	}
+
+	v := validator.New()
+	if data.ValidateFosterHome(v, foster); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Fosters.Insert(foster); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.audit(r, data.AuditCreate, "foster_home", foster.ID, nil, foster)
+
+	w.Header().Set("Location", fmt.Sprintf("/v1/fosters/%d", foster.ID))
+	app.JSONResponse(w, http.StatusCreated, envelope{"foster": foster})
+}
+
+// listFostersHandler lists foster homes. has_room=true narrows to approved
+// homes with an open spot; species and age match homes that take them (or
+// have no preference).
+func (app *application) listFostersHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	filter := data.FosterFilter{
+		Search:   app.readString(qs, "search", ""),
+		Status:   app.readString(qs, "status", ""),
+		Species:  app.readString(qs, "species", ""),
+		AgeGroup: app.readString(qs, "age", ""),
+		HasRoom:  qs.Get("has_room") == "true",
+	}
+
+	filters := data.Filters{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "page_size", 20, v),
+		Sort:         app.readString(qs, "sort", "name"),
+		SortSafelist: []string{"id", "name", "created_at", "capacity", "current_pets", "-id", "-name", "-created_at", "-capacity", "-current_pets"},
+	}
+
+	if filter.Status != "" {
+		v.Check(validator.PermittedValue(filter.Status, data.FosterStatuses...), "status", "invalid status")
+	}
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	fosters, metadata, err := app.models.Fosters.GetAll(filter, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"fosters": fosters, "metadata": metadata})
+}
+
+// getFoster loads the foster home named by the {id} path parameter, writing
+// the error response itself when it returns nil.
+func (app *application) getFoster(w http.ResponseWriter, r *http.Request) *data.FosterHome {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil
+	}
+
+	foster, err := app.models.Fosters.Get(id)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil
+	}
+
+	return foster
+}
+
+func (app *application) getFosterHandler(w http.ResponseWriter, r *http.Request) {
+	foster := app.getFoster(w, r)
+	if foster == nil {
+		return
+	}
+
+	app.setETag(w, foster.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"foster": foster})
+}
+
+func (app *application) updateFosterHandler(w http.ResponseWriter, r *http.Request) {
+	foster := app.getFoster(w, r)
+	if foster == nil {
+		return
+	}
+
+	if app.preconditionFailed(w, r, foster.Version) {
+		return
+	}
+
+	before := *foster
+
+	var input struct {
+		Name               *string  `json:"name"`
+		Email              *string  `json:"email"`
+		Phone              *string  `json:"phone"`
+		Address            *string  `json:"address"`
+		City               *string  `json:"city"`
+		Zip                *string  `json:"zip"`
+		Status             *string  `json:"status"`
+		Capacity           *int     `json:"capacity"`
+		SpeciesPreferences []string `json:"speciesPreferences"`
+		AgePreferences     []string `json:"agePreferences"`
+		OtherPets          *string  `json:"otherPets"`
+		Notes              *string  `json:"notes"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Name != nil {
+		foster.Name = *input.Name
+	}
+	if input.Email != nil {
+		foster.Email = *input.Email
+	}
+	if input.Phone != nil {
+		foster.Phone = *input.Phone
+	}
+	if input.Address != nil {
+		foster.Address = *input.Address
+	}
+	if input.City != nil {
+		foster.City = *input.City
+	}
+	if input.Zip != nil {
+		foster.Zip = *input.Zip
+	}
+	if input.Status != nil {
+		foster.Status = *input.Status
+	}
+	if input.Capacity != nil {
+		foster.Capacity = *input.Capacity
+	}
+	if input.SpeciesPreferences != nil {
+		foster.SpeciesPreferences = input.SpeciesPreferences
+	}
+	if input.AgePreferences != nil {
+		foster.AgePreferences = input.AgePreferences
+	}
+	if input.OtherPets != nil {
+		foster.OtherPets = *input.OtherPets
+	}
+	if input.Notes != nil {
+		foster.Notes = *input.Notes
+	}
+
+	v := validator.New()
+	if data.ValidateFosterHome(v, foster); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Fosters.Update(foster); err != nil {
+		if errors.Is(err, data.ErrEditConflict) {
+			app.editConflictResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, data.AuditUpdate, "foster_home", foster.ID, before, foster)
+
+	app.setETag(w, foster.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"foster": foster})
+}
+
+// listFosterPetsHandler shows the pets currently with a foster home and how
+// much room is left; history=true adds past placements.
+func (app *application) listFosterPetsHandler(w http.ResponseWriter, r *http.Request) {
+	foster := app.getFoster(w, r)
+	if foster == nil {
+		return
+	}
+
+	placements, err := app.models.Fosters.Placements(foster.ID, r.URL.Query().Get("history") == "true")
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{
+		"foster":      foster,
+		"pets":        placements,
+		"capacity":    foster.Capacity,
+		"currentPets": foster.CurrentPets,
+		"openSpots":   foster.OpenSpots,
+	})
+}
+
+func (app *application) createPlacementHandler(w http.ResponseWriter, r *http.Request) {
+	foster := app.getFoster(w, r)
+	if foster == nil {
+		return
+	}
+
+	var input struct {
+		PetID        string `json:"petId"`
+		StartDate    string `json:"startDate"`
+		Notes        string `json:"notes"`
+		OverCapacity bool   `json:"overCapacity"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.StartDate == "" {
+		input.StartDate = time.Now().Format(time.DateOnly)
+	}
+
+	v := validator.New()
+	v.Check(input.PetID != "", "petId", "must be provided")
+	_, err := time.Parse(time.DateOnly, input.StartDate)
+	v.Check(err == nil, "startDate", "must be a YYYY-MM-DD date")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	placement, err := app.models.Fosters.Place(foster.ID, input.PetID, input.StartDate, input.Notes, input.OverCapacity)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("petId", "pet not found")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrFosterNotApproved):
+			app.JSONError(w, http.StatusConflict, "This foster home is not approved for placements")
+		case errors.Is(err, data.ErrFosterAtCapacity):
+			app.JSONError(w, http.StatusConflict, fmt.Sprintf("This foster home is full (%d of %d); set overCapacity to place anyway", foster.CurrentPets, foster.Capacity))
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, data.AuditCreate, "foster_placement", placement.ID, nil, placement)
+
+	app.JSONResponse(w, http.StatusCreated, envelope{"placement": placement})
+}
+
+func (app *application) endPlacementHandler(w http.ResponseWriter, r *http.Request) {
+	foster := app.getFoster(w, r)
+	if foster == nil {
+		return
+	}
+
+	placementID, err := strconv.ParseInt(r.PathValue("placementId"), 10, 64)
+	if err != nil || placementID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		EndDate string `json:"endDate"`
+		Reason  string `json:"reason"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.EndDate == "" {
+		input.EndDate = time.Now().Format(time.DateOnly)
+	}
+
+	v := validator.New()
+	_, err = time.Parse(time.DateOnly, input.EndDate)
+	v.Check(err == nil, "endDate", "must be a YYYY-MM-DD date")
+	v.Check(input.Reason != "", "reason", "must be provided")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	placement, err := app.models.Fosters.EndPlacement(foster.ID, placementID, input.EndDate, input.Reason)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, "end", "foster_placement", placement.ID, nil, placement)
+
+	app.JSONResponse(w, http.StatusOK, envelope{"placement": placement})
+}
+
+// listPetFostersHandler is a pet's foster placement history, newest first.
+func (app *application) listPetFostersHandler(w http.ResponseWriter, r *http.Request) {
+	placements, err := app.models.Fosters.PetHistory(r.PathValue("id"))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"placements": placements})
+}
@@ -7,7 +7,10 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cconner57/adoption-os/backend/internal/validator"
 )
@@ -167,3 +170,76 @@ func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request)
 func (app *application) readStringParam(r *http.Request, key string) string {
 	return r.PathValue(key)
 }
+
+// setETag exposes the record version so clients can send it back in If-Match.
+func (app *application) setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
+// readIfMatch parses the If-Match header into a record version. ok is false
+// when the header is absent or "*", in which case the write is unconditional.
+func (app *application) readIfMatch(r *http.Request) (version int, ok bool, err error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, false, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	tag = strings.Trim(tag, `"`)
+
+	version, err = strconv.Atoi(tag)
+	if err != nil || version < 1 {
+		return 0, false, errors.New("invalid If-Match header")
+	}
+
+	return version, true, nil
+}
+
+// preconditionFailed checks the If-Match header against the stored version and
+// writes the error response if the write should not go ahead.
+func (app *application) preconditionFailed(w http.ResponseWriter, r *http.Request, current int) bool {
+	expected, ok, err := app.readIfMatch(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return true
+	}
+
+	if ok && expected != current {
+		app.editConflictResponse(w, r)
+		return true
+	}
+
+	return false
+}
+
+// jsonEqual compares two JSON documents by value, so key order and whitespace
+// don't matter. Empty and null are equal.
+func jsonEqual(a, b json.RawMessage) bool {
+	var x, y any
+	if len(a) > 0 {
+		if err := json.Unmarshal(a, &x); err != nil {
+			return false
+		}
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &y); err != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// dailyJobsHour is the hour, server local time, the daily background jobs
+// run at.
+const dailyJobsHour = 7
+
+// nextDailyRun is the next time the daily jobs are due after now. Scheduling
+// by the clock rather than a 24-hour ticker means a restart doesn't skip a
+// day or run the jobs twice.
+func nextDailyRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -5,6 +5,7 @@ import (
 	"mime/multipart"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestValidateImageFile(t *testing.T) {
@@ -91,3 +92,67 @@ func TestValidateImageFile(t *testing.T) {
 		})
 	}
 }
+
+func TestReadIfMatch(t *testing.T) {
+	app := &application{}
+
+	tests := []struct {
+		header      string
+		wantVersion int
+		wantOK      bool
+		expectError bool
+	}{
+		{header: "", wantOK: false},
+		{header: "*", wantOK: false},
+		{header: `"3"`, wantVersion: 3, wantOK: true},
+		{header: `W/"12"`, wantVersion: 12, wantOK: true},
+		{header: "7", wantVersion: 7, wantOK: true},
+		{header: `"abc"`, expectError: true},
+		{header: `"0"`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("PUT", "/", nil)
+		if tt.header != "" {
+			r.Header.Set("If-Match", tt.header)
+		}
+
+		version, ok, err := app.readIfMatch(r)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("If-Match %q: expected error, got none", tt.header)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("If-Match %q: unexpected error: %v", tt.header, err)
+			continue
+		}
+		if version != tt.wantVersion || ok != tt.wantOK {
+			t.Errorf("If-Match %q: want (%d, %v); got (%d, %v)", tt.header, tt.wantVersion, tt.wantOK, version, ok)
+		}
+	}
+}
+
+func TestNextDailyRun(t *testing.T) {
+	loc := time.FixedZone("PT", -7*60*60)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"before the hour", time.Date(2026, 3, 10, 5, 30, 0, 0, loc), time.Date(2026, 3, 10, 7, 0, 0, 0, loc)},
+		{"on the hour", time.Date(2026, 3, 10, 7, 0, 0, 0, loc), time.Date(2026, 3, 11, 7, 0, 0, 0, loc)},
+		{"after the hour", time.Date(2026, 3, 10, 23, 59, 0, 0, loc), time.Date(2026, 3, 11, 7, 0, 0, 0, loc)},
+		{"end of month", time.Date(2026, 1, 31, 8, 0, 0, 0, loc), time.Date(2026, 2, 1, 7, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextDailyRun(tt.now, 7); !got.Equal(tt.want) {
+				t.Errorf("nextDailyRun(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultInviteDays = 2
+	maxInviteDays     = 30
+	maxBulkInvites    = 500
+	maxBulkInviteSize = 1 << 20 // 1 MB of CSV
+)
+
+// inviteProblem explains why one invite can't be created, as the input field
+// at fault and a message. An empty message means the invite is fine.
+func (app *application) inviteProblem(r *http.Request, email, roleName string) (field, message string, err error) {
+	if !validator.Matches(email, validator.EmailRX) {
+		return "email", "must be a valid email address", nil
+	}
+
+	role, ok := data.GetRole(roleName)
+	if !ok {
+		return "role", fmt.Sprintf("unknown role %q", roleName), nil
+	}
+	if role.Name == data.RoleSuperAdmin && !strings.EqualFold(app.contextGetRole(r), data.RoleSuperAdmin) {
+		return "role", "only a super admin can invite a super admin", nil
+	}
+
+	_, err = app.models.Users.GetByEmail(email)
+	switch {
+	case err == nil:
+		return "email", "already has an account", nil
+	case !errors.Is(err, data.ErrRecordNotFound):
+		return "", "", err
+	}
+
+	_, err = app.models.Invitations.GetPendingByEmail(email)
+	switch {
+	case err == nil:
+		return "email", "already has a pending invitation; resend it instead", nil
+	case !errors.Is(err, data.ErrRecordNotFound):
+		return "", "", err
+	}
+
+	return "", "", nil
+}
+
+// createInvite stores the invitation and emails the link. A failed email is
+// logged; the admin can resend. A foster portal invite names the foster home
+// the account is linked to once it's accepted.
+func (app *application) createInvite(r *http.Request, email, roleName string, days int, fosterID *int64) (*data.Invitation, error) {
+	role, _ := data.GetRole(roleName)
+	invitedBy := app.contextGetUser(r)
+
+	invite := &data.Invitation{
+		Token:     uuid.New().String(),
+		Email:     strings.ToLower(email),
+		Role:      role.Name,
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+		InvitedBy: &invitedBy,
+		FosterID:  fosterID,
+	}
+
+	if err := app.models.Invitations.Insert(invite); err != nil {
+		return nil, err
+	}
+
+	app.audit(r, data.AuditCreate, "invitation", invite.ID, nil, invite)
+
+	if err := app.sendInviteEmail(r, invite); err != nil {
+		app.logger.Error("Failed to send invitation email", "invite_id", invite.ID, "error", err)
+	}
+
+	return invite, nil
+}
+
+func (app *application) sendInviteEmail(r *http.Request, invite *data.Invitation) error {
+	link := app.frontendLink("/register", invite.Token)
+	if app.config.env == "development" {
+		app.logger.Info("Invite link", "email", invite.Email, "link", link)
+	}
+
+	inviter := "The I Dream of Home Rescue team"
+	if user, err := app.models.Users.Get(app.contextGetUser(r)); err == nil && user.Name != "" {
+		inviter = user.Name
+	}
+
+	role, _ := data.GetRole(invite.Role)
+	days := int(time.Until(invite.ExpiresAt).Hours()/24 + 0.5)
+
+	body := accountLinkEmail("there", "You're invited to join I Dream of Home Rescue",
+		fmt.Sprintf("%s has invited you to join our team as <strong>%s</strong>. Create your account to get started.",
+			html.EscapeString(inviter), html.EscapeString(role.Label)),
+		"Accept invitation", link,
+		fmt.Sprintf("This invitation expires in %d day(s) and only works for %s.", days, html.EscapeString(invite.Email)))
+
+	return app.sendAccountEmail(invite.Email, "You're invited to join I Dream of Home Rescue", body)
+}
+
+func (app *application) inviteUserHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email         string `json:"email"`
+		Role          string `json:"role"`
+		ExpiresInDays int    `json:"expiresInDays"` // 0 = default
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.ExpiresInDays == 0 {
+		input.ExpiresInDays = defaultInviteDays
+	}
+
+	v := validator.New()
+	v.Check(input.Email != "", "email", "must be provided")
+	v.Check(input.Role != "", "role", "must be provided")
+	v.Check(input.ExpiresInDays >= 1 && input.ExpiresInDays <= maxInviteDays, "expiresInDays", fmt.Sprintf("must be between 1 and %d", maxInviteDays))
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if role, ok := data.GetRole(input.Role); ok && role.Name == data.RoleSuperAdmin && !strings.EqualFold(app.contextGetRole(r), data.RoleSuperAdmin) {
+		app.JSONError(w, http.StatusForbidden, "Only a super admin can invite a super admin")
+		return
+	}
+
+	field, problem, err := app.inviteProblem(r, input.Email, input.Role)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if problem != "" {
+		v.AddError(field, problem)
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	invite, err := app.createInvite(r, input.Email, input.Role, input.ExpiresInDays, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusCreated, envelope{"invitation": invite}, nil)
+}
+
+// listInvitationsHandler shows pending invitations, and expired ones with
+// ?include_expired=true.
+func (app *application) listInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	includeExpired := app.readString(r.URL.Query(), "include_expired", "") == "true"
+
+	invites, err := app.models.Invitations.GetAll(includeExpired)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"invitations": invites})
+}
+
+// resendInvitationHandler issues a new link (the old one stops working),
+// optionally with a new expiry, and emails it again.
+func (app *application) resendInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		ExpiresInDays int `json:"expiresInDays"`
+	}
+
+	// The body is optional
+	if r.ContentLength != 0 {
+		if err := app.readJSON(w, r, &input); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if input.ExpiresInDays == 0 {
+		input.ExpiresInDays = defaultInviteDays
+	}
+
+	v := validator.New()
+	v.Check(input.ExpiresInDays >= 1 && input.ExpiresInDays <= maxInviteDays, "expiresInDays", fmt.Sprintf("must be between 1 and %d", maxInviteDays))
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	invite, err := app.models.Invitations.GetByID(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if invite.Role == data.RoleSuperAdmin && !strings.EqualFold(app.contextGetRole(r), data.RoleSuperAdmin) {
+		app.JSONError(w, http.StatusForbidden, "Only a super admin can invite a super admin")
+		return
+	}
+
+	before := *invite
+	invite.Token = uuid.New().String()
+	invite.ExpiresAt = time.Now().AddDate(0, 0, input.ExpiresInDays)
+
+	err = app.models.Invitations.Renew(invite)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, "resend", "invitation", invite.ID, &before, invite)
+
+	if err := app.sendInviteEmail(r, invite); err != nil {
+		app.logger.Error("Failed to resend invitation email", "invite_id", invite.ID, "error", err)
+		app.JSONError(w, http.StatusBadGateway, "The invitation was renewed but the email could not be sent")
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"invitation": invite})
+}
+
+func (app *application) revokeInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	invite, err := app.models.Invitations.GetByID(id)
+	if err == nil {
+		err = app.models.Invitations.DeleteByID(id)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, "revoke", "invitation", id, invite, nil)
+
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "invitation revoked"})
+}
+
+// bulkInviteResult reports one CSV row.
+type bulkInviteResult struct {
+	Line  int    `json:"line"`
+	Email string `json:"email"`
+	Role  string `json:"role,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// bulkInviteHandler invites everyone in an uploaded CSV ("file" field). Rows
+// are "email[,role]"; a header row is skipped and rows without a role use the
+// "role" form field. Bad rows are reported without stopping the rest.
+func (app *application) bulkInviteHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkInviteSize+4096)
+	if err := r.ParseMultipartForm(maxBulkInviteSize); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	defaultRole := r.FormValue("role")
+	days := defaultInviteDays
+	if s := r.FormValue("expiresInDays"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxInviteDays {
+			v := validator.New()
+			v.AddError("expiresInDays", fmt.Sprintf("must be between 1 and %d", maxInviteDays))
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+		days = n
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("a CSV file must be uploaded in the \"file\" field"))
+		return
+	}
+	defer file.Close()
+
+	rows, err := parseInviteCSV(file, defaultRole)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if len(rows) > maxBulkInvites {
+		app.badRequestResponse(w, r, fmt.Errorf("a CSV may contain at most %d invitations", maxBulkInvites))
+		return
+	}
+
+	seen := map[string]bool{}
+	results := make([]bulkInviteResult, 0, len(rows))
+	created := 0
+
+	for _, row := range rows {
+		email := strings.ToLower(row.Email)
+		switch {
+		case row.Error != "":
+		case seen[email]:
+			row.Error = "duplicate of an earlier row"
+		default:
+			seen[email] = true
+			_, problem, err := app.inviteProblem(r, row.Email, row.Role)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+			row.Error = problem
+		}
+
+		if row.Error == "" {
+			if _, err := app.createInvite(r, row.Email, row.Role, days, nil); err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+			role, _ := data.GetRole(row.Role)
+			row.Role = role.Name
+			created++
+		}
+		results = append(results, row)
+	}
+
+	app.logger.Info("Bulk invitations sent", "created", created, "rows", len(rows), "by", app.contextGetUser(r))
+
+	app.JSONResponse(w, http.StatusOK, envelope{
+		"created": created,
+		"failed":  len(rows) - created,
+		"results": results,
+	})
+}
+
+// parseInviteCSV reads "email[,role]" rows, skipping blank lines and a
+// header row.
+func parseInviteCSV(src io.Reader, defaultRole string) ([]bulkInviteResult, error) {
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []bulkInviteResult
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		row := bulkInviteResult{Line: line, Email: strings.TrimSpace(record[0]), Role: defaultRole}
+		if len(rows) == 0 && strings.EqualFold(row.Email, "email") {
+			continue
+		}
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			row.Role = strings.TrimSpace(record[1])
+		}
+		if row.Role == "" {
+			row.Error = "role is required (add a role column or choose a default)"
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
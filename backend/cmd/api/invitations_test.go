@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseInviteCSV(t *testing.T) {
+	csv := "Email,Role\n" +
+		"ana@example.com,ADMIN\n" +
+		"\n" +
+		"  ben@example.com\n" +
+		"cara@example.com, medical_lead\n"
+
+	rows, err := parseInviteCSV(strings.NewReader(csv), "VOLUNTEER_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []bulkInviteResult{
+		{Line: 2, Email: "ana@example.com", Role: "ADMIN"},
+		{Line: 4, Email: "ben@example.com", Role: "VOLUNTEER_1"},
+		{Line: 5, Email: "cara@example.com", Role: "medical_lead"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestParseInviteCSVNeedsRole(t *testing.T) {
+	rows, err := parseInviteCSV(strings.NewReader("ana@example.com\n"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Error == "" {
+		t.Errorf("want a missing-role error, got %+v", rows)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+func (app *application) listLocationsHandler(w http.ResponseWriter, r *http.Request) {
+	locations, err := app.models.Locations.GetAll(r.URL.Query().Get("inactive") == "true")
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"locations": locations})
+}
+
+func (app *application) createLocationHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name     string `json:"name"`
+		Kind     string `json:"kind"`
+		Capacity int    `json:"capacity"`
+		Notes    string `json:"notes"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	location := &data.ShelterLocation{
+		Name:     strings.TrimSpace(input.Name),
+		Kind:     input.Kind,
+		Capacity: input.Capacity,
+		Notes:    input.Notes,
+		Active:   true,
+	}
+	if location.Kind == "" {
+		location.Kind = data.LocationRoom
+	}
+
+	v := validator.New()
+	if data.ValidateShelterLocation(v, location); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Locations.Insert(location); err != nil {
+		if errors.Is(err, data.ErrDuplicateLocation) {
+			v.AddError("name", "a location with this name already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, data.AuditCreate, "shelter_location", location.ID, nil, location)
+
+	w.Header().Set("Location", fmt.Sprintf("/v1/locations/%d", location.ID))
+	app.JSONResponse(w, http.StatusCreated, envelope{"location": location})
+}
+
+// getLocation loads the location named by the {id} path parameter, writing
+// the error response itself when it returns nil.
+func (app *application) getLocation(w http.ResponseWriter, r *http.Request) *data.ShelterLocation {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil
+	}
+
+	location, err := app.models.Locations.Get(id)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil
+	}
+
+	return location
+}
+
+func (app *application) getLocationHandler(w http.ResponseWriter, r *http.Request) {
+	location := app.getLocation(w, r)
+	if location == nil {
+		return
+	}
+
+	app.setETag(w, location.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"location": location})
+}
+
+// updateLocationHandler edits a location. Lowering the capacity below who is
+// already there is allowed; the dashboard shows it as over.
+func (app *application) updateLocationHandler(w http.ResponseWriter, r *http.Request) {
+	location := app.getLocation(w, r)
+	if location == nil {
+		return
+	}
+
+	if app.preconditionFailed(w, r, location.Version) {
+		return
+	}
+
+	before := *location
+
+	var input struct {
+		Name     *string `json:"name"`
+		Kind     *string `json:"kind"`
+		Capacity *int    `json:"capacity"`
+		Notes    *string `json:"notes"`
+		Active   *bool   `json:"active"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Name != nil {
+		location.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Kind != nil {
+		location.Kind = *input.Kind
+	}
+	if input.Capacity != nil {
+		location.Capacity = *input.Capacity
+	}
+	if input.Notes != nil {
+		location.Notes = *input.Notes
+	}
+	if input.Active != nil {
+		location.Active = *input.Active
+	}
+
+	v := validator.New()
+	v.Check(location.Active || location.Occupied == 0, "active", "move the pets out before closing this location")
+	if data.ValidateShelterLocation(v, location); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Locations.Update(location); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateLocation):
+			v.AddError("name", "a location with this name already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, data.AuditUpdate, "shelter_location", location.ID, before, location)
+
+	app.setETag(w, location.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"location": location})
+}
+
+// occupancyHandler is the kennel dashboard: each location, who is in it and
+// how full it is, with shelter-wide totals.
+func (app *application) occupancyHandler(w http.ResponseWriter, r *http.Request) {
+	occupancy, err := app.models.Locations.Occupancy()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	type kindTotals struct {
+		Capacity int `json:"capacity"`
+		Occupied int `json:"occupied"`
+	}
+	totals := map[string]*kindTotals{}
+	var all kindTotals
+	for _, o := range occupancy {
+		t := totals[o.Kind]
+		if t == nil {
+			t = &kindTotals{}
+			totals[o.Kind] = t
+		}
+		t.Capacity += o.Capacity
+		t.Occupied += o.Occupied
+		all.Capacity += o.Capacity
+		all.Occupied += o.Occupied
+	}
+
+	warnings, err := app.models.Locations.IsolationWarnings(0)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"locations": occupancy, "byKind": totals, "totals": all, "warnings": warnings})
+}
+
+// movePetHandler puts a pet in a location, or takes it out of the building
+// when locationId is null. Moving into a full location is refused unless
+// overCapacity is set.
+func (app *application) movePetHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		LocationID   *int64 `json:"locationId"`
+		Notes        string `json:"notes"`
+		OverCapacity bool   `json:"overCapacity"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	petID := r.PathValue("id")
+
+	var location *data.ShelterLocation
+	if input.LocationID != nil {
+		var err error
+		location, err = app.models.Locations.Get(*input.LocationID)
+		if err != nil || !location.Active {
+			if err == nil || errors.Is(err, data.ErrRecordNotFound) {
+				app.failedValidationResponse(w, r, map[string]string{"locationId": "location not found"})
+			} else {
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+	}
+
+	stay, err := app.models.Locations.Move(petID, input.LocationID, input.Notes, app.contextGetUser(r), input.OverCapacity)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrLocationFull):
+			app.JSONError(w, http.StatusConflict, fmt.Sprintf("%s is full (%d of %d); set overCapacity to move anyway", location.Name, location.Occupied, location.Capacity))
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	warnings := []string{}
+	if location != nil && location.Kind == data.LocationIsolation {
+		if warning := location.Warning(1); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	app.audit(r, "move", "pet", petID, nil, stay)
+
+	app.JSONResponse(w, http.StatusOK, envelope{"location": stay, "warnings": warnings})
+}
+
+func (app *application) listPetLocationsHandler(w http.ResponseWriter, r *http.Request) {
+	history, err := app.models.Locations.PetHistory(r.PathValue("id"))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"locations": history})
+}
+
+// isolationWarnings is the capacity check for bringing new animals in, which
+// start in isolation. It never blocks the request: a failed lookup is logged
+// and reported as no warnings.
+func (app *application) isolationWarnings(adding int) []string {
+	warnings, err := app.models.Locations.IsolationWarnings(adding)
+	if err != nil {
+		app.logger.Error("Failed to check isolation capacity", "error", err)
+		return []string{}
+	}
+	return warnings
+}
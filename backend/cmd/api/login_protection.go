@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// loginAttemptRetention bounds the audit trail.
+const loginAttemptRetention = 90 * 24 * time.Hour
+
+// recordLoginAttempt logs an attempt for throttling and audit. A failure to
+// write is logged rather than failing the login.
+func (app *application) recordLoginAttempt(r *http.Request, email string, userID *string, success bool, reason string) {
+	attempt := &data.LoginAttempt{
+		Email:     email,
+		UserID:    userID,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+		Success:   success,
+		Reason:    reason,
+	}
+
+	app.insertLoginAttempt(attempt)
+}
+
+func (app *application) insertLoginAttempt(attempt *data.LoginAttempt) {
+	if err := app.models.LoginAttempts.Insert(attempt); err != nil {
+		app.logger.Error("Failed to record login attempt", "email", attempt.Email, "error", err)
+	}
+}
+
+func (app *application) tooManyLoginAttemptsResponse(w http.ResponseWriter, r *http.Request, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	app.JSONError(w, http.StatusTooManyRequests, fmt.Sprintf("Too many failed sign-in attempts. Try again in %d seconds.", seconds))
+}
+
+func (app *application) accountLockedResponse(w http.ResponseWriter, r *http.Request) {
+	app.JSONError(w, http.StatusLocked, "This account is temporarily locked after too many failed sign-in attempts. Check your email for an unlock link.")
+}
+
+// lockAccount locks the user out for AccountLockDuration and emails them a
+// link to unlock early.
+func (app *application) lockAccount(user *data.User, failures int) {
+	until := time.Now().Add(data.AccountLockDuration)
+	if err := app.models.Users.Lock(user.ID, until); err != nil {
+		app.logger.Error("Failed to lock account", "user_id", user.ID, "error", err)
+		return
+	}
+
+	app.logger.Warn("Account locked after failed logins", "user_id", user.ID, "failures", failures)
+
+	token, err := app.models.Tokens.New(user.ID, data.ScopeUnlock, data.AccountLockDuration)
+	if err != nil {
+		app.logger.Error("Failed to create unlock token", "user_id", user.ID, "error", err)
+		return
+	}
+
+	link := app.frontendLink("/unlock-account", token)
+	if app.config.env == "development" {
+		app.logger.Info("Unlock link", "email", user.Email, "link", link)
+	}
+
+	body := accountLinkEmail(user.Name, "Your account has been locked",
+		fmt.Sprintf("There were %d failed attempts to sign in to your account, so we've locked it for %d minutes. If that was you, you can unlock it now.",
+			failures, int(data.AccountLockDuration.Minutes())),
+		"Unlock my account", link,
+		"If you didn't try to sign in, someone may be guessing your password. We recommend resetting it after unlocking.")
+
+	if err := app.sendAccountEmail(user.Email, "Your account has been locked", body); err != nil {
+		app.logger.Error("Failed to send unlock email", "user_id", user.ID, "error", err)
+	}
+}
+
+// unlockAccountHandler consumes the emailed unlock token.
+func (app *application) unlockAccountHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Token string `json:"token"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Token != "", "token", "must be provided")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	userID, err := app.models.Tokens.Consume(data.ScopeUnlock, input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired unlock token")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user, err := app.models.Users.Get(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.unlockUser(w, r, user, false) {
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "account unlocked"})
+}
+
+// adminUnlockUserHandler clears a lock without waiting for the email.
+func (app *application) adminUnlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := app.models.Users.Get(r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !app.unlockUser(w, r, user, true) {
+		return
+	}
+
+	app.audit(r, "unlock", "user", user.ID, nil, nil)
+
+	app.logger.Info("Account unlocked by admin", "user_id", user.ID, "by", app.contextGetUser(r))
+
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "account unlocked"})
+}
+
+// unlockUser clears the lock and resets the failure count. It writes the
+// error response and returns false on failure.
+func (app *application) unlockUser(w http.ResponseWriter, r *http.Request, user *data.User, byAdmin bool) bool {
+	if err := app.models.Users.Unlock(user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return false
+	}
+	if err := app.models.Tokens.DeleteAllForUser(data.ScopeUnlock, user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return false
+	}
+
+	// A success row restarts the account's consecutive-failure count. An admin
+	// unlocking from their own desk isn't a sign-in from that address.
+	if byAdmin {
+		app.insertLoginAttempt(&data.LoginAttempt{Email: user.Email, UserID: &user.ID, Success: true, Reason: data.LoginReasonUnlocked})
+	} else {
+		app.recordLoginAttempt(r, user.Email, &user.ID, true, data.LoginReasonUnlocked)
+	}
+	return true
+}
+
+// listLoginAttemptsHandler is the admin audit of sign-in attempts.
+func (app *application) listLoginAttemptsHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	email := app.readString(qs, "email", "")
+	ip := app.readString(qs, "ip", "")
+	failedOnly := app.readString(qs, "failed", "") == "true"
+
+	filters := data.Filters{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "page_size", 50, v),
+		Sort:         app.readString(qs, "sort", "-created_at"),
+		SortSafelist: []string{"created_at", "-created_at"},
+	}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	attempts, metadata, err := app.models.LoginAttempts.GetAll(email, ip, failedOnly, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"attempts": attempts, "metadata": metadata})
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/cconner57/adoption-os/backend/internal/data"
 	"github.com/cconner57/adoption-os/backend/internal/mailer"
 	"github.com/cconner57/adoption-os/backend/internal/notifier"
+	"github.com/cconner57/adoption-os/backend/internal/oidc"
 	_ "github.com/lib/pq"
 )
 
@@ -37,7 +39,17 @@ type config struct {
 		password string
 		sender   string
 	}
-	assetsDir string
+	assetsDir   string
+	frontendURL string
+	refunds     data.RefundPolicy
+	oidc        struct {
+		issuer         string
+		clientID       string
+		clientSecret   string
+		redirectURL    string
+		allowedDomains []string
+		defaultRole    string
+	}
 }
 
 type application struct {
@@ -47,6 +59,7 @@ type application struct {
 	mailer   mailer.Mailer
 	notifier *notifier.Notifier
 	db       *sql.DB
+	oidc     *oidc.Provider // nil when single sign-on isn't configured
 }
 
 func main() {
@@ -74,6 +87,36 @@ func main() {
 		cfg.assetsDir = "/mnt/nvme/adoption-os/assets"
 	}
 
+	flag.StringVar(&cfg.frontendURL, "frontend-url", os.Getenv("FRONTEND_URL"), "Base URL for links in emails")
+	if cfg.frontendURL == "" {
+		cfg.frontendURL = "https://idohr.app"
+	}
+
+	flag.StringVar(&cfg.oidc.issuer, "oidc-issuer", os.Getenv("OIDC_ISSUER"), "OpenID Connect issuer")
+	if cfg.oidc.issuer == "" {
+		cfg.oidc.issuer = "https://accounts.google.com"
+	}
+	flag.StringVar(&cfg.oidc.clientID, "oidc-client-id", os.Getenv("OIDC_CLIENT_ID"), "OpenID Connect client ID (empty disables single sign-on)")
+	flag.StringVar(&cfg.oidc.clientSecret, "oidc-client-secret", os.Getenv("OIDC_CLIENT_SECRET"), "OpenID Connect client secret")
+	flag.StringVar(&cfg.oidc.redirectURL, "oidc-redirect-url", os.Getenv("OIDC_REDIRECT_URL"), "OpenID Connect callback URL registered with the provider")
+	flag.Func("oidc-allowed-domains", "Comma-separated email domains that get an account on first sign-in", func(s string) error {
+		cfg.oidc.allowedDomains = splitDomains(s)
+		return nil
+	})
+	cfg.oidc.allowedDomains = splitDomains(os.Getenv("OIDC_ALLOWED_DOMAINS"))
+	flag.StringVar(&cfg.oidc.defaultRole, "oidc-default-role", os.Getenv("OIDC_DEFAULT_ROLE"), "Role for accounts created by single sign-on")
+	if cfg.oidc.defaultRole == "" {
+		cfg.oidc.defaultRole = data.RoleVolunteer1
+	}
+
+	// Refund windows from the adoption contract; unset leaves refunds to staff
+	trialDays, _ := strconv.Atoi(os.Getenv("REFUND_TRIAL_DAYS"))
+	flag.IntVar(&cfg.refunds.TrialDays, "refund-trial-days", trialDays, "Days after adoption for a full refund on any return (0 disables)")
+	healthDays, _ := strconv.Atoi(os.Getenv("REFUND_HEALTH_DAYS"))
+	flag.IntVar(&cfg.refunds.HealthDays, "refund-health-days", healthDays, "Days after adoption for a full refund on a health return (0 disables)")
+	halfDays, _ := strconv.Atoi(os.Getenv("REFUND_HALF_DAYS"))
+	flag.IntVar(&cfg.refunds.HalfRefundDays, "refund-half-days", halfDays, "Days after adoption for a half refund (0 disables)")
+
 	seed := flag.Bool("seed", false, "Seed adoption dates from CSV")
 	seedSlugs := flag.Bool("seed-slugs", false, "Seed slugs for existing pets")
 	seedVolunteers := flag.Bool("seed-volunteers", false, "Seed active volunteers from mock data")
@@ -159,6 +202,22 @@ func main() {
 		db:       db,
 	}
 
+	if cfg.oidc.clientID != "" {
+		role, ok := data.GetRole(cfg.oidc.defaultRole)
+		if !ok || data.IsAdminRole(role.Name) {
+			logger.Error("OIDC default role must be a known, non-admin role", "role", cfg.oidc.defaultRole)
+			os.Exit(1)
+		}
+		app.config.oidc.defaultRole = role.Name
+		app.oidc = oidc.New(oidc.Config{
+			Issuer:       cfg.oidc.issuer,
+			ClientID:     cfg.oidc.clientID,
+			ClientSecret: cfg.oidc.clientSecret,
+			RedirectURL:  cfg.oidc.redirectURL,
+		})
+		logger.Info("single sign-on enabled", "issuer", cfg.oidc.issuer, "allowed_domains", cfg.oidc.allowedDomains)
+	}
+
 	go func() {
 		ticker := time.NewTicker(1 * time.Hour)
 		defer ticker.Stop()
@@ -174,10 +233,40 @@ func main() {
 			if err != nil {
 				app.logger.Error("Background Worker: Failed to delete denied", "error", err)
 			}
+
+			err = app.models.Tokens.DeleteExpired(ctx)
+			if err != nil {
+				app.logger.Error("Background Worker: Failed to delete expired tokens", "error", err)
+			}
+
+			err = app.models.Sessions.DeleteExpired(ctx)
+			if err != nil {
+				app.logger.Error("Background Worker: Failed to delete expired sessions", "error", err)
+			}
+
+			err = app.models.LoginAttempts.DeleteOlderThan(ctx, loginAttemptRetention)
+			if err != nil {
+				app.logger.Error("Background Worker: Failed to prune login attempts", "error", err)
+			}
+
+			err = app.models.OIDCStates.DeleteExpired(ctx)
+			if err != nil {
+				app.logger.Error("Background Worker: Failed to delete expired sign-on states", "error", err)
+			}
 			cancel()
 		}
 	}()
 
+	go func() {
+		for {
+			time.Sleep(time.Until(nextDailyRun(time.Now(), dailyJobsHour)))
+
+			app.sendStaffingGapDigest()
+			app.rolloverVolunteerStats()
+			app.sendMicrochipReminders()
+		}
+	}()
+
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.port),
 		Handler:      app.routes(),
@@ -228,3 +317,14 @@ func openDB(cfg config) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// splitDomains parses a comma-separated domain list, lowercased.
+func splitDomains(s string) []string {
+	var domains []string
+	for _, d := range strings.Split(s, ",") {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
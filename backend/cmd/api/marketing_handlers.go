@@ -37,6 +37,7 @@ func (app *application) getCampaignHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.setETag(w, campaign.Version)
 	app.writeJSON(w, http.StatusOK, envelope{"campaign": campaign}, nil)
 }
 
@@ -99,6 +100,8 @@ func (app *application) createCampaignHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.audit(r, data.AuditCreate, "campaign", campaign.ID, nil, campaign)
+
 	app.writeJSON(w, http.StatusCreated, envelope{"campaign": campaign}, nil)
 }
 
@@ -119,6 +122,12 @@ func (app *application) updateCampaignHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if app.preconditionFailed(w, r, campaign.Version) {
+		return
+	}
+
+	before := *campaign
+
 	var input struct {
 		Name        *string  `json:"name"`
 		Status      *string  `json:"status"`
@@ -176,13 +185,20 @@ func (app *application) updateCampaignHandler(w http.ResponseWriter, r *http.Req
 
 	err = app.models.Marketing.UpdateCampaign(campaign)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		if errors.Is(err, data.ErrEditConflict) {
+			app.editConflictResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
 		return
 	}
 
+	app.audit(r, data.AuditUpdate, "campaign", campaign.ID, &before, campaign)
+
 	if campaign.Progress >= 100 {
 		go app.notifier.SendToAll(fmt.Sprintf("Campaign '%s' has reached 100%% goal! 🎉", campaign.Name))
 	}
 
+	app.setETag(w, campaign.Version)
 	app.writeJSON(w, http.StatusOK, envelope{"campaign": campaign}, nil)
 }
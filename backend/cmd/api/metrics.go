@@ -2,7 +2,6 @@ package main
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"github.com/cconner57/adoption-os/backend/internal/data"
@@ -37,5 +36,9 @@ func (app *application) submitMetric(w http.ResponseWriter, r *http.Request) {
 	}
 
 	app.writeJSON(w, http.StatusCreated, envelope{"message": "metric recorded"}, nil)
-	fmt.Printf("Metric Recorded: %s\n", input.EventType)
+	source := ""
+	if key := app.contextGetAPIKey(r); key != nil {
+		source = key.Name
+	}
+	app.logger.Info("Metric recorded", "event_type", input.EventType, "api_key", source)
 }
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/totp"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// mfaChallengeTTL is how long the user has to enter a code after their password.
+const mfaChallengeTTL = 5 * time.Minute
+
+// verifySecondFactor checks a TOTP code, or failing that a recovery code.
+// Each TOTP step and each recovery code only works once.
+func (app *application) verifySecondFactor(mfa *data.UserMFA, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		step, ok := totp.Validate(mfa.TOTPSecret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return app.models.MFA.RecordStep(mfa.UserID, step)
+	}
+
+	if recoveryCode != "" {
+		used, err := app.models.MFA.UseRecoveryCode(mfa.UserID, recoveryCode)
+		if used {
+			app.logger.Warn("MFA recovery code used", "user_id", mfa.UserID)
+		}
+		return used, err
+	}
+
+	return false, nil
+}
+
+// getEnabledMFA loads the user's confirmed enrollment, or nil.
+func (app *application) getEnabledMFA(userID string) (*data.UserMFA, error) {
+	mfa, err := app.models.MFA.Get(userID)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !mfa.Enabled {
+		return nil, nil
+	}
+	return mfa, nil
+}
+
+func (app *application) getMFAStatusHandler(w http.ResponseWriter, r *http.Request) {
+	userID := app.contextGetUser(r)
+
+	user, err := app.models.Users.Get(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	mfa, err := app.getEnabledMFA(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	remaining := 0
+	if mfa != nil {
+		remaining, err = app.models.MFA.CountRecoveryCodes(userID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	session := app.contextGetSession(r)
+
+	app.JSONResponse(w, http.StatusOK, envelope{
+		"enabled":                mfa != nil,
+		"required":               data.IsAdminRole(user.Role),
+		"sessionVerified":        session != nil && session.MFAVerified,
+		"recoveryCodesRemaining": remaining,
+		"methods":                []string{"totp"},
+	})
+}
+
+// beginTOTPEnrollmentHandler issues a new secret. It isn't active until a code
+// from it is confirmed.
+func (app *application) beginTOTPEnrollmentHandler(w http.ResponseWriter, r *http.Request) {
+	userID := app.contextGetUser(r)
+
+	user, err := app.models.Users.Get(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	existing, err := app.getEnabledMFA(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if existing != nil {
+		app.JSONError(w, http.StatusConflict, "Multi-factor authentication is already enabled")
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.MFA.BeginEnrollment(userID, secret)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{
+		"secret": secret,
+		"uri":    totp.URI(organizationName, user.Email, secret),
+	})
+}
+
+// confirmTOTPEnrollmentHandler turns MFA on and returns the recovery codes.
+// This is the only time the codes are shown.
+func (app *application) confirmTOTPEnrollmentHandler(w http.ResponseWriter, r *http.Request) {
+	userID := app.contextGetUser(r)
+
+	var input struct {
+		Code string `json:"code"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	mfa, err := app.models.MFA.Get(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.JSONError(w, http.StatusConflict, "Start enrollment first")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if mfa.Enabled {
+		app.JSONError(w, http.StatusConflict, "Multi-factor authentication is already enabled")
+		return
+	}
+
+	step, ok := totp.Validate(mfa.TOTPSecret, input.Code, time.Now())
+	if !ok {
+		v := validator.New()
+		v.AddError("code", "is incorrect or has expired")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	codes, err := data.GenerateRecoveryCodes(data.RecoveryCodeCount)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.models.MFA.ReplaceRecoveryCodes(userID, codes); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.models.MFA.Enable(userID, step); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The user just proved possession, so this session no longer needs a second step
+	if session := app.contextGetSession(r); session != nil {
+		if err = app.models.Sessions.MarkMFAVerified(session.Token); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	app.logger.Info("MFA enabled", "user_id", userID)
+
+	app.JSONResponse(w, http.StatusOK, envelope{
+		"enabled":       true,
+		"recoveryCodes": codes,
+	})
+}
+
+func (app *application) regenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	userID := app.contextGetUser(r)
+
+	var input struct {
+		Code string `json:"code"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	mfa, err := app.getEnabledMFA(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if mfa == nil {
+		app.JSONError(w, http.StatusConflict, "Multi-factor authentication is not enabled")
+		return
+	}
+
+	ok, err := app.verifySecondFactor(mfa, input.Code, "")
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !ok {
+		v := validator.New()
+		v.AddError("code", "is incorrect or has expired")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	codes, err := data.GenerateRecoveryCodes(data.RecoveryCodeCount)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.models.MFA.ReplaceRecoveryCodes(userID, codes); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"recoveryCodes": codes})
+}
+
+// disableMFAHandler lets non-admin users turn MFA off with a current code.
+func (app *application) disableMFAHandler(w http.ResponseWriter, r *http.Request) {
+	userID := app.contextGetUser(r)
+
+	var input struct {
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recoveryCode"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, err := app.models.Users.Get(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if data.IsAdminRole(user.Role) {
+		app.JSONError(w, http.StatusForbidden, "Multi-factor authentication is required for your role")
+		return
+	}
+
+	mfa, err := app.getEnabledMFA(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if mfa == nil {
+		app.JSONError(w, http.StatusConflict, "Multi-factor authentication is not enabled")
+		return
+	}
+
+	ok, err := app.verifySecondFactor(mfa, input.Code, input.RecoveryCode)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !ok {
+		v := validator.New()
+		v.AddError("code", "is incorrect or has expired")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err = app.models.MFA.Delete(userID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logger.Info("MFA disabled", "user_id", userID)
+
+	app.JSONResponse(w, http.StatusOK, envelope{"enabled": false})
+}
+
+// verifyLoginMFAHandler is the second step of login: it exchanges the
+// challenge token plus a code for an MFA-verified session.
+func (app *application) verifyLoginMFAHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recoveryCode"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.ChallengeToken != "", "challengeToken", "must be provided")
+	v.Check(input.Code != "" || input.RecoveryCode != "", "code", "must be provided")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	userID, err := app.models.MFA.UseChallengeAttempt(input.ChallengeToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.JSONError(w, http.StatusUnauthorized, "Login challenge expired, please sign in again")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	mfa, err := app.getEnabledMFA(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if mfa == nil {
+		app.JSONError(w, http.StatusUnauthorized, "Login challenge expired, please sign in again")
+		return
+	}
+
+	user, err := app.models.Users.Get(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Codes get the same throttle and lock as passwords, otherwise a known
+	// password buys unlimited fresh challenges to guess six digits with
+	ip := clientIP(r)
+	failures, err := app.models.LoginAttempts.RecentFailures(user.Email, ip)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if wait := failures.RetryAfter(time.Now()); wait > 0 {
+		app.logger.Info("MFA verification throttled", "user_id", user.ID, "ip", ip, "account_failures", failures.Account, "ip_failures", failures.IP)
+		app.recordLoginAttempt(r, user.Email, &user.ID, false, data.LoginReasonThrottled)
+		app.tooManyLoginAttemptsResponse(w, r, wait)
+		return
+	}
+
+	if user.Locked(time.Now()) {
+		app.recordLoginAttempt(r, user.Email, &user.ID, false, data.LoginReasonLocked)
+		app.accountLockedResponse(w, r)
+		return
+	}
+
+	ok, err := app.verifySecondFactor(mfa, input.Code, input.RecoveryCode)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !ok {
+		app.logger.Info("Login failed: second factor mismatch", "user_id", userID)
+		app.recordLoginAttempt(r, user.Email, &user.ID, false, data.LoginReasonMFA)
+		if failures.Account+1 >= data.AccountLockThreshold {
+			app.lockAccount(user, failures.Account+1)
+		}
+		app.JSONError(w, http.StatusUnauthorized, "Invalid authentication code")
+		return
+	}
+
+	if err = app.models.MFA.DeleteChallenge(input.ChallengeToken); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The account may have been deactivated since the password step
+	if reason := user.SignInBlocked(); reason != "" {
+		app.JSONError(w, http.StatusForbidden, reason)
+		return
+	}
+
+	app.startSession(w, r, user, true)
+}
+
+// resetUserMFAHandler clears another user's enrollment when they've lost
+// both their device and recovery codes. They re-enroll at next login.
+func (app *application) resetUserMFAHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	user, err := app.models.Users.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if user.ID == app.contextGetUser(r) {
+		app.JSONError(w, http.StatusForbidden, "Use your own MFA settings to change your enrollment")
+		return
+	}
+	if strings.EqualFold(user.Role, data.RoleSuperAdmin) && !strings.EqualFold(app.contextGetRole(r), data.RoleSuperAdmin) {
+		app.JSONError(w, http.StatusForbidden, "Only a super admin can reset a super admin's MFA")
+		return
+	}
+
+	if err = app.models.MFA.Delete(user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.audit(r, "reset_mfa", "user", user.ID, nil, nil)
+
+	app.logger.Warn("MFA reset by admin", "user_id", user.ID, "by", app.contextGetUser(r))
+
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "multi-factor authentication reset"})
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// microchipLookupHandler finds a chip number in our pets and strays, for
+// scanning a found animal or checking a chip before it is recorded.
+func (app *application) microchipLookupHandler(w http.ResponseWriter, r *http.Request) {
+	number := r.PathValue("number")
+
+	v := validator.New()
+	v.Check(data.ValidMicrochip(number), "number", "must be a 9, 10 or 15 character microchip number")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	matches, err := app.models.Microchips.Lookup(number)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"chipNumber": data.NormalizeMicrochip(number), "matches": matches})
+}
+
+// microchipTaken refuses a pet whose chip already belongs to another pet,
+// writing the 409 itself. Only a changed chip is checked, so records that
+// predate the check can still be edited.
+func (app *application) microchipTaken(w http.ResponseWriter, r *http.Request, pet, current *data.Pet) bool {
+	number, _ := pet.Microchip()
+	if number == "" {
+		return false
+	}
+	if current != nil {
+		previous, _ := current.Microchip()
+		if data.NormalizeMicrochip(previous) == data.NormalizeMicrochip(number) {
+			return false
+		}
+	}
+
+	owner, err := app.models.Microchips.Owner(number, pet.ID)
+	switch {
+	case err == nil:
+		app.JSONError(w, http.StatusConflict, fmt.Sprintf("Microchip %s is already assigned to %s", number, owner.PetName))
+		return true
+	case !errors.Is(err, data.ErrRecordNotFound):
+		app.serverErrorResponse(w, r, err)
+		return true
+	}
+	return false
+}
+
+// trackMicrochipRegistration starts the registration transfer for a pet
+// that has just been adopted with a chip. Failures are logged; they don't
+// undo the adoption.
+func (app *application) trackMicrochipRegistration(pet *data.Pet) {
+	number, company := pet.Microchip()
+	if number == "" {
+		return
+	}
+
+	var adoption data.Adoption
+	_ = json.Unmarshal(pet.Adoption, &adoption)
+	adopted := time.Now()
+	if adoption.Date != nil {
+		if d, ok := data.ParseRecordDate(*adoption.Date); ok {
+			adopted = d
+		}
+	}
+
+	if err := app.models.Microchips.Track(pet.ID, number, company, adopted.Format(time.DateOnly)); err != nil {
+		app.logger.Error("Failed to track microchip registration", "petId", pet.ID, "error", err)
+	}
+}
+
+func (app *application) listMicrochipRegistrationsHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	status := app.readString(qs, "status", data.RegistrationPending)
+	if status == "all" {
+		status = ""
+	}
+
+	filters := data.Filters{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "page_size", 50, v),
+		Sort:         "adoption_date",
+		SortSafelist: []string{"adoption_date"},
+	}
+
+	if status != "" {
+		v.Check(validator.PermittedValue(status, data.RegistrationStatuses...), "status", "invalid status")
+	}
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	registrations, metadata, err := app.models.Microchips.Registrations(status, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"registrations": registrations, "metadata": metadata})
+}
+
+// updateMicrochipRegistrationHandler records that the adopter's registration
+// went through, or that it isn't needed.
+func (app *application) updateMicrochipRegistrationHandler(w http.ResponseWriter, r *http.Request) {
+	registration, err := app.models.Microchips.GetRegistration(r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if app.preconditionFailed(w, r, registration.Version) {
+		return
+	}
+
+	before := *registration
+
+	var input struct {
+		Status *string `json:"status"`
+		Notes  *string `json:"notes"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Status != nil {
+		registration.Status = *input.Status
+	}
+	if input.Notes != nil {
+		registration.Notes = *input.Notes
+	}
+
+	v := validator.New()
+	if data.ValidateMicrochipRegistration(v, registration); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Microchips.UpdateRegistration(registration); err != nil {
+		if errors.Is(err, data.ErrEditConflict) {
+			app.editConflictResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, data.AuditUpdate, "microchip_registration", registration.PetID, before, registration)
+
+	app.setETag(w, registration.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"registration": registration})
+}
+
+// sendMicrochipReminders emails adopters who haven't moved their pet's chip
+// registration into their name yet. Runs from the daily background worker.
+func (app *application) sendMicrochipReminders() {
+	due, err := app.models.Microchips.DueReminders()
+	if err != nil {
+		app.logger.Error("Microchip reminders: failed to load registrations", "error", err)
+		return
+	}
+
+	sent := 0
+	for _, reg := range due {
+		if reg.AdopterEmail == "" {
+			continue
+		}
+		if err := app.sendMicrochipReminderEmail(reg); err != nil {
+			app.logger.Error("Microchip reminders: failed to send email", "petId", reg.PetID, "error", err)
+			continue
+		}
+		if err := app.models.Microchips.MarkReminded(reg.PetID); err != nil {
+			app.logger.Error("Microchip reminders: failed to record reminder", "petId", reg.PetID, "error", err)
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		app.logger.Info("Microchip reminders sent", "count", sent)
+	}
+}
+
+func (app *application) sendMicrochipReminderEmail(reg *data.MicrochipRegistration) error {
+	company := reg.Company
+	if company == "" {
+		company = "the microchip company"
+	}
+	firstName, _, _ := strings.Cut(reg.AdopterName, " ")
+	if firstName == "" {
+		firstName = "there"
+	}
+
+	attachments := make(map[string][]byte)
+	if logoBytes := app.getLogoBytes(); logoBytes != nil {
+		attachments["logo.jpg"] = logoBytes
+	}
+
+	subject := fmt.Sprintf("Reminder: register %s's microchip in your name", reg.PetName)
+	body := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+  body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+  .container { max-width: 600px; margin: 0 auto; padding: 20px; border: 1px solid #e0e0e0; border-radius: 8px; }
+  .header { text-align: center; margin-bottom: 30px; }
+  .logo { max-width: 150px; height: auto; margin-bottom: 20px; }
+  .content { font-size: 16px; }
+  .chip { font-family: monospace; font-size: 18px; background: #f2f2f2; padding: 4px 8px; border-radius: 4px; }
+</style>
+</head>
+<body>
+<div class="container">
+  <div class="header">
+    <img src="cid:logo.jpg" alt="IDOHR Logo" class="logo">
+  </div>
+
+  <div class="content">
+    <p>Dear %s,</p>
+    <p><strong>%s</strong>'s microchip is still registered to our rescue. If they ever get lost, the shelter or vet that scans them will call us first, which costs precious time.</p>
+    <p>Please contact %s to transfer the registration into your name. You'll need the chip number:</p>
+    <p class="chip">%s</p>
+    <p>Once it's done, just reply to this email so we can stop reminding you.</p>
+    <p>Warmly,<br>I Dream of Home Rescue Team</p>
+  </div>
+</div>
+</body>
+</html>`, html.EscapeString(firstName), html.EscapeString(reg.PetName), html.EscapeString(company), html.EscapeString(reg.ChipNumber))
+
+	app.logger.Info("Sending microchip reminder email", "recipient", reg.AdopterEmail, "petId", reg.PetID)
+	return app.mailer.Send(reg.AdopterEmail, subject, body, attachments)
+}
@@ -1,12 +1,13 @@
 package main
 
 import (
-	"crypto/subtle"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"runtime/debug"
+	"time"
 
+	"github.com/cconner57/adoption-os/backend/internal/data"
 	"github.com/google/uuid"
 )
 
@@ -67,30 +68,44 @@ func (app *application) cacheControl(next http.Handler) http.Handler {
 	})
 }
 
-// Authentication Middleware (Constant Time Comparison)
-func (app *application) requireAuthentication(next http.Handler) http.Handler {
+// API Key Middleware
+// For devices and scripts (kennel cards, partner integrations). Keys are looked
+// up by hash, so there's no secret to compare in constant time here.
+func (app *application) requireAPIKey(scope string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get key from header
-		apiKey := r.Header.Get("X-API-Key")
+		plaintext := r.Header.Get("X-API-Key")
+		if plaintext == "" {
+			app.JSONError(w, http.StatusUnauthorized, "Invalid or missing authentication token")
+			return
+		}
 
-		// Get key from env (assume already loaded)
-		expectedKey := os.Getenv("API_KEY")
+		key, err := app.models.APIKeys.GetByKey(plaintext)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				app.JSONError(w, http.StatusUnauthorized, "Invalid or missing authentication token")
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
 
-		if expectedKey == "" {
-			// If no key is set in env, maybe we should default strictly,
-			// but for this demo let's log error and deny everything safely.
-			app.serverErrorResponse(w, r, fmt.Errorf("server missing API_KEY configuration"))
+		if !key.Active(time.Now()) {
+			app.logger.Info("Rejected inactive API key", "key_id", key.ID, "prefix", key.Prefix)
+			app.JSONError(w, http.StatusUnauthorized, "API key has expired or been revoked")
 			return
 		}
 
-		// Constant Time Comparison
-		// Convert to bytes
-		if subtle.ConstantTimeCompare([]byte(apiKey), []byte(expectedKey)) != 1 {
-			app.JSONError(w, http.StatusUnauthorized, "Invalid or missing authentication token")
+		if !key.HasScope(scope) {
+			app.JSONError(w, http.StatusForbidden, "API key lacks the "+scope+" scope")
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		if err := app.models.APIKeys.TouchLastUsed(key.ID, clientIP(r)); err != nil {
+			app.logger.Error("API key last-used update failed", "key_id", key.ID, "error", err)
+		}
+
+		next.ServeHTTP(w, app.contextSetAPIKey(r, key))
 	})
 }
 
@@ -130,25 +145,13 @@ func (app *application) securityHeaders(next http.Handler) http.Handler {
 // Session Auth Middleware
 func (app *application) requireLogin(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var token string
-
-		// 1. Check for Bearer Token
-		authHeader := r.Header.Get("Authorization")
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			token = authHeader[7:]
-		}
+		token := app.readSessionToken(r)
 
-		// 2. Fallback to Cookie
-		if token == "" {
-			cookie, err := r.Cookie("session_token")
-			if err == nil {
-				token = cookie.Value
-			}
-		}
-
-		// 3. Fallback to Query Param (e.g. for window.open / downloads)
-		if token == "" {
-			token = r.URL.Query().Get("token")
+		// Downloads opened with window.open can't send headers, so they carry a
+		// single-use token minted for exactly this path instead
+		if token == "" && r.Method == http.MethodGet && r.URL.Query().Get("download_token") != "" {
+			app.authenticateDownload(w, r, next)
+			return
 		}
 
 		if token == "" {
@@ -157,9 +160,7 @@ func (app *application) requireLogin(next http.Handler) http.Handler {
 			return
 		}
 
-		app.logger.Info("Checking session", "token_length", len(token), "source", "header_or_cookie")
-
-		// 3. Validate Session
+		// Validate Session
 		session, err := app.models.Sessions.Get(token)
 		if err != nil {
 			app.logger.Error("Session lookup error", "error", err)
@@ -176,26 +177,78 @@ func (app *application) requireLogin(next http.Handler) http.Handler {
 			app.logger.Info("Session not found or expired", "token_prefix", prefix)
 			// Invalid or expired token
 			// Clear the cookie just in case it was a cookie
-			http.SetCookie(w, &http.Cookie{
-				Name:     "session_token",
-				Value:    "",
-				Path:     "/",
-				MaxAge:   -1,
-				HttpOnly: true,
-			})
+			app.clearSessionCookie(w)
 			app.JSONError(w, http.StatusUnauthorized, "Invalid or expired session")
 			return
 		}
 
+		// Sliding expiry. Requests presenting the pre-rotation token during the
+		// grace period are served but don't trigger another rotation. Only
+		// cookie sessions rotate: a Bearer token lives in client storage we
+		// can't update, so rotating it would log the client out.
+		if session.Token == token {
+			rotate := readBearerToken(r) == "" && time.Since(session.RotatedAt) > data.SessionRotateAfter
+			switch {
+			case rotate:
+				rotated, err := app.models.Sessions.Rotate(session)
+				if err != nil {
+					app.serverErrorResponse(w, r, err)
+					return
+				}
+				if rotated != "" {
+					session.Token = rotated
+					app.setSessionCookie(w, rotated, session.CreatedAt.Add(data.SessionMaxAge))
+					w.Header().Set("X-Session-Token", rotated)
+				}
+			case time.Since(session.LastSeenAt) > data.SessionTouchInterval:
+				if err := app.models.Sessions.Touch(session); err != nil {
+					app.logger.Error("Session touch error", "error", err)
+				}
+			}
+		}
+
 		// Valid session -> Add user to context
 		r = app.contextSetUser(r, session.UserID)
+		r = app.contextSetSession(r, session)
 
 		next.ServeHTTP(w, r)
 	})
 }
 
-// Admin Authorization Middleware
-func (app *application) requireAdmin(next http.Handler) http.Handler {
+// authenticateDownload serves a GET authorised by a download token. The
+// token is consumed, so the link works once.
+func (app *application) authenticateDownload(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	sessionID, err := app.models.Tokens.ConsumeForSession(data.ScopeDownloadPrefix+r.URL.Path, r.URL.Query().Get("download_token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.JSONError(w, http.StatusUnauthorized, "Invalid or expired download link")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	session, err := app.models.Sessions.GetByID(sessionID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if session == nil {
+		app.JSONError(w, http.StatusUnauthorized, "Invalid or expired download link")
+		return
+	}
+
+	r = app.contextSetUser(r, session.UserID)
+	r = app.contextSetSession(r, session)
+
+	next.ServeHTTP(w, r)
+}
+
+// Permission Authorization Middleware
+// Must run after requireLogin. The user's role is stored on the request so
+// handlers can make finer checks (e.g. medical edits) without a second lookup.
+func (app *application) requirePermission(perm string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		userID := app.contextGetUser(r)
 
@@ -205,11 +258,48 @@ func (app *application) requireAdmin(next http.Handler) http.Handler {
 			return
 		}
 
-		if user.Role != "ADMIN" && user.Role != "SUPER_ADMIN" {
+		if !user.Activated || !data.HasPermission(user.Role, perm) {
+			app.logger.Info("Permission denied", "user_id", userID, "role", user.Role, "permission", perm)
 			app.JSONError(w, http.StatusForbidden, "Insufficient permissions")
 			return
 		}
 
+		// Admin roles must have completed a second factor for this session.
+		// Login-only routes (profile, MFA enrollment) stay reachable.
+		if app.mfaPending(r, user.Role) {
+			app.JSONError(w, http.StatusForbidden, "Multi-factor authentication required")
+			return
+		}
+
+		r = app.contextSetRole(r, user.Role)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireMFA applies the admin second-factor rule to login-only routes that
+// change the account or its sessions. Must run after requireLogin; the MFA
+// enrollment routes are left without it so an admin can still enroll.
+func (app *application) requireMFA(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := app.models.Users.Get(app.contextGetUser(r))
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if app.mfaPending(r, user.Role) {
+			app.JSONError(w, http.StatusForbidden, "Multi-factor authentication required")
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
+
+// mfaPending reports whether role needs a second factor that this request's
+// session hasn't completed.
+func (app *application) mfaPending(r *http.Request, role string) bool {
+	session := app.contextGetSession(r)
+	return data.IsAdminRole(role) && (session == nil || !session.MFAVerified)
+}
@@ -93,6 +93,8 @@ func (app *application) sendBroadcastHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	app.audit(r, "broadcast", "notification", "", nil, envelope{"message": input.Message})
+
 	go app.notifier.SendToAll(input.Message)
 
 	app.writeJSON(w, http.StatusOK, envelope{"status": "queued"}, nil)
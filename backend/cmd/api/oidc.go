@@ -0,0 +1,305 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/oidc"
+	"github.com/cconner57/adoption-os/backend/internal/password"
+)
+
+const (
+	// oidcStateTTL is how long the user has to finish signing in at the provider.
+	oidcStateTTL = 10 * time.Minute
+	// oidcSignInTTL covers the redirect back to the frontend and its exchange call.
+	oidcSignInTTL = 2 * time.Minute
+)
+
+// safeRedirectPath only allows local paths so the callback can't be turned
+// into an open redirect.
+func safeRedirectPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.Contains(path, `\`) {
+		return "/"
+	}
+	return path
+}
+
+// emailDomain returns the lowercased part after the @.
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+// oidcDomainAllowed reports whether a new account may be provisioned for
+// email from the Workspace hostedDomain.
+func oidcDomainAllowed(allowed []string, email, hostedDomain string) bool {
+	domain := emailDomain(email)
+	return domain != "" && slices.Contains(allowed, domain) && slices.Contains(allowed, strings.ToLower(hostedDomain))
+}
+
+// oidcRefusal returns the sso_error code when single sign-on mustn't open the
+// account, or "". The provider vouches for the mailbox, so an unverified
+// address is fine; a deactivated account never is, verified or not.
+func oidcRefusal(user *data.User, now time.Time) string {
+	switch {
+	case user.Locked(now):
+		return "locked"
+	case !user.Activated:
+		return "deactivated"
+	}
+	return ""
+}
+
+// oidcErrorRedirect sends the browser back to the frontend sign-in page with
+// a short error code it can explain to the user.
+func (app *application) oidcErrorRedirect(w http.ResponseWriter, r *http.Request, code string) {
+	target := strings.TrimRight(app.config.frontendURL, "/") + "/login?sso_error=" + url.QueryEscape(code)
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// oidcLoginHandler starts single sign-on: it remembers a state, nonce and
+// PKCE verifier and redirects to the provider.
+func (app *application) oidcLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if app.oidc == nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		values[i] = value
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	err := app.models.OIDCStates.Insert(state, &data.OIDCState{
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		RedirectPath: safeRedirectPath(r.URL.Query().Get("redirect")),
+	}, oidcStateTTL)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	target, err := app.oidc.AuthCodeURL(r.Context(), state, nonce, verifier)
+	if err != nil {
+		app.logger.Error("OIDC discovery failed", "error", err)
+		app.oidcErrorRedirect(w, r, "provider_unavailable")
+		return
+	}
+
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// oidcCallbackHandler finishes single sign-on. Verified emails map to
+// existing accounts; unknown addresses on an allowed domain get a new account
+// with the configured default role.
+func (app *application) oidcCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	if app.oidc == nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	qs := r.URL.Query()
+	if qs.Get("error") != "" {
+		// The user cancelled or the provider refused
+		app.logger.Info("OIDC sign-in aborted by provider", "error", qs.Get("error"))
+		app.oidcErrorRedirect(w, r, "cancelled")
+		return
+	}
+
+	state, err := app.models.OIDCStates.Consume(qs.Get("state"))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.oidcErrorRedirect(w, r, "expired")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	rawIDToken, err := app.oidc.Exchange(r.Context(), qs.Get("code"), state.CodeVerifier)
+	if err != nil {
+		app.logger.Error("OIDC code exchange failed", "error", err)
+		app.oidcErrorRedirect(w, r, "exchange_failed")
+		return
+	}
+
+	claims, err := app.oidc.Verify(r.Context(), rawIDToken, state.Nonce)
+	if err != nil {
+		app.logger.Warn("OIDC ID token rejected", "error", err)
+		app.oidcErrorRedirect(w, r, "invalid_token")
+		return
+	}
+
+	email := strings.ToLower(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		app.oidcErrorRedirect(w, r, "email_unverified")
+		return
+	}
+
+	user, err := app.models.Users.GetByEmail(email)
+	if errors.Is(err, data.ErrRecordNotFound) {
+		user, err = app.provisionOIDCUser(email, claims.Name, claims.HostedDomain)
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.logger.Info("OIDC sign-in refused: no account and domain not allowed", "email", email)
+			app.oidcErrorRedirect(w, r, "no_account")
+			return
+		}
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if reason := oidcRefusal(user, time.Now()); reason != "" {
+		if reason == "locked" {
+			app.recordLoginAttempt(r, email, &user.ID, false, data.LoginReasonLocked)
+		}
+		app.oidcErrorRedirect(w, r, reason)
+		return
+	}
+	if user.EmailVerifiedAt == nil {
+		if _, err := app.models.Users.MarkEmailVerified(user.ID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	// Single sign-on replaces the password, not the second factor
+	mfa, err := app.models.MFA.Get(user.ID)
+	if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if mfa != nil && mfa.Enabled {
+		challenge, err := app.models.MFA.CreateChallenge(user.ID, mfaChallengeTTL)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		link := app.frontendLink("/login", challenge) + "&sso=mfa&redirect=" + url.QueryEscape(state.RedirectPath)
+		http.Redirect(w, r, link, http.StatusFound)
+		return
+	}
+
+	token, err := app.createSession(w, r, user, false, data.LoginReasonOIDC)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The frontend authenticates with a Bearer token, so hand it a one-time
+	// code to swap for the session rather than relying on the cookie
+	session, err := app.models.Sessions.Get(token)
+	if err != nil || session == nil {
+		app.serverErrorResponse(w, r, errors.Join(err, errors.New("new session not found")))
+		return
+	}
+	code, _, err := app.models.Tokens.NewForSession(user.ID, session.ID, data.ScopeSignIn, oidcSignInTTL)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	link := app.frontendLink("/login", code) + "&sso=session&redirect=" + url.QueryEscape(state.RedirectPath)
+	http.Redirect(w, r, link, http.StatusFound)
+}
+
+// oidcSessionHandler swaps the one-time code from the callback redirect for
+// the session token, answering like a password login.
+func (app *application) oidcSessionHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Code string `json:"code"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	sessionID, err := app.models.Tokens.ConsumeForSession(data.ScopeSignIn, input.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.JSONError(w, http.StatusUnauthorized, "Invalid or expired sign-in code")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	session, err := app.models.Sessions.GetByID(sessionID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if session == nil {
+		app.JSONError(w, http.StatusUnauthorized, "Invalid or expired sign-in code")
+		return
+	}
+
+	user, err := app.models.Users.Get(session.UserID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeSession(w, r, session.Token, user, session.MFAVerified)
+}
+
+// provisionOIDCUser creates an account for a first-time sign-in from an
+// allowed domain. Both the email and the Workspace the provider says the
+// account belongs to (hd) must be allowed: the email alone can be any address
+// a personal account has verified. It returns ErrRecordNotFound otherwise.
+func (app *application) provisionOIDCUser(email, name, hostedDomain string) (*data.User, error) {
+	if !oidcDomainAllowed(app.config.oidc.allowedDomains, email, hostedDomain) {
+		return nil, data.ErrRecordNotFound
+	}
+
+	// Nobody knows this password; the user can set one through a reset
+	secret, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := password.HashPassword(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = email[:strings.LastIndex(email, "@")]
+	}
+
+	now := time.Now()
+	user := &data.User{
+		Name:            name,
+		Email:           email,
+		PasswordHash:    hash,
+		Activated:       true,
+		Role:            app.config.oidc.defaultRole,
+		EmailVerifiedAt: &now,
+	}
+
+	if err := app.models.Users.Insert(user); err != nil {
+		return nil, err
+	}
+
+	app.logger.Info("Account created by single sign-on", "user_id", user.ID, "email", email, "role", user.Role)
+	return user, nil
+}
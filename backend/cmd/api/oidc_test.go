@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+)
+
+func TestSafeRedirectPath(t *testing.T) {
+	tests := map[string]string{
+		"":                     "/",
+		"/dashboard":           "/dashboard",
+		"/pets?status=foster":  "/pets?status=foster",
+		"//evil.example":       "/",
+		"/\\evil.example":      "/",
+		"https://evil.example": "/",
+		"dashboard":            "/",
+	}
+
+	for in, want := range tests {
+		if got := safeRedirectPath(in); got != want {
+			t.Errorf("safeRedirectPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestEmailDomain(t *testing.T) {
+	if got := emailDomain("Jane@IDOHR.org"); got != "idohr.org" {
+		t.Errorf("got %q", got)
+	}
+	if got := emailDomain("no-at-sign"); got != "" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestOIDCDomainAllowed(t *testing.T) {
+	allowed := []string{"idohr.org"}
+
+	tests := []struct {
+		email, hd string
+		want      bool
+	}{
+		{"jane@idohr.org", "idohr.org", true},
+		{"jane@idohr.org", "IDOHR.org", true},
+		// A personal account that has verified a work address has no hd
+		{"jane@idohr.org", "", false},
+		{"jane@idohr.org", "other.org", false},
+		{"jane@gmail.com", "idohr.org", false},
+		{"no-at-sign", "idohr.org", false},
+	}
+
+	for _, tt := range tests {
+		if got := oidcDomainAllowed(allowed, tt.email, tt.hd); got != tt.want {
+			t.Errorf("oidcDomainAllowed(%q, %q) = %v, want %v", tt.email, tt.hd, got, tt.want)
+		}
+	}
+}
+
+func TestOIDCRefusal(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		user data.User
+		want string
+	}{
+		{"active and verified", data.User{Activated: true, EmailVerifiedAt: &now}, ""},
+		{"active, awaiting verification", data.User{Activated: true}, ""},
+		{"deactivated and verified", data.User{EmailVerifiedAt: &now}, "deactivated"},
+		// Deactivated after changing address: the provider's verification
+		// mustn't bring it back
+		{"deactivated and unverified", data.User{}, "deactivated"},
+		{"locked", data.User{Activated: true, EmailVerifiedAt: &now, LockedUntil: &later}, "locked"},
+	}
+
+	for _, tt := range tests {
+		if got := oidcRefusal(&tt.user, now); got != tt.want {
+			t.Errorf("%s: want %q; got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -62,6 +63,24 @@ func (app *application) getAdoptedPetsCount(w http.ResponseWriter, r *http.Reque
 	app.JSONResponse(w, http.StatusOK, map[string]int{"count": count})
 }
 
+// getPet returns a single pet with its version as the ETag, so an editor can
+// send it back in If-Match.
+func (app *application) getPet(w http.ResponseWriter, r *http.Request) {
+	pet, err := app.models.Pets.Get(r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.setETag(w, pet.Version)
+	app.JSONResponse(w, http.StatusOK, pet)
+}
+
 func (app *application) updatePet(w http.ResponseWriter, r *http.Request) {
 	// 1. Get ID from URL path
 	// Using r.URL.Path? We don't have r.PathValue in this Go version maybe?
@@ -79,6 +98,21 @@ func (app *application) updatePet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	current, err := app.models.Pets.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if app.preconditionFailed(w, r, current.Version) {
+		return
+	}
+
 	// 2. Parse Body
 	var input struct {
 		Name            string          `json:"name"`
@@ -99,15 +133,25 @@ func (app *application) updatePet(w http.ResponseWriter, r *http.Request) {
 		LitterName      *string         `json:"litterName"`
 	}
 
-	err := app.readJSON(w, r, &input)
+	err = app.readJSON(w, r, &input)
 	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
+	// Medical history is limited to roles with medical:write. Leaving it out
+	// of the request keeps what's there.
+	if input.Medical == nil {
+		input.Medical = current.Medical
+	} else if !data.HasPermission(app.contextGetRole(r), data.PermMedicalWrite) && !jsonEqual(input.Medical, current.Medical) {
+		app.JSONError(w, http.StatusForbidden, "Insufficient permissions to edit medical records")
+		return
+	}
+
 	// 3. Construct Pet Model
 	pet := &data.Pet{
 		ID:              id,
+		Version:         current.Version,
 		Name:            input.Name,
 		Slug:            input.Slug,
 		Sex:             input.Sex,
@@ -151,14 +195,38 @@ func (app *application) updatePet(w http.ResponseWriter, r *http.Request) {
 	// DEBUG: Log adoption data
 	fmt.Printf("DEBUG: Received Adoption Payload: %s\n", input.Adoption)
 
+	if app.microchipTaken(w, r, pet, current) {
+		return
+	}
+
 	// 4. Update via Model
 	err = app.models.Pets.Update(pet)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		case errors.Is(err, data.ErrStrayHold):
+			message := fmt.Sprintf("%s is on stray hold", current.Name)
+			if hold, err := app.models.Strays.ActiveHold(id); err == nil {
+				message += " until " + hold.HoldUntil
+			}
+			app.JSONError(w, http.StatusConflict, message)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
 		return
 	}
 
+	app.audit(r, data.AuditUpdate, "pet", id, current, pet)
+
+	if strings.EqualFold(pet.Status(), "adopted") && !strings.EqualFold(current.Status(), "adopted") {
+		app.trackMicrochipRegistration(pet)
+	}
+
 	// 5. Return success (with updated object for frontend state)
+	app.setETag(w, pet.Version)
 	app.JSONResponse(w, http.StatusOK, pet)
 }
 
@@ -194,6 +262,12 @@ func (app *application) createPet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	hasMedical := !jsonEqual(input.Medical, nil) && !jsonEqual(input.Medical, json.RawMessage("{}"))
+	if hasMedical && !data.HasPermission(app.contextGetRole(r), data.PermMedicalWrite) {
+		app.JSONError(w, http.StatusForbidden, "Insufficient permissions to edit medical records")
+		return
+	}
+
 	// 3. Construct Pet Model
 	pet := &data.Pet{
 		Name:            input.Name,
@@ -213,6 +287,10 @@ func (app *application) createPet(w http.ResponseWriter, r *http.Request) {
 		LitterName:      input.LitterName,
 	}
 
+	if app.microchipTaken(w, r, pet, nil) {
+		return
+	}
+
 	// 4. Insert via Model
 	err = app.models.Pets.Insert(pet)
 	if err != nil {
@@ -220,6 +298,8 @@ func (app *application) createPet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	app.audit(r, data.AuditCreate, "pet", pet.ID, nil, pet)
+
 	// 5. Return success with the created resource
 	app.JSONResponse(w, http.StatusCreated, pet)
 }
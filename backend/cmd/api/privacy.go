@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// readSubject validates and normalises the email/phone pair that identifies
+// a data subject.
+func readSubject(v *validator.Validator, email, phone string) data.Subject {
+	subject := data.NewSubject(email, phone)
+
+	v.Check(subject.Email != "" || subject.Phone != "", "email", "an email or phone number is required")
+	if subject.Email != "" {
+		v.Check(validator.Matches(subject.Email, validator.EmailRX), "email", "must be a valid email address")
+	}
+	if phone != "" {
+		v.Check(len(subject.Phone) >= 7, "phone", "must contain at least 7 digits")
+	}
+
+	return subject
+}
+
+// recordRefs reduces each matched record to its id so a search can show
+// where a person appears without echoing their data back.
+func recordRefs(records data.SubjectRecords) map[string][]any {
+	refs := map[string][]any{}
+	for name, docs := range records {
+		ids := []any{}
+		for _, doc := range docs {
+			var row struct {
+				ID any `json:"id"`
+			}
+			if err := json.Unmarshal(doc, &row); err == nil && row.ID != nil {
+				ids = append(ids, row.ID)
+			}
+		}
+		refs[name] = ids
+	}
+	return refs
+}
+
+// searchPrivacyHandler shows which records mention an email or phone.
+func (app *application) searchPrivacyHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	subject := readSubject(v, qs.Get("email"), qs.Get("phone"))
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	records, err := app.models.Privacy.Find(subject)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"counts": records.Counts(), "records": recordRefs(records)})
+}
+
+// exportPrivacyHandler returns everything held about a person as a JSON
+// bundle and keeps a receipt of the request.
+func (app *application) exportPrivacyHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email string `json:"email"`
+		Phone string `json:"phone"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	subject := readSubject(v, input.Email, input.Phone)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	records, err := app.models.Privacy.Find(subject)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	counts := records.Counts()
+	receipt, err := app.models.Privacy.RecordExport(subject, app.contextGetUser(r), counts)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The audit entry only carries counts; the subject is in the receipt as a hash
+	app.audit(r, "export", "data_subject", receipt.ID, nil, envelope{"counts": counts})
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="data-export-%d.json"`, receipt.ID))
+	app.writeJSON(w, http.StatusOK, envelope{
+		"generatedAt": time.Now().UTC(),
+		"subject":     envelope{"email": subject.Email, "phone": subject.Phone},
+		"records":     records,
+		"receipt":     receipt,
+	}, nil)
+}
+
+// erasePrivacyHandler redacts or deletes everything held about a person.
+func (app *application) erasePrivacyHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email   string `json:"email"`
+		Phone   string `json:"phone"`
+		Confirm bool   `json:"confirm"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	subject := readSubject(v, input.Email, input.Phone)
+	v.Check(input.Confirm, "confirm", "must be true; erasure cannot be undone")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	receipt, err := app.models.Privacy.Erase(subject, app.contextGetUser(r))
+	if err != nil {
+		if errors.Is(err, data.ErrSubjectHasPrivilegedAccount) {
+			v.AddError("email", "belongs to an admin account; demote the admin account first")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.audit(r, "erase", "data_subject", receipt.ID, nil, envelope{"counts": receipt.Counts, "retained": receipt.Retained})
+
+	app.JSONResponse(w, http.StatusOK, envelope{"receipt": receipt})
+}
+
+// listPrivacyRequestsHandler lists export and erasure receipts, optionally
+// only those for one email or phone.
+func (app *application) listPrivacyRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	subject := data.NewSubject(qs.Get("email"), qs.Get("phone"))
+
+	filters := data.Filters{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "page_size", 20, v),
+		Sort:         app.readString(qs, "sort", "-created_at"),
+		SortSafelist: []string{"created_at", "-created_at"},
+	}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	requests, metadata, err := app.models.Privacy.GetAll(subject, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"requests": requests, "metadata": metadata})
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// processReturnHandler takes an adopted pet back. The pet goes back to
+// intake as of the return date; the return keeps the adopter, the
+// original application and the refund worked out from the configured
+// policy. refund overrides the policy amount (refunds:override only).
+func (app *application) processReturnHandler(w http.ResponseWriter, r *http.Request) {
+	pet, err := app.models.Pets.Get(r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		ReturnDate    string `json:"returnDate"`
+		Reason        string `json:"reason"`
+		Details       string `json:"details"`
+		ApplicationID *int64 `json:"applicationId"`
+		Refund        *int   `json:"refund"`
+		FlagAdopter   *bool  `json:"flagAdopter"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if !strings.EqualFold(pet.Status(), "adopted") {
+		app.JSONError(w, http.StatusConflict, fmt.Sprintf("%s isn't adopted, so there's nothing to return", pet.Name))
+		return
+	}
+
+	ret := &data.PetReturn{
+		PetID:         pet.ID,
+		ApplicationID: input.ApplicationID,
+		ReturnDate:    input.ReturnDate,
+		Reason:        input.Reason,
+		Details:       strings.TrimSpace(input.Details),
+		FlagAdopter:   true,
+	}
+	if ret.ReturnDate == "" {
+		ret.ReturnDate = time.Now().Format(time.DateOnly)
+	}
+	if input.FlagAdopter != nil {
+		ret.FlagAdopter = *input.FlagAdopter
+	}
+	ret.FromAdoption(pet.Adoption)
+
+	ret.Refund, ret.RefundPolicy = app.config.refunds.RefundFor(ret.Fee, ret.DaysKept, ret.Reason)
+	if input.Refund != nil && *input.Refund != ret.Refund {
+		if !app.canOverrideRefund(w, r) {
+			return
+		}
+		ret.Refund = *input.Refund
+		ret.RefundPolicy = "Set by staff (policy: " + ret.RefundPolicy + ")"
+	}
+
+	v := validator.New()
+	if data.ValidatePetReturn(v, ret); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if ret.ApplicationID == nil {
+		ret.ApplicationID, err = app.models.Returns.AdoptionApplication(ret.AdopterEmail, ret.AdoptionDate)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	if userID := app.contextGetUser(r); userID != "" {
+		ret.ProcessedBy = &userID
+	}
+
+	if err := app.models.Returns.Process(ret); err != nil {
+		if errors.Is(err, data.ErrNotAdopted) {
+			app.JSONError(w, http.StatusConflict, fmt.Sprintf("%s has already been returned", pet.Name))
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, data.AuditCreate, "pet_return", ret.ID, nil, ret)
+
+	updated, err := app.models.Pets.Get(pet.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	app.audit(r, data.AuditUpdate, "pet", pet.ID, pet, updated)
+
+	w.Header().Set("Location", fmt.Sprintf("/v1/returns/%d", ret.ID))
+	app.JSONResponse(w, http.StatusCreated, envelope{"return": ret, "pet": updated, "warnings": app.isolationWarnings(1)})
+}
+
+// canOverrideRefund checks the caller may set a refund other than the
+// policy's, writing the 403 itself when not.
+func (app *application) canOverrideRefund(w http.ResponseWriter, r *http.Request) bool {
+	if data.HasPermission(app.contextGetRole(r), data.PermRefundsOverride) {
+		return true
+	}
+	app.JSONError(w, http.StatusForbidden, "Only an admin can set a refund different from the policy")
+	return false
+}
+
+func (app *application) listReturnsHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	reason := app.readString(qs, "reason", "")
+	filters := data.Filters{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "page_size", 20, v),
+		Sort:         "-return_date",
+		SortSafelist: []string{"-return_date"},
+	}
+
+	if reason != "" {
+		v.Check(validator.PermittedValue(reason, data.ReturnReasons...), "reason", "invalid reason")
+	}
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	returns, metadata, err := app.models.Returns.GetAll(r.PathValue("id"), reason, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"returns": returns, "metadata": metadata})
+}
+
+// updateReturnHandler corrects a processed return. Changing the reason
+// re-applies the refund policy unless refund is given too.
+func (app *application) updateReturnHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	ret, err := app.models.Returns.Get(id)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if app.preconditionFailed(w, r, ret.Version) {
+		return
+	}
+
+	before := *ret
+
+	var input struct {
+		Reason        *string `json:"reason"`
+		Details       *string `json:"details"`
+		ApplicationID *int64  `json:"applicationId"`
+		Refund        *int    `json:"refund"`
+		FlagAdopter   *bool   `json:"flagAdopter"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Reason != nil && *input.Reason != ret.Reason {
+		ret.Reason = *input.Reason
+		ret.Refund, ret.RefundPolicy = app.config.refunds.RefundFor(ret.Fee, ret.DaysKept, ret.Reason)
+	}
+	if input.Details != nil {
+		ret.Details = strings.TrimSpace(*input.Details)
+	}
+	if input.ApplicationID != nil {
+		ret.ApplicationID = input.ApplicationID
+	}
+	if input.Refund != nil && *input.Refund != ret.Refund {
+		refund, policy := app.config.refunds.RefundFor(ret.Fee, ret.DaysKept, ret.Reason)
+		if *input.Refund == refund {
+			// Back to what the policy says
+			ret.Refund, ret.RefundPolicy = refund, policy
+		} else {
+			if !app.canOverrideRefund(w, r) {
+				return
+			}
+			ret.Refund = *input.Refund
+			ret.RefundPolicy = "Set by staff (policy: " + policy + ")"
+		}
+	}
+	if input.FlagAdopter != nil {
+		ret.FlagAdopter = *input.FlagAdopter
+	}
+
+	v := validator.New()
+	if data.ValidatePetReturn(v, ret); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Returns.Update(ret); err != nil {
+		if errors.Is(err, data.ErrEditConflict) {
+			app.editConflictResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, data.AuditUpdate, "pet_return", ret.ID, before, ret)
+
+	app.setETag(w, ret.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"return": ret})
+}
+
+// returnReportHandler reports return rates by period and reason. Periods
+// are months, quarters or years of adoption; the default covers the last
+// twelve months.
+func (app *application) returnReportHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	from := to.AddDate(-1, 0, 0)
+
+	interval := app.readString(qs, "interval", "month")
+	v.Check(validator.PermittedValue(interval, "month", "quarter", "year"), "interval", "must be month, quarter or year")
+
+	if s := qs.Get("from"); s != "" {
+		t, err := time.Parse(time.DateOnly, s)
+		v.Check(err == nil, "from", "must be a YYYY-MM-DD date")
+		from = t
+	}
+	if s := qs.Get("to"); s != "" {
+		t, err := time.Parse(time.DateOnly, s)
+		v.Check(err == nil, "to", "must be a YYYY-MM-DD date")
+		to = t.AddDate(0, 0, 1)
+	}
+	v.Check(from.Before(to), "to", "must be after from")
+	v.Check(!from.Before(to.AddDate(-10, 0, 0)), "from", "must be within ten years of to")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	flows, err := app.models.Pets.Flow()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	adoptions := []time.Time{}
+	for _, f := range flows {
+		if f.Adopted != nil {
+			adoptions = append(adoptions, *f.Adopted)
+		}
+	}
+
+	returns, err := app.models.Returns.AdoptedSince(from)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	periods, reasons := data.ReturnReport(returns, adoptions, from, to, interval)
+
+	totals := struct {
+		Adoptions int     `json:"adoptions"`
+		Returns   int     `json:"returns"`
+		Rate      float64 `json:"rate"`
+	}{}
+	for _, p := range periods {
+		totals.Adoptions += p.Adoptions
+		totals.Returns += p.Returns
+	}
+	if totals.Adoptions > 0 {
+		totals.Rate = math.Round(float64(totals.Returns)*1000/float64(totals.Adoptions)) / 10
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{
+		"from":     from.Format(time.DateOnly),
+		"to":       to.AddDate(0, 0, -1).Format(time.DateOnly),
+		"interval": interval,
+		"periods":  periods,
+		"reasons":  reasons,
+		"totals":   totals,
+	})
+}
+
+// adopterReturnFlags looks up an applicant's flagged returns. A failed
+// lookup is logged and treated as none, so it never holds up an application.
+func (app *application) adopterReturnFlags(email, phone string) []data.ReturnFlag {
+	flags, err := app.models.Returns.Flags(data.NewSubject(email, phone))
+	if err != nil {
+		app.logger.Error("Failed to look up adopter returns", "error", err)
+		return nil
+	}
+	return flags
+}
+
+// flagApplications marks adoption applications from people who have
+// returned a pet before.
+func (app *application) flagApplications(applications []*data.Application) {
+	for _, a := range applications {
+		if a.Type != "adoption" {
+			continue
+		}
+		var contact struct {
+			Email       string `json:"email"`
+			PhoneNumber string `json:"phoneNumber"`
+		}
+		if err := json.Unmarshal(a.Data, &contact); err != nil {
+			continue
+		}
+		a.ReturnFlags = app.adopterReturnFlags(contact.Email, contact.PhoneNumber)
+	}
+}
+
+// returnFlagsHTML is the warning banner for the staff copy of an adoption
+// application.
+func returnFlagsHTML(flags []data.ReturnFlag) string {
+	if len(flags) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<div style="border: 2px solid #c0392b; background: #fdecea; padding: 10px 15px; border-radius: 6px; margin-bottom: 20px;">
+  <strong style="color: #c0392b;">This applicant has returned a pet before</strong>
+  <ul>`)
+	for _, f := range flags {
+		kept := ""
+		if f.DaysKept != nil {
+			kept = fmt.Sprintf(" after %d days", *f.DaysKept)
+		}
+		fmt.Fprintf(&sb, "\n    <li>%s, returned %s%s: %s</li>", html.EscapeString(f.PetName), f.ReturnDate, kept, strings.ReplaceAll(f.Reason, "_", " "))
+	}
+	sb.WriteString("\n  </ul>\n</div>\n")
+	return sb.String()
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// userSummary is the admin-facing view of a user (no password hash).
+type userSummary struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	Activated   bool      `json:"activated"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Permissions []string  `json:"permissions"`
+	Version     int       `json:"version"`
+}
+
+func newUserSummary(user *data.User) userSummary {
+	role, _ := data.GetRole(user.Role)
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return userSummary{
+		ID:          user.ID,
+		Name:        user.Name,
+		Email:       user.Email,
+		Role:        user.Role,
+		Activated:   user.Activated,
+		CreatedAt:   user.CreatedAt,
+		Permissions: permissions,
+		Version:     user.Version,
+	}
+}
+
+func (app *application) listRolesHandler(w http.ResponseWriter, r *http.Request) {
+	app.JSONResponse(w, http.StatusOK, envelope{
+		"roles":       data.Roles,
+		"permissions": data.Permissions,
+	})
+}
+
+func (app *application) listUsersHandler(w http.ResponseWriter, r *http.Request) {
+	users, err := app.models.Users.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	summaries := make([]userSummary, len(users))
+	for i, user := range users {
+		summaries[i] = newUserSummary(user)
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"users": summaries})
+}
+
+// updateUserRoleHandler changes a user's role and/or activation. Only a super
+// admin can grant or take away SUPER_ADMIN, and the last one can't be removed.
+func (app *application) updateUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	user, err := app.models.Users.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if app.preconditionFailed(w, r, user.Version) {
+		return
+	}
+
+	var input struct {
+		Role      *string `json:"role"`
+		Activated *bool   `json:"activated"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Role != nil || input.Activated != nil, "role", "must be provided")
+
+	newRole := user.Role
+	if input.Role != nil {
+		role, ok := data.GetRole(*input.Role)
+		v.Check(ok, "role", "is not a known role")
+		newRole = role.Name
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actorRole := app.contextGetRole(r)
+	touchesSuperAdmin := strings.EqualFold(user.Role, data.RoleSuperAdmin) || newRole == data.RoleSuperAdmin
+	if touchesSuperAdmin && !strings.EqualFold(actorRole, data.RoleSuperAdmin) {
+		app.JSONError(w, http.StatusForbidden, "Only a super admin can grant or change the super admin role")
+		return
+	}
+
+	activated := user.Activated
+	if input.Activated != nil {
+		activated = *input.Activated
+	}
+
+	if strings.EqualFold(user.Role, data.RoleSuperAdmin) && user.Activated && (newRole != data.RoleSuperAdmin || !activated) {
+		count, err := app.models.Users.CountByRole(data.RoleSuperAdmin)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if count <= 1 {
+			v.AddError("role", "cannot remove the last active super admin")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	previousRole := user.Role
+	before := newUserSummary(user)
+	user.Role = newRole
+	user.Activated = activated
+
+	err = app.models.Users.Update(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// A deactivated account is signed out everywhere and its links stop working
+	if before.Activated && !user.Activated {
+		if err := app.models.Sessions.DeleteAllForUser(user.ID, ""); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if err := app.models.Tokens.DeleteEveryScopeForUser(user.ID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	app.logger.Info("User role updated", "user_id", user.ID, "from", previousRole, "to", user.Role, "activated", user.Activated, "by", app.contextGetUser(r))
+
+	app.audit(r, data.AuditUpdate, "user", user.ID, before, newUserSummary(user))
+
+	app.setETag(w, user.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"user": newUserSummary(user)})
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"net/http"
 
+	"github.com/cconner57/adoption-os/backend/internal/data"
 	"github.com/rs/cors"
 )
 
@@ -18,54 +19,164 @@ func (app *application) routes() http.Handler {
 	mux.HandleFunc("GET /pets/spotlight", app.getSpotlightPets)
 	mux.HandleFunc("GET /pets/available", app.getAvailablePets)
 	mux.HandleFunc("GET /pets/adopted-count", app.getAdoptedPetsCount)
+	mux.HandleFunc("GET /pets/found", app.listFoundPetsHandler)
 
 	// Protected Routes (Applications & Metrics)
 	// We create a protected mux or just wrap handlers inline. Inline is easier for mixed usage here.
 	// Pet Management
-	mux.Handle("PUT /pets/{id}", app.requireLogin(http.HandlerFunc(app.updatePet)))
-	mux.Handle("POST /pets", app.requireLogin(http.HandlerFunc(app.createPet)))
+	mux.Handle("GET /pets/{id}", app.requireLogin(app.requirePermission(data.PermPetsWrite, http.HandlerFunc(app.getPet))))
+	mux.Handle("PUT /pets/{id}", app.requireLogin(app.requirePermission(data.PermPetsWrite, http.HandlerFunc(app.updatePet))))
+	mux.Handle("POST /pets", app.requireLogin(app.requirePermission(data.PermPetsWrite, http.HandlerFunc(app.createPet))))
 	mux.Handle("POST /applications/volunteer", http.HandlerFunc(app.submitVolunteerApplication))
 	mux.Handle("POST /applications/adoption", http.HandlerFunc(app.submitAdoptionApplication))
 	mux.Handle("POST /applications/surrender", http.HandlerFunc(app.submitSurrenderApplication))
-	mux.Handle("POST /metrics", app.requireAuthentication(http.HandlerFunc(app.submitMetric)))
+	mux.Handle("POST /applications/foster", http.HandlerFunc(app.submitFosterApplication))
+	mux.Handle("POST /metrics", app.requireAPIKey(data.ScopeMetricsWrite, http.HandlerFunc(app.submitMetric)))
+	mux.Handle("GET /v1/kennel-cards/{id}", app.requireAPIKey(data.ScopeKennelCardRead, http.HandlerFunc(app.getKennelCardHandler)))
 
 	// Application Management
-	mux.Handle("GET /v1/applications", app.requireLogin(http.HandlerFunc(app.listApplicationsHandler)))
-	mux.Handle("PUT /v1/applications/{id}", app.requireLogin(http.HandlerFunc(app.updateApplicationStatusHandler)))
-	mux.Handle("GET /v1/applications/{id}/original", app.requireLogin(http.HandlerFunc(app.getApplicationOriginalHandler)))
-	mux.Handle("POST /v1/applications/{id}/resend-email", app.requireLogin(http.HandlerFunc(app.resendApplicationEmailHandler)))
+	mux.Handle("GET /v1/applications", app.requireLogin(app.requirePermission(data.PermApplicationsRead, http.HandlerFunc(app.listApplicationsHandler))))
+	mux.Handle("PUT /v1/applications/{id}", app.requireLogin(app.requirePermission(data.PermApplicationsWrite, http.HandlerFunc(app.updateApplicationStatusHandler))))
+	mux.Handle("GET /v1/applications/{id}/original", app.requireLogin(app.requirePermission(data.PermApplicationsRead, http.HandlerFunc(app.getApplicationOriginalHandler))))
+	mux.Handle("POST /v1/applications/{id}/resend-email", app.requireLogin(app.requirePermission(data.PermApplicationsWrite, http.HandlerFunc(app.resendApplicationEmailHandler))))
+	mux.Handle("POST /v1/applications/{id}/triage", app.requireLogin(app.requirePermission(data.PermApplicationsWrite, http.HandlerFunc(app.triageApplicationHandler))))
+	mux.Handle("POST /v1/applications/{id}/intake", app.requireLogin(app.requirePermission(data.PermApplicationsWrite, http.HandlerFunc(app.intakeApplicationHandler))))
+
+	// Stray Intake
+	mux.Handle("GET /v1/strays", app.requireLogin(app.requirePermission(data.PermStraysManage, http.HandlerFunc(app.listStraysHandler))))
+	mux.Handle("POST /v1/strays", app.requireLogin(app.requirePermission(data.PermStraysManage, http.HandlerFunc(app.createStrayHandler))))
+	mux.Handle("GET /v1/strays/{id}", app.requireLogin(app.requirePermission(data.PermStraysManage, http.HandlerFunc(app.getStrayHandler))))
+	mux.Handle("PUT /v1/strays/{id}", app.requireLogin(app.requirePermission(data.PermStraysManage, http.HandlerFunc(app.updateStrayHandler))))
+
+	// Microchips
+	mux.Handle("GET /v1/microchips/{number}", app.requireLogin(app.requirePermission(data.PermMicrochipsManage, http.HandlerFunc(app.microchipLookupHandler))))
+	mux.Handle("GET /v1/microchip-registrations", app.requireLogin(app.requirePermission(data.PermMicrochipsManage, http.HandlerFunc(app.listMicrochipRegistrationsHandler))))
+	mux.Handle("PUT /v1/microchip-registrations/{id}", app.requireLogin(app.requirePermission(data.PermMicrochipsManage, http.HandlerFunc(app.updateMicrochipRegistrationHandler))))
+
+	// Returns
+	mux.Handle("GET /v1/returns", app.requireLogin(app.requirePermission(data.PermReturnsManage, http.HandlerFunc(app.listReturnsHandler))))
+	mux.Handle("PUT /v1/returns/{id}", app.requireLogin(app.requirePermission(data.PermReturnsManage, http.HandlerFunc(app.updateReturnHandler))))
+	mux.Handle("GET /v1/pets/{id}/returns", app.requireLogin(app.requirePermission(data.PermReturnsManage, http.HandlerFunc(app.listReturnsHandler))))
+	mux.Handle("POST /v1/pets/{id}/returns", app.requireLogin(app.requirePermission(data.PermReturnsManage, http.HandlerFunc(app.processReturnHandler))))
+	mux.Handle("GET /v1/reports/returns", app.requireLogin(app.requirePermission(data.PermReportsRead, http.HandlerFunc(app.returnReportHandler))))
+
+	// Shelter Locations
+	mux.Handle("GET /v1/locations", app.requireLogin(app.requirePermission(data.PermLocationsManage, http.HandlerFunc(app.listLocationsHandler))))
+	mux.Handle("POST /v1/locations", app.requireLogin(app.requirePermission(data.PermLocationsManage, http.HandlerFunc(app.createLocationHandler))))
+	mux.Handle("GET /v1/locations/occupancy", app.requireLogin(app.requirePermission(data.PermLocationsManage, http.HandlerFunc(app.occupancyHandler))))
+	mux.Handle("GET /v1/locations/{id}", app.requireLogin(app.requirePermission(data.PermLocationsManage, http.HandlerFunc(app.getLocationHandler))))
+	mux.Handle("PUT /v1/locations/{id}", app.requireLogin(app.requirePermission(data.PermLocationsManage, http.HandlerFunc(app.updateLocationHandler))))
+	mux.Handle("GET /v1/pets/{id}/locations", app.requireLogin(app.requirePermission(data.PermLocationsManage, http.HandlerFunc(app.listPetLocationsHandler))))
+	mux.Handle("POST /v1/pets/{id}/location", app.requireLogin(app.requirePermission(data.PermLocationsManage, http.HandlerFunc(app.movePetHandler))))
+
+	// Intake Forecasting
+	mux.Handle("GET /v1/forecast", app.requireLogin(app.requirePermission(data.PermReportsRead, http.HandlerFunc(app.forecastHandler))))
+
+	// Surrender Waitlist
+	mux.Handle("GET /v1/surrender-waitlist", app.requireLogin(app.requirePermission(data.PermApplicationsRead, http.HandlerFunc(app.listWaitlistHandler))))
+	mux.Handle("PUT /v1/surrender-waitlist/{id}", app.requireLogin(app.requirePermission(data.PermApplicationsWrite, http.HandlerFunc(app.updateWaitlistHandler))))
 
 	// Volunteer Management
-	mux.Handle("POST /v1/volunteers", app.requireLogin(http.HandlerFunc(app.createVolunteerHandler)))
-	mux.Handle("GET /v1/volunteers", app.requireLogin(http.HandlerFunc(app.listVolunteersHandler)))
-	mux.Handle("GET /v1/volunteers/{id}", app.requireLogin(http.HandlerFunc(app.getVolunteerHandler)))
-	mux.Handle("PUT /v1/volunteers/{id}", app.requireLogin(http.HandlerFunc(app.updateVolunteerHandler)))
+	mux.Handle("POST /v1/volunteers", app.requireLogin(app.requirePermission(data.PermVolunteersWrite, http.HandlerFunc(app.createVolunteerHandler))))
+	mux.Handle("GET /v1/volunteers", app.requireLogin(app.requirePermission(data.PermVolunteersRead, http.HandlerFunc(app.listVolunteersHandler))))
+	mux.Handle("GET /v1/volunteers/{id}", app.requireLogin(app.requirePermission(data.PermVolunteersRead, http.HandlerFunc(app.getVolunteerHandler))))
+	mux.Handle("PUT /v1/volunteers/{id}", app.requireLogin(app.requirePermission(data.PermVolunteersWrite, http.HandlerFunc(app.updateVolunteerHandler))))
+	mux.Handle("GET /v1/volunteers/{id}/stats", app.requireLogin(app.requirePermission(data.PermVolunteersRead, http.HandlerFunc(app.getVolunteerStatsHandler))))
+	mux.HandleFunc("GET /v1/volunteers/leaderboard", app.getLeaderboardHandler) // Public, opted-in volunteers only
+	mux.Handle("GET /v1/volunteers/{id}/hours", app.requireLogin(app.requirePermission(data.PermReportsRead, http.HandlerFunc(app.getVolunteerHoursHandler))))
+	mux.Handle("GET /v1/volunteers/hours/export", app.requireLogin(app.requirePermission(data.PermReportsRead, http.HandlerFunc(app.exportVolunteerHoursHandler))))
 
 	// Shift Management
-	mux.Handle("POST /v1/shifts", app.requireLogin(http.HandlerFunc(app.createShiftHandler)))
-	mux.Handle("GET /v1/shifts", app.requireLogin(http.HandlerFunc(app.listShiftsHandler))) // Added
-	mux.Handle("GET /v1/volunteers/{id}/shifts", app.requireLogin(http.HandlerFunc(app.listVolunteerShiftsHandler)))
-	mux.Handle("PUT /v1/shifts/{id}", app.requireLogin(http.HandlerFunc(app.updateShiftHandler)))
-	mux.Handle("DELETE /v1/shifts/{id}", app.requireLogin(http.HandlerFunc(app.deleteShiftHandler)))
-	mux.Handle("GET /v1/shifts/meta/roles", app.requireLogin(http.HandlerFunc(app.getShiftRoleStatsHandler)))
+	mux.Handle("POST /v1/shifts", app.requireLogin(app.requirePermission(data.PermShiftsWrite, http.HandlerFunc(app.createShiftHandler))))
+	mux.Handle("GET /v1/shifts", app.requireLogin(app.requirePermission(data.PermShiftsRead, http.HandlerFunc(app.listShiftsHandler)))) // Added
+	mux.Handle("GET /v1/volunteers/{id}/shifts", app.requireLogin(app.requirePermission(data.PermShiftsRead, http.HandlerFunc(app.listVolunteerShiftsHandler))))
+	mux.Handle("GET /v1/shifts/{id}", app.requireLogin(app.requirePermission(data.PermShiftsRead, http.HandlerFunc(app.getShiftHandler))))
+	mux.Handle("PUT /v1/shifts/{id}", app.requireLogin(app.requirePermission(data.PermShiftsWrite, http.HandlerFunc(app.updateShiftHandler))))
+	mux.Handle("DELETE /v1/shifts/{id}", app.requireLogin(app.requirePermission(data.PermShiftsWrite, http.HandlerFunc(app.deleteShiftHandler))))
+	mux.Handle("GET /v1/shifts/meta/roles", app.requireLogin(app.requirePermission(data.PermShiftsRead, http.HandlerFunc(app.getShiftRoleStatsHandler))))
+	mux.Handle("GET /v1/shifts/gaps", app.requireLogin(app.requirePermission(data.PermShiftsRead, http.HandlerFunc(app.getShiftGapsHandler))))
+	mux.Handle("GET /v1/shifts/staffing-rules", app.requireLogin(app.requirePermission(data.PermShiftsRead, http.HandlerFunc(app.listStaffingRulesHandler))))
+	mux.Handle("PUT /v1/shifts/staffing-rules", app.requireLogin(app.requirePermission(data.PermStaffingWrite, http.HandlerFunc(app.upsertStaffingRuleHandler))))
+	mux.Handle("DELETE /v1/shifts/staffing-rules/{id}", app.requireLogin(app.requirePermission(data.PermStaffingWrite, http.HandlerFunc(app.deleteStaffingRuleHandler))))
+
+	// Foster Homes
+	mux.Handle("GET /v1/fosters", app.requireLogin(app.requirePermission(data.PermFostersRead, http.HandlerFunc(app.listFostersHandler))))
+	mux.Handle("POST /v1/fosters", app.requireLogin(app.requirePermission(data.PermFostersWrite, http.HandlerFunc(app.createFosterHandler))))
+	mux.Handle("GET /v1/fosters/{id}", app.requireLogin(app.requirePermission(data.PermFostersRead, http.HandlerFunc(app.getFosterHandler))))
+	mux.Handle("PUT /v1/fosters/{id}", app.requireLogin(app.requirePermission(data.PermFostersWrite, http.HandlerFunc(app.updateFosterHandler))))
+	mux.Handle("GET /v1/fosters/{id}/pets", app.requireLogin(app.requirePermission(data.PermFostersRead, http.HandlerFunc(app.listFosterPetsHandler))))
+	mux.Handle("POST /v1/fosters/{id}/placements", app.requireLogin(app.requirePermission(data.PermFostersWrite, http.HandlerFunc(app.createPlacementHandler))))
+	mux.Handle("POST /v1/fosters/{id}/placements/{placementId}/end", app.requireLogin(app.requirePermission(data.PermFostersWrite, http.HandlerFunc(app.endPlacementHandler))))
+	mux.Handle("POST /v1/fosters/{id}/invite", app.requireLogin(app.requirePermission(data.PermFostersWrite, http.HandlerFunc(app.inviteFosterHandler))))
+	mux.Handle("GET /v1/foster-updates", app.requireLogin(app.requirePermission(data.PermFostersRead, http.HandlerFunc(app.listFosterUpdatesHandler))))
+	mux.Handle("POST /v1/foster-updates/{id}/approve", app.requireLogin(app.requirePermission(data.PermFostersWrite, http.HandlerFunc(app.approveFosterUpdateHandler))))
+	mux.Handle("POST /v1/foster-updates/{id}/reject", app.requireLogin(app.requirePermission(data.PermFostersWrite, http.HandlerFunc(app.rejectFosterUpdateHandler))))
+	mux.Handle("GET /v1/pets/{id}/fosters", app.requireLogin(app.requirePermission(data.PermFostersRead, http.HandlerFunc(app.listPetFostersHandler))))
+
+	// Foster Portal (scoped to the signed-in foster's current pets)
+	mux.Handle("GET /v1/foster-portal", app.requireLogin(app.requirePermission(data.PermFosterPortal, http.HandlerFunc(app.getFosterPortalHandler))))
+	mux.Handle("GET /v1/foster-portal/updates", app.requireLogin(app.requirePermission(data.PermFosterPortal, http.HandlerFunc(app.listPortalUpdatesHandler))))
+	mux.Handle("POST /v1/foster-portal/pets/{id}/updates", app.requireLogin(app.requirePermission(data.PermFosterPortal, http.HandlerFunc(app.submitPortalUpdateHandler))))
+	mux.Handle("POST /v1/foster-portal/pets/{id}/photos", app.requireLogin(app.requirePermission(data.PermFosterPortal, http.HandlerFunc(app.uploadPortalPhotoHandler))))
 
 	// Marketing Management
-	mux.Handle("GET /v1/marketing/campaigns", app.requireLogin(http.HandlerFunc(app.listCampaignsHandler)))
-	mux.Handle("GET /v1/marketing/campaigns/{id}", app.requireLogin(http.HandlerFunc(app.getCampaignHandler)))
-	mux.Handle("POST /v1/marketing/campaigns", app.requireLogin(http.HandlerFunc(app.createCampaignHandler)))
-	mux.Handle("PUT /v1/marketing/campaigns/{id}", app.requireLogin(http.HandlerFunc(app.updateCampaignHandler)))
+	mux.Handle("GET /v1/marketing/campaigns", app.requireLogin(app.requirePermission(data.PermMarketingRead, http.HandlerFunc(app.listCampaignsHandler))))
+	mux.Handle("GET /v1/marketing/campaigns/{id}", app.requireLogin(app.requirePermission(data.PermMarketingRead, http.HandlerFunc(app.getCampaignHandler))))
+	mux.Handle("POST /v1/marketing/campaigns", app.requireLogin(app.requirePermission(data.PermMarketingWrite, http.HandlerFunc(app.createCampaignHandler))))
+	mux.Handle("PUT /v1/marketing/campaigns/{id}", app.requireLogin(app.requirePermission(data.PermMarketingWrite, http.HandlerFunc(app.updateCampaignHandler))))
 
 	// User Authentication
 	mux.HandleFunc("POST /api/register", app.registerUserHandler)
 	mux.HandleFunc("GET /api/validate-invite/{token}", app.validateInviteHandler)
-	mux.Handle("POST /api/contracts/generate", app.requireLogin(app.requireAdmin(http.HandlerFunc(app.generateContractHandler))))
+	mux.Handle("POST /api/contracts/generate", app.requireLogin(app.requirePermission(data.PermContractsWrite, http.HandlerFunc(app.generateContractHandler))))
 	mux.HandleFunc("POST /api/contracts/submit", app.submitContractHandler)
 	mux.HandleFunc("POST /api/users", app.registerUserHandler) // Keep existing alias if needed, or remove. keeping for safety.
 	mux.HandleFunc("POST /api/login", app.loginUserHandler)
+	mux.HandleFunc("POST /api/login/mfa", app.verifyLoginMFAHandler)
+	mux.HandleFunc("GET /api/auth/oidc/login", app.oidcLoginHandler)
+	mux.HandleFunc("GET /api/auth/oidc/callback", app.oidcCallbackHandler)
+	mux.HandleFunc("POST /api/auth/oidc/session", app.oidcSessionHandler)
+	mux.HandleFunc("POST /api/users/verify-email", app.verifyEmailHandler)
+	mux.HandleFunc("POST /api/users/verify-email/resend", app.resendVerificationHandler)
+	mux.HandleFunc("POST /api/users/password-reset", app.requestPasswordResetHandler)
+	mux.HandleFunc("PUT /api/users/password", app.resetPasswordHandler)
+	mux.HandleFunc("POST /api/users/unlock", app.unlockAccountHandler)
 	mux.HandleFunc("POST /api/users/logout", app.logoutUserHandler)
 	mux.Handle("GET /api/users/me", app.requireLogin(http.HandlerFunc(app.profileUserHandler)))
-	mux.Handle("PUT /api/users", app.requireLogin(http.HandlerFunc(app.updateUserHandler)))
-	mux.Handle("POST /api/admin/invite", app.requireLogin(app.requireAdmin(http.HandlerFunc(app.inviteUserHandler))))
+	mux.Handle("PUT /api/users", app.requireLogin(app.requireMFA(http.HandlerFunc(app.updateUserHandler))))
+	mux.Handle("GET /api/users/me/sessions", app.requireLogin(app.requireMFA(http.HandlerFunc(app.listMySessionsHandler))))
+	mux.Handle("DELETE /api/users/me/sessions", app.requireLogin(app.requireMFA(http.HandlerFunc(app.revokeAllMySessionsHandler))))
+	mux.Handle("DELETE /api/users/me/sessions/{id}", app.requireLogin(app.requireMFA(http.HandlerFunc(app.revokeMySessionHandler))))
+	mux.Handle("POST /api/users/me/download-tokens", app.requireLogin(app.requireMFA(http.HandlerFunc(app.createDownloadTokenHandler))))
+	mux.Handle("GET /api/users/me/mfa", app.requireLogin(http.HandlerFunc(app.getMFAStatusHandler)))
+	mux.Handle("DELETE /api/users/me/mfa", app.requireLogin(app.requireMFA(http.HandlerFunc(app.disableMFAHandler))))
+	mux.Handle("POST /api/users/me/mfa/totp", app.requireLogin(http.HandlerFunc(app.beginTOTPEnrollmentHandler)))
+	mux.Handle("POST /api/users/me/mfa/totp/confirm", app.requireLogin(http.HandlerFunc(app.confirmTOTPEnrollmentHandler)))
+	mux.Handle("POST /api/users/me/mfa/recovery-codes", app.requireLogin(app.requireMFA(http.HandlerFunc(app.regenerateRecoveryCodesHandler))))
+	mux.Handle("POST /api/admin/invite", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.inviteUserHandler))))
+	mux.Handle("GET /api/admin/invites", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.listInvitationsHandler))))
+	mux.Handle("POST /api/admin/invites/bulk", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.bulkInviteHandler))))
+	mux.Handle("POST /api/admin/invites/{id}/resend", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.resendInvitationHandler))))
+	mux.Handle("DELETE /api/admin/invites/{id}", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.revokeInvitationHandler))))
+
+	// Role Management
+	mux.Handle("GET /api/admin/roles", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.listRolesHandler))))
+	mux.Handle("GET /api/admin/users", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.listUsersHandler))))
+	mux.Handle("PUT /api/admin/users/{id}/role", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.updateUserRoleHandler))))
+	mux.Handle("GET /api/admin/api-keys", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.listAPIKeysHandler))))
+	mux.Handle("POST /api/admin/api-keys", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.createAPIKeyHandler))))
+	mux.Handle("DELETE /api/admin/api-keys/{id}", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.revokeAPIKeyHandler))))
+	mux.Handle("POST /api/admin/users/{id}/unlock", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.adminUnlockUserHandler))))
+	mux.Handle("GET /api/admin/login-attempts", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.listLoginAttemptsHandler))))
+	mux.Handle("DELETE /api/admin/users/{id}/sessions", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.revokeUserSessionsHandler))))
+	mux.Handle("DELETE /api/admin/users/{id}/mfa", app.requireLogin(app.requirePermission(data.PermUsersManage, http.HandlerFunc(app.resetUserMFAHandler))))
+	mux.Handle("GET /v1/audit", app.requireLogin(app.requirePermission(data.PermAuditRead, http.HandlerFunc(app.listAuditHandler))))
+
+	// Data Subject Requests
+	mux.Handle("GET /v1/privacy/search", app.requireLogin(app.requirePermission(data.PermPrivacyManage, http.HandlerFunc(app.searchPrivacyHandler))))
+	mux.Handle("POST /v1/privacy/export", app.requireLogin(app.requirePermission(data.PermPrivacyManage, http.HandlerFunc(app.exportPrivacyHandler))))
+	mux.Handle("POST /v1/privacy/erase", app.requireLogin(app.requirePermission(data.PermPrivacyManage, http.HandlerFunc(app.erasePrivacyHandler))))
+	mux.Handle("GET /v1/privacy/requests", app.requireLogin(app.requirePermission(data.PermPrivacyManage, http.HandlerFunc(app.listPrivacyRequestsHandler))))
 
 	// Static Files (Uploads)
 	// fileServer := http.FileServer(http.Dir("./uploads"))
@@ -78,17 +189,18 @@ func (app *application) routes() http.Handler {
 
 	// Upload Route
 	// Upload Route
-	mux.Handle("POST /pets/{id}/photos", app.requireLogin(http.HandlerFunc(app.uploadPetPhotoHandler)))
+	mux.Handle("POST /pets/{id}/photos", app.requireLogin(app.requirePermission(data.PermPetsWrite, http.HandlerFunc(app.uploadPetPhotoHandler))))
 
 	// Notifications
 	mux.HandleFunc("POST /v1/notifications/subscribe", app.subscribeHandler)
-	mux.HandleFunc("POST /v1/notifications/test", app.testNotificationHandler)                                   // New Test Route
-	mux.Handle("POST /v1/notifications/broadcast", app.requireLogin(http.HandlerFunc(app.sendBroadcastHandler))) // Admin only
+	mux.HandleFunc("POST /v1/notifications/test", app.testNotificationHandler)                                                                                           // New Test Route
+	mux.Handle("POST /v1/notifications/broadcast", app.requireLogin(app.requirePermission(data.PermNotificationsBroadcast, http.HandlerFunc(app.sendBroadcastHandler)))) // Admin only
 
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5173", "http://127.0.0.1:5173", "https://idohr.app", "https://www.idohr.app"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-KEY"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-KEY", "If-Match"},
+		ExposedHeaders:   []string{"ETag", "X-Session-Token"},
 		AllowCredentials: true,
 	})
 
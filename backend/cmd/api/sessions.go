@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// downloadTokenTTL only needs to cover the gap between minting and opening the link.
+const downloadTokenTTL = 2 * time.Minute
+
+// readSessionToken reads the Bearer token, falling back to the session cookie.
+func (app *application) readSessionToken(r *http.Request) string {
+	if token := readBearerToken(r); token != "" {
+		return token
+	}
+
+	cookie, err := r.Cookie("session_token")
+	if err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
+
+func readBearerToken(r *http.Request) string {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+		return authHeader[7:]
+	}
+	return ""
+}
+
+func (app *application) sessionCookie(value string, expires time.Time) *http.Cookie {
+	cookieMode := http.SameSiteLaxMode
+	isSecure := false
+
+	if app.config.env == "production" {
+		cookieMode = http.SameSiteNoneMode
+		isSecure = true
+	}
+
+	return &http.Cookie{
+		Name:     "session_token",
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   isSecure,
+		SameSite: cookieMode,
+	}
+}
+
+func (app *application) setSessionCookie(w http.ResponseWriter, token string, expires time.Time) {
+	http.SetCookie(w, app.sessionCookie(token, expires))
+}
+
+func (app *application) clearSessionCookie(w http.ResponseWriter) {
+	cookie := app.sessionCookie("", time.Unix(0, 0))
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+}
+
+// clientIP strips the port from RemoteAddr; sessions.ip is an inet column.
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+func (app *application) listMySessionsHandler(w http.ResponseWriter, r *http.Request) {
+	sessions, err := app.models.Sessions.GetAllForUser(app.contextGetUser(r))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var currentID int64
+	if current := app.contextGetSession(r); current != nil {
+		currentID = current.ID
+	}
+
+	type sessionView struct {
+		*data.Session
+		Current bool `json:"current"`
+	}
+
+	views := make([]sessionView, len(sessions))
+	for i, s := range sessions {
+		views[i] = sessionView{Session: s, Current: s.ID == currentID}
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"sessions": views})
+}
+
+func (app *application) revokeMySessionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	deleted, err := app.models.Sessions.Delete(app.contextGetUser(r), id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !deleted {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if current := app.contextGetSession(r); current != nil && current.ID == id {
+		app.clearSessionCookie(w)
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "session revoked"})
+}
+
+// revokeAllMySessionsHandler is "log out everywhere", including this device.
+func (app *application) revokeAllMySessionsHandler(w http.ResponseWriter, r *http.Request) {
+	err := app.models.Sessions.DeleteAllForUser(app.contextGetUser(r), "")
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.clearSessionCookie(w)
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "signed out of all sessions"})
+}
+
+// revokeUserSessionsHandler lets an admin force a user to sign in again,
+// e.g. after a lost laptop or a role change.
+func (app *application) revokeUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	user, err := app.models.Users.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if strings.EqualFold(user.Role, data.RoleSuperAdmin) && !strings.EqualFold(app.contextGetRole(r), data.RoleSuperAdmin) {
+		app.JSONError(w, http.StatusForbidden, "Only a super admin can end a super admin's sessions")
+		return
+	}
+
+	if err = app.models.Sessions.DeleteAllForUser(user.ID, ""); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.audit(r, "revoke_sessions", "user", user.ID, nil, nil)
+
+	app.logger.Warn("Sessions revoked by admin", "user_id", user.ID, "by", app.contextGetUser(r))
+
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "all sessions revoked"})
+}
+
+// createDownloadTokenHandler mints a single-use token for one GET path, for
+// links opened outside fetch (window.open, <a download>).
+func (app *application) createDownloadTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Path string `json:"path"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(strings.HasPrefix(input.Path, "/") && !strings.ContainsAny(input.Path, "?#"), "path", "must be an absolute path without a query string")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	session := app.contextGetSession(r)
+	if session == nil {
+		app.JSONError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	token, expiry, err := app.models.Tokens.NewForSession(session.UserID, session.ID, data.ScopeDownloadPrefix+input.Path, downloadTokenTTL)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusCreated, envelope{
+		"token":     token,
+		"expiresAt": expiry,
+	})
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// validateShiftScheduling adds validation errors when a shift would double-book
+// its volunteer or push a role over the capacity configured for that day-part.
+func (app *application) validateShiftScheduling(v *validator.Validator, shift *data.Shift) error {
+	if _, err := data.ParseShiftTime(shift.StartTime); err != nil {
+		v.AddError("startTime", "must be a valid time (HH:MM)")
+	}
+	if _, err := data.ParseShiftTime(shift.EndTime); err != nil {
+		v.AddError("endTime", "must be a valid time (HH:MM)")
+	}
+	if _, err := time.Parse("2006-01-02", shift.Date); err != nil {
+		v.AddError("date", "must be a valid date (YYYY-MM-DD)")
+	}
+
+	// Cancelled/covered shifts don't hold a slot, so there's nothing to check
+	if !v.Valid() || !data.CountsTowardStaffing(shift.Status) {
+		return nil
+	}
+
+	sameDay, err := app.models.Shifts.GetAll(shift.Date, shift.Date)
+	if err != nil {
+		return err
+	}
+
+	if conflicts := data.FindShiftConflicts(shift, sameDay); len(conflicts) > 0 {
+		c := conflicts[0]
+		v.AddError("volunteerId", fmt.Sprintf("is already scheduled from %s to %s on %s (shift #%d)", c.StartTime, c.EndTime, c.Date, c.ID))
+	}
+
+	rules, err := app.models.StaffingRules.GetAll()
+	if err != nil {
+		return err
+	}
+
+	dayPart := data.ShiftDayPart(shift.StartTime)
+	for _, rule := range rules {
+		if rule.Role != shift.Role || rule.DayPart != dayPart || rule.Capacity <= 0 {
+			continue
+		}
+		if data.CountRoleSlot(sameDay, shift.Date, shift.Role, dayPart, shift.ID) >= rule.Capacity {
+			v.AddError("role", fmt.Sprintf("%s is already at capacity (%d) for the %s of %s", shift.Role, rule.Capacity, dayPart, shift.Date))
+		}
+	}
+
+	return app.validateMinorShift(v, shift, sameDay)
+}
+
+func (app *application) getShiftGapsHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	today := time.Now().Format("2006-01-02")
+
+	fromStr := app.readString(qs, "from", today)
+	toStr := app.readString(qs, "to", "")
+
+	v := validator.New()
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		v.AddError("from", "must be a valid date (YYYY-MM-DD)")
+	}
+
+	to := from.AddDate(0, 0, 6)
+	if toStr != "" {
+		to, err = time.Parse("2006-01-02", toStr)
+		if err != nil {
+			v.AddError("to", "must be a valid date (YYYY-MM-DD)")
+		}
+	}
+
+	if v.Valid() {
+		v.Check(!to.Before(from), "to", "must not be before from")
+		v.Check(to.Sub(from) <= 92*24*time.Hour, "to", "range must not exceed 92 days")
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	gaps, err := app.findStaffingGaps(from, to)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{
+		"from": from.Format("2006-01-02"),
+		"to":   to.Format("2006-01-02"),
+		"gaps": gaps,
+	})
+}
+
+func (app *application) findStaffingGaps(from, to time.Time) ([]data.StaffingGap, error) {
+	rules, err := app.models.StaffingRules.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	shifts, err := app.models.Shifts.GetAll(from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	return data.FindStaffingGaps(rules, shifts, from, to), nil
+}
+
+func (app *application) listStaffingRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := app.models.StaffingRules.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"rules": rules})
+}
+
+func (app *application) upsertStaffingRuleHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Role          string `json:"role"`
+		DayPart       string `json:"dayPart"`
+		MinVolunteers int    `json:"minVolunteers"`
+		Capacity      int    `json:"capacity"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Role != "", "role", "must be provided")
+	v.Check(data.IsPermittedValue(input.DayPart, data.DayParts...), "dayPart", "must be one of morning, afternoon, evening")
+	v.Check(input.MinVolunteers >= 0, "minVolunteers", "must not be negative")
+	v.Check(input.Capacity >= 0, "capacity", "must not be negative")
+	if input.Capacity > 0 {
+		v.Check(input.Capacity >= input.MinVolunteers, "capacity", "must be at least the minimum staffing")
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	rule := &data.StaffingRule{
+		Role:          input.Role,
+		DayPart:       input.DayPart,
+		MinVolunteers: input.MinVolunteers,
+		Capacity:      input.Capacity,
+	}
+
+	// Fetched for the audit diff; an upsert doesn't say what it replaced
+	existing, err := app.models.StaffingRules.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	before := findStaffingRule(existing, func(sr *data.StaffingRule) bool {
+		return sr.Role == rule.Role && sr.DayPart == rule.DayPart
+	})
+
+	err = app.models.StaffingRules.Upsert(rule)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if before == nil {
+		app.audit(r, data.AuditCreate, "staffing_rule", rule.ID, nil, rule)
+	} else {
+		app.audit(r, data.AuditUpdate, "staffing_rule", rule.ID, before, rule)
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"rule": rule})
+}
+
+func (app *application) deleteStaffingRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	existing, err := app.models.StaffingRules.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	before := findStaffingRule(existing, func(sr *data.StaffingRule) bool { return sr.ID == id })
+
+	err = app.models.StaffingRules.Delete(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, data.AuditDelete, "staffing_rule", id, before, nil)
+
+	app.JSONResponse(w, http.StatusOK, envelope{"message": "staffing rule deleted successfully"})
+}
+
+func findStaffingRule(rules []*data.StaffingRule, match func(*data.StaffingRule) bool) *data.StaffingRule {
+	for _, rule := range rules {
+		if match(rule) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// sendStaffingGapDigest emails coordinators a summary of understaffed slots
+// for the coming week. Runs from the daily background worker.
+func (app *application) sendStaffingGapDigest() {
+	y, m, d := time.Now().Date()
+	from := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 6)
+
+	gaps, err := app.findStaffingGaps(from, to)
+	if err != nil {
+		app.logger.Error("Staffing digest: failed to compute gaps", "error", err)
+		return
+	}
+
+	if len(gaps) == 0 {
+		app.logger.Info("Staffing digest: no gaps in the coming week")
+		return
+	}
+
+	coordinators, err := app.models.Users.GetByRoles(data.RoleAdmin, data.RoleSuperAdmin, data.RoleVolunteerCoordinator)
+	if err != nil {
+		app.logger.Error("Staffing digest: failed to load coordinators", "error", err)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+  body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+  .container { max-width: 600px; margin: 0 auto; padding: 20px; border: 1px solid #e0e0e0; border-radius: 8px; }
+  h1 { color: #00a5ad; font-size: 22px; }
+  .table { width: 100%; border-collapse: collapse; margin-top: 10px; }
+  .table th, .table td { border: 1px solid #ddd; padding: 8px; text-align: left; font-size: 14px; }
+  .table th { background-color: #f2f2f2; }
+  .short { color: #c0392b; font-weight: bold; }
+</style>
+</head>
+<body>
+<div class="container">
+  <h1>Understaffed Shifts This Week</h1>
+  <table class="table">
+    <tr><th>Date</th><th>Time of Day</th><th>Role</th><th>Scheduled</th><th>Needed</th></tr>
+`)
+	for _, g := range gaps {
+		fmt.Fprintf(&sb, `    <tr><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td class="short">%d more</td></tr>
+`, g.Date, g.DayPart, g.Role, g.Scheduled, g.Shortfall)
+	}
+	sb.WriteString(`  </table>
+</div>
+</body>
+</html>`)
+
+	subject := fmt.Sprintf("Schedule Alert: %d understaffed shift slot(s) this week", len(gaps))
+	body := sb.String()
+
+	for _, user := range coordinators {
+		if app.config.smtp.password == "" || app.config.smtp.username == "" {
+			app.logger.Info("Development Mode: Simulating sending email", "recipient", user.Email, "subject", subject)
+			continue
+		}
+		if err := app.mailer.Send(user.Email, subject, body, nil); err != nil {
+			app.logger.Error("Staffing digest: failed to send email", "recipient", user.Email, "error", err)
+		}
+	}
+}
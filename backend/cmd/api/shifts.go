@@ -39,7 +39,13 @@ func (app *application) createShiftHandler(w http.ResponseWriter, r *http.Reques
 	if input.Date == "" {
 		v.AddError("date", "must be provided")
 	}
-	// Basic validation, detailed validation can be added later
+
+	if v.Valid() {
+		if err := app.validateShiftScheduling(v, shift); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
 
 	if !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
@@ -52,8 +58,11 @@ func (app *application) createShiftHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.audit(r, data.AuditCreate, "shift", shift.ID, nil, shift)
+
 	// Recalculate stats in bg or sync? Sync is fine for now.
 	app.recalculateVolunteerStats(shift.VolunteerID)
+	go app.notifyParentOfScheduleChange(shift, "added")
 
 	app.JSONResponse(w, http.StatusCreated, envelope{"shift": shift})
 }
@@ -74,6 +83,28 @@ func (app *application) listVolunteerShiftsHandler(w http.ResponseWriter, r *htt
 	app.JSONResponse(w, http.StatusOK, envelope{"shifts": shifts})
 }
 
+func (app *application) getShiftHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	shift, err := app.models.Shifts.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.setETag(w, shift.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"shift": shift})
+}
+
 func (app *application) updateShiftHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParam(r)
 	if err != nil {
@@ -109,6 +140,12 @@ func (app *application) updateShiftHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if app.preconditionFailed(w, r, shift.Version) {
+		return
+	}
+
+	before := *shift
+
 	var input struct {
 		Date        *string `json:"date"`
 		StartTime   *string `json:"startTime"`
@@ -150,6 +187,28 @@ func (app *application) updateShiftHandler(w http.ResponseWriter, r *http.Reques
 		v.AddError("date", "must be provided")
 	}
 
+	if v.Valid() {
+		if err := app.validateShiftScheduling(v, shift); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	if v.Valid() {
+		message, err := app.strandedMinors(&before, shift)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if message != "" {
+			field := "status"
+			if data.CountsTowardStaffing(shift.Status) {
+				field = "startTime"
+			}
+			v.AddError(field, message)
+		}
+	}
+
 	if !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -158,6 +217,8 @@ func (app *application) updateShiftHandler(w http.ResponseWriter, r *http.Reques
 	err = app.models.Shifts.Update(shift)
 	if err != nil {
 		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
 		case errors.Is(err, data.ErrEditConflict):
 			app.editConflictResponse(w, r)
 		default:
@@ -166,8 +227,18 @@ func (app *application) updateShiftHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.audit(r, data.AuditUpdate, "shift", shift.ID, &before, shift)
+
 	app.recalculateVolunteerStats(shift.VolunteerID)
 
+	switch {
+	case data.CountsTowardStaffing(before.Status) && !data.CountsTowardStaffing(shift.Status):
+		go app.notifyParentOfScheduleChange(shift, "cancelled")
+	case before.Date != shift.Date || before.StartTime != shift.StartTime || before.EndTime != shift.EndTime || before.Role != shift.Role:
+		go app.notifyParentOfScheduleChange(shift, "changed")
+	}
+
+	app.setETag(w, shift.Version)
 	app.JSONResponse(w, http.StatusOK, envelope{"shift": shift})
 }
 
@@ -185,6 +256,16 @@ func (app *application) deleteShiftHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	message, err := app.strandedMinors(shift, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if message != "" {
+		app.JSONError(w, http.StatusConflict, "Deleting this shift "+message)
+		return
+	}
+
 	err = app.models.Shifts.Delete(id)
 	if err != nil {
 		switch {
@@ -196,7 +277,12 @@ func (app *application) deleteShiftHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.audit(r, data.AuditDelete, "shift", shift.ID, shift, nil)
+
 	app.recalculateVolunteerStats(shift.VolunteerID)
+	if shift.Status == "scheduled" {
+		go app.notifyParentOfScheduleChange(shift, "cancelled")
+	}
 
 	app.JSONResponse(w, http.StatusOK, envelope{"message": "shift deleted successfully"})
 }
@@ -1,9 +1,15 @@
 package main
 
 import (
-	"sort"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
 	"strings"
 	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
 )
 
 func (app *application) recalculateVolunteerStats(volunteerID int64) error {
@@ -12,149 +18,172 @@ func (app *application) recalculateVolunteerStats(volunteerID int64) error {
 		return err
 	}
 
-	currentYear := time.Now().Year()
+	// Archive every year so totals from prior years survive the January reset
+	yearly := data.ComputeYearlyStats(volunteerID, shifts)
+	for _, stats := range yearly {
+		if err := app.models.VolunteerStats.Upsert(stats); err != nil {
+			return err
+		}
+	}
 
-	// Filter shifts for current year only
-	var currentYearShifts []struct {
-		Timestamp time.Time
-		Status    string
-		StartTime string
-		EndTime   string
-		Notes     string
+	// The volunteer profile only shows the current year
+	current, ok := yearly[time.Now().Year()]
+	if !ok {
+		current = &data.VolunteerYearStats{}
 	}
 
-	for _, s := range shifts {
-		// Skip future/scheduled shifts
-		if s.Status == "scheduled" {
-			continue
-		}
+	err = app.models.Volunteers.UpdateStats(volunteerID, current.ReliabilityScore, int(current.TotalHours), current.Streak)
+	if err != nil {
+		return err
+	}
 
-		// Parse Date
-		date, err := time.Parse("2006-01-02", s.Date)
-		if err != nil {
-			continue
-		}
+	return app.awardVolunteerBadges(volunteerID, shifts)
+}
 
-		// Strictly filter for current year
-		if date.Year() != currentYear {
-			continue
-		}
+// awardVolunteerBadges runs the badge rules over the volunteer's full history
+// and adds any newly earned badges.
+func (app *application) awardVolunteerBadges(volunteerID int64, shifts []*data.Shift) error {
+	volunteer, err := app.models.Volunteers.Get(volunteerID)
+	if err != nil {
+		return err
+	}
 
-		// Parse Time (Attempt 24h then 12h)
-		t, err := time.Parse("15:04", s.StartTime)
-		if err != nil {
-			t, err = time.Parse("3:04 PM", s.StartTime)
-		}
-		// If time fails, default to midnight
-		if err != nil {
-			t = time.Date(0, 0, 0, 0, 0, 0, 0, time.UTC)
-		}
+	awarded := data.NewBadges(data.SummarizeActivity(shifts), volunteer.Badges)
+	if len(awarded) == 0 {
+		return nil
+	}
 
-		// Combine Date + Time
-		timestamp := time.Date(
-			date.Year(), date.Month(), date.Day(),
-			t.Hour(), t.Minute(), 0, 0,
-			time.Local,
-		)
-
-		currentYearShifts = append(currentYearShifts, struct {
-			Timestamp time.Time
-			Status    string
-			StartTime string
-			EndTime   string
-			Notes     string
-		}{
-			Timestamp: timestamp,
-			Status:    s.Status,
-			StartTime: s.StartTime,
-			EndTime:   s.EndTime,
-			Notes:     s.Notes,
-		})
-	}
-
-	// 1. Calculate Total Hours (Current Year)
-	var totalHours float64
-	for _, s := range currentYearShifts {
-		if s.Status == "completed" || s.Status == "all_good" || s.Status == "late" {
-			start, err1 := time.Parse("15:04", s.StartTime)
-			end, err2 := time.Parse("15:04", s.EndTime)
-
-			if err1 != nil {
-				start, err1 = time.Parse("3:04 PM", s.StartTime)
-			}
-			if err2 != nil {
-				end, err2 = time.Parse("3:04 PM", s.EndTime)
-			}
-
-			if err1 == nil && err2 == nil {
-				duration := end.Sub(start).Hours()
-				if duration < 0 {
-					duration += 24
-				}
-				if duration > 0 {
-					totalHours += duration
-				}
-			}
-		}
+	err = app.models.Volunteers.AwardBadges(volunteerID, awarded)
+	if err != nil {
+		return err
 	}
 
-	// 2. Calculate Reliability (Current Year)
-	score := 0.0
-	// Base points
-	// Completed: +20
-	// Late: +10
-	// Missed: -50
-	// Covered: -5 / -10 / -20
-	// Bonus: +10 / +20
-
-	for _, s := range currentYearShifts {
-		// Base Score
-		switch s.Status {
-		case "completed", "all_good":
-			score += 20
-		case "late":
-			score += 10
-		case "missed", "no_show":
-			score -= 50
-		case "covered", "covered_24h", "covered 24h":
-			score -= 5
-		case "covered_less_24h", "covered_late", "covered <24h notice", "covered late":
-			score -= 10
-		case "covered_less_1h", "covered <1h notice":
-			score -= 20
-		}
+	app.logger.Info("Volunteer earned badges", "volunteer_id", volunteerID, "badges", awarded)
+	go app.sendBadgeEmail(volunteer, awarded)
 
-		// Bonus Points from Notes via "Covering for"
-		// Logic matches src/utils/reliability.ts
-		if strings.Contains(s.Notes, "Covering for") {
-			if strings.Contains(s.Notes, ">24h notice") {
-				score += 10
-			} else if strings.Contains(s.Notes, "<24h notice") {
-				score += 20
-			}
+	return nil
+}
+
+// sendBadgeEmail tells the volunteer about their new badges. It goes to the
+// volunteer alone: names aren't public unless they've opted into the
+// leaderboard, and some volunteers are minors.
+func (app *application) sendBadgeEmail(volunteer *data.Volunteer, badges []string) {
+	if volunteer.Email == "" {
+		return
+	}
+
+	var items strings.Builder
+	for _, badge := range badges {
+		fmt.Fprintf(&items, "\n    <li>%s</li>", html.EscapeString(badge))
+	}
+
+	subject := "You earned a new volunteer badge!"
+	if len(badges) > 1 {
+		subject = fmt.Sprintf("You earned %d new volunteer badges!", len(badges))
+	}
+	body := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+  body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+  .container { max-width: 600px; margin: 0 auto; padding: 20px; border: 1px solid #e0e0e0; border-radius: 8px; }
+  h1 { color: #00a5ad; font-size: 22px; }
+</style>
+</head>
+<body>
+<div class="container">
+  <h1>🏅 Congratulations, %s!</h1>
+  <p>Your time with the animals has earned you:</p>
+  <ul>%s
+  </ul>
+  <p>Thank you for everything you do.</p>
+  <p>Warmly,<br>I Dream of Home Rescue Team</p>
+</div>
+</body>
+</html>`, html.EscapeString(volunteer.FirstName), items.String())
+
+	if app.config.smtp.password == "" || app.config.smtp.username == "" {
+		app.logger.Info("Development Mode: Simulating sending email", "recipient", volunteer.Email, "subject", subject)
+		return
+	}
+
+	if err := app.mailer.Send(volunteer.Email, subject, body, nil); err != nil {
+		app.logger.Error("Badge email: failed to send", "volunteer_id", volunteer.ID, "error", err)
+	}
+}
+
+// rolloverVolunteerStats recalculates every volunteer from the daily worker.
+// On the first run of a new year this resets the profile counters while the
+// previous year stays in volunteer_yearly_stats.
+func (app *application) rolloverVolunteerStats() {
+	ids, err := app.models.Volunteers.GetAllIDs()
+	if err != nil {
+		app.logger.Error("Stats rollover: failed to load volunteers", "error", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := app.recalculateVolunteerStats(id); err != nil {
+			app.logger.Error("Stats rollover: failed to recalculate", "volunteer_id", id, "error", err)
 		}
 	}
+}
 
-	// 3. Calculate Streak (Current Year)
-	// Sort descending for streak calc
-	sort.Slice(currentYearShifts, func(i, j int) bool {
-		return currentYearShifts[i].Timestamp.After(currentYearShifts[j].Timestamp)
-	})
+func (app *application) getVolunteerStatsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
 
-	streak := 0
-	for _, s := range currentYearShifts {
-		if s.Status == "completed" || s.Status == "all_good" || s.Status == "late" {
-			streak++
-		} else {
-			break
+	_, err = app.models.Volunteers.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
 		}
+		return
+	}
+
+	stats, err := app.models.VolunteerStats.GetForVolunteer(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
 	}
 
-	// Cap at 100 on the upper end (allow negatives? Frontend doesn't explicitly clamp negatives but display might. We'll leave as is)
-	if score > 100 {
-		score = 100
+	app.JSONResponse(w, http.StatusOK, envelope{"stats": stats, "badgeRules": badgeCatalogue()})
+}
+
+func (app *application) getLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	year := app.readInt(qs, "year", time.Now().Year(), v)
+	limit := app.readInt(qs, "limit", 10, v)
+
+	v.Check(year >= 2000 && year <= time.Now().Year(), "year", "must be a valid year")
+	v.Check(limit > 0 && limit <= 50, "limit", "must be between 1 and 50")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
 	}
-	reliability := int(score)
 
-	return app.models.Volunteers.UpdateStats(volunteerID, reliability, int(totalHours), streak)
+	entries, err := app.models.VolunteerStats.Leaderboard(year, limit)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"year": year, "leaderboard": entries})
+}
+
+func badgeCatalogue() []map[string]string {
+	catalogue := []map[string]string{}
+	for _, rule := range data.BadgeRules {
+		catalogue = append(catalogue, map[string]string{"name": rule.Name, "description": rule.Description})
+	}
+	return catalogue
 }
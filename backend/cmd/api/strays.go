@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// createStrayHandler takes in a found animal: it creates the pet with status
+// "hold" and the stray record that keeps it there until the hold ends.
+func (app *application) createStrayHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name            string   `json:"name"`
+		Species         string   `json:"species"`
+		Sex             string   `json:"sex"`
+		Color           string   `json:"color"`
+		FinderName      string   `json:"finderName"`
+		FinderEmail     string   `json:"finderEmail"`
+		FinderPhone     string   `json:"finderPhone"`
+		FoundDate       string   `json:"foundDate"`
+		FoundLocation   string   `json:"foundLocation"`
+		Latitude        *float64 `json:"latitude"`
+		Longitude       *float64 `json:"longitude"`
+		MicrochipScan   string   `json:"microchipScan"`
+		MicrochipNumber string   `json:"microchipNumber"`
+		IntakeDate      string   `json:"intakeDate"`
+		HoldDays        *int     `json:"holdDays"`
+		Notes           string   `json:"notes"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	today := time.Now().Format(time.DateOnly)
+	stray := &data.StrayIntake{
+		FinderName:      strings.TrimSpace(input.FinderName),
+		FinderEmail:     strings.TrimSpace(input.FinderEmail),
+		FinderPhone:     strings.TrimSpace(input.FinderPhone),
+		FoundDate:       input.FoundDate,
+		FoundLocation:   strings.TrimSpace(input.FoundLocation),
+		Latitude:        input.Latitude,
+		Longitude:       input.Longitude,
+		MicrochipScan:   input.MicrochipScan,
+		MicrochipNumber: strings.TrimSpace(input.MicrochipNumber),
+		IntakeDate:      input.IntakeDate,
+		HoldDays:        data.DefaultStrayHoldDays,
+		Status:          data.StrayHolding,
+		Notes:           input.Notes,
+	}
+	if stray.IntakeDate == "" {
+		stray.IntakeDate = today
+	}
+	if stray.FoundDate == "" {
+		stray.FoundDate = stray.IntakeDate
+	}
+	if stray.MicrochipScan == "" {
+		stray.MicrochipScan = data.MicrochipNotScanned
+	}
+	if input.HoldDays != nil {
+		stray.HoldDays = *input.HoldDays
+	}
+	stray.SetHold()
+
+	if input.Species == "" {
+		input.Species = "cat"
+	}
+	input.Sex = strings.ToLower(input.Sex)
+	if input.Sex == "" {
+		input.Sex = "unknown"
+	}
+	if input.Name == "" {
+		input.Name = "Found " + input.Species + " " + stray.FoundDate
+	}
+
+	v := validator.New()
+	v.Check(validator.PermittedValue(input.Species, data.Species...), "species", "must be cat or dog")
+	v.Check(validator.PermittedValue(input.Sex, data.Sexes...), "sex", "must be male, female or unknown")
+	if data.ValidateStrayIntake(v, stray); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// A chip read at intake may already tell us whose animal this is
+	matches := []*data.MicrochipMatch{}
+	if stray.MicrochipNumber != "" && data.ValidMicrochip(stray.MicrochipNumber) {
+		var err error
+		matches, err = app.models.Microchips.Lookup(stray.MicrochipNumber)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	// The pet's descriptions are public once it's listed, so the found
+	// location stays on the stray record only
+	origin := "Stray found " + stray.FoundDate + "."
+
+	physical, _ := json.Marshal(map[string]any{"color": input.Color})
+	descriptions, _ := json.Marshal(map[string]any{"origin": origin})
+	details, _ := json.Marshal(map[string]any{"status": "hold", "intakeDate": stray.IntakeDate})
+
+	pet := &data.Pet{
+		Name:            input.Name,
+		Species:         input.Species,
+		Sex:             input.Sex,
+		Physical:        physical,
+		Behavior:        json.RawMessage("{}"),
+		Medical:         json.RawMessage("{}"),
+		Descriptions:    descriptions,
+		Details:         details,
+		Adoption:        json.RawMessage("{}"),
+		Foster:          json.RawMessage("{}"),
+		Returned:        json.RawMessage("{}"),
+		Sponsored:       json.RawMessage("{}"),
+		Photos:          json.RawMessage("[]"),
+		ProfileSettings: json.RawMessage("{}"),
+	}
+
+	if err := app.models.Strays.InsertWithPet(stray, pet); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.audit(r, data.AuditCreate, "pet", pet.ID, nil, pet)
+	app.audit(r, data.AuditCreate, "stray_intake", stray.ID, nil, stray)
+
+	w.Header().Set("Location", fmt.Sprintf("/v1/strays/%d", stray.ID))
+	app.JSONResponse(w, http.StatusCreated, envelope{"stray": stray, "pet": pet, "microchipMatches": matches, "warnings": app.isolationWarnings(1)})
+}
+
+func (app *application) listStraysHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	filter := data.StrayFilter{
+		Search: app.readString(qs, "search", ""),
+		Status: app.readString(qs, "status", ""),
+	}
+
+	filters := data.Filters{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "page_size", 20, v),
+		Sort:         app.readString(qs, "sort", "-found_date"),
+		SortSafelist: []string{"found_date", "hold_until", "created_at", "-found_date", "-hold_until", "-created_at"},
+	}
+
+	if filter.Status != "" {
+		v.Check(validator.PermittedValue(filter.Status, data.StrayStatuses...), "status", "invalid status")
+	}
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	strays, metadata, err := app.models.Strays.GetAll(filter, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"strays": strays, "metadata": metadata})
+}
+
+// getStray loads the stray named by the {id} path parameter, writing the
+// error response itself when it returns nil.
+func (app *application) getStray(w http.ResponseWriter, r *http.Request) *data.StrayIntake {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil
+	}
+
+	stray, err := app.models.Strays.Get(id)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil
+	}
+
+	return stray
+}
+
+func (app *application) getStrayHandler(w http.ResponseWriter, r *http.Request) {
+	stray := app.getStray(w, r)
+	if stray == nil {
+		return
+	}
+
+	app.setETag(w, stray.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"stray": stray})
+}
+
+// updateStrayHandler edits a stray record. Reclaiming needs the owner's
+// name; releasing is only allowed once the hold has run out.
+func (app *application) updateStrayHandler(w http.ResponseWriter, r *http.Request) {
+	stray := app.getStray(w, r)
+	if stray == nil {
+		return
+	}
+
+	if app.preconditionFailed(w, r, stray.Version) {
+		return
+	}
+
+	before := *stray
+
+	var input struct {
+		FinderName      *string  `json:"finderName"`
+		FinderEmail     *string  `json:"finderEmail"`
+		FinderPhone     *string  `json:"finderPhone"`
+		FoundDate       *string  `json:"foundDate"`
+		FoundLocation   *string  `json:"foundLocation"`
+		Latitude        *float64 `json:"latitude"`
+		Longitude       *float64 `json:"longitude"`
+		MicrochipScan   *string  `json:"microchipScan"`
+		MicrochipNumber *string  `json:"microchipNumber"`
+		IntakeDate      *string  `json:"intakeDate"`
+		HoldDays        *int     `json:"holdDays"`
+		Status          *string  `json:"status"`
+		OwnerName       *string  `json:"ownerName"`
+		OwnerContact    *string  `json:"ownerContact"`
+		Notes           *string  `json:"notes"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.FinderName != nil {
+		stray.FinderName = strings.TrimSpace(*input.FinderName)
+	}
+	if input.FinderEmail != nil {
+		stray.FinderEmail = strings.TrimSpace(*input.FinderEmail)
+	}
+	if input.FinderPhone != nil {
+		stray.FinderPhone = strings.TrimSpace(*input.FinderPhone)
+	}
+	if input.FoundDate != nil {
+		stray.FoundDate = *input.FoundDate
+	}
+	if input.FoundLocation != nil {
+		stray.FoundLocation = strings.TrimSpace(*input.FoundLocation)
+	}
+	if input.Latitude != nil {
+		stray.Latitude = input.Latitude
+	}
+	if input.Longitude != nil {
+		stray.Longitude = input.Longitude
+	}
+	if input.MicrochipScan != nil {
+		stray.MicrochipScan = *input.MicrochipScan
+	}
+	if input.MicrochipNumber != nil {
+		stray.MicrochipNumber = strings.TrimSpace(*input.MicrochipNumber)
+	}
+	if input.IntakeDate != nil {
+		stray.IntakeDate = *input.IntakeDate
+	}
+	if input.HoldDays != nil {
+		stray.HoldDays = *input.HoldDays
+	}
+	if input.Status != nil {
+		stray.Status = *input.Status
+	}
+	if input.OwnerName != nil {
+		stray.OwnerName = strings.TrimSpace(*input.OwnerName)
+	}
+	if input.OwnerContact != nil {
+		stray.OwnerContact = strings.TrimSpace(*input.OwnerContact)
+	}
+	if input.Notes != nil {
+		stray.Notes = *input.Notes
+	}
+	stray.SetHold()
+
+	v := validator.New()
+	if data.ValidateStrayIntake(v, stray); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Strays.Update(stray); err != nil {
+		if errors.Is(err, data.ErrEditConflict) {
+			app.editConflictResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, data.AuditUpdate, "stray_intake", stray.ID, before, stray)
+
+	app.setETag(w, stray.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"stray": stray})
+}
+
+// listFoundPetsHandler is the public "found cats" page for owners looking
+// for a lost pet.
+func (app *application) listFoundPetsHandler(w http.ResponseWriter, r *http.Request) {
+	found, err := app.models.Strays.Found()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"pets": found})
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/services/scoring"
 	"github.com/cconner57/adoption-os/backend/internal/validator"
 )
 
@@ -38,6 +41,8 @@ func (app *application) submitSurrenderApplication(w http.ResponseWriter, r *htt
 		return
 	}
 
+	triage := scoring.Surrender(&input, time.Now())
+
 	// Construct Email Body
 	var body strings.Builder
 	body.WriteString("New Surrender Application Received\n\n")
@@ -46,6 +51,13 @@ func (app *application) submitSurrenderApplication(w http.ResponseWriter, r *htt
 	body.WriteString(fmt.Sprintf("Phone: %s\n", input.PhoneNumber))
 	body.WriteString(fmt.Sprintf("Address: %s, %s, %s %s\n\n", input.StreetAddress, input.City, input.State, input.ZipCode))
 
+	body.WriteString(fmt.Sprintf("Triage: %s priority (urgency %d, risk %d)\n", triage.Level, triage.Urgency, triage.Risk))
+	body.WriteString(fmt.Sprintf("Needed: %s\n", input.WhenToSurrenderAnimal))
+	for _, reason := range triage.Reasons {
+		body.WriteString(fmt.Sprintf("  - %s\n", reason))
+	}
+	body.WriteString("\n")
+
 	body.WriteString("Animal Details:\n")
 	body.WriteString(fmt.Sprintf("Name: %s\n", input.AnimalName))
 	body.WriteString(fmt.Sprintf("Age: %s\n", input.AnimalAge))
@@ -80,10 +92,14 @@ func (app *application) submitSurrenderApplication(w http.ResponseWriter, r *htt
 	err = app.models.Applications.Insert(appRecord)
 	if err != nil {
 		app.logger.Error("Failed to persist surrender application", "error", err)
+	} else if err := app.models.Waitlist.Triage(appRecord.ID, triage); err != nil {
+		app.logger.Error("Failed to add surrender to waitlist", "appId", appRecord.ID, "error", err)
+	} else {
+		go app.sendSurrenderStatusEmail(input.Email, input.FirstName, input.AnimalName, data.WaitlistWaiting, nil, "")
 	}
 
 	sender := app.config.smtp.sender
-	err = app.mailer.Send(sender, "New Surrender Application - "+input.AnimalName, body.String(), nil)
+	err = app.mailer.Send(sender, fmt.Sprintf("New Surrender Application - %s (%s priority)", input.AnimalName, triage.Level), body.String(), nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -93,3 +109,121 @@ func (app *application) submitSurrenderApplication(w http.ResponseWriter, r *htt
 
 	app.JSONResponse(w, http.StatusCreated, map[string]string{"message": "Surrender application submitted successfully"})
 }
+
+// intakeApplicationHandler converts an accepted surrender into a draft pet
+// with status "intake", links the two, and marks the waitlist entry
+// accepted (emailing the surrenderer unless notify is false).
+func (app *application) intakeApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	application, err := app.models.Applications.Get(id)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		Species    string `json:"species"`
+		IntakeDate string `json:"intakeDate"`
+		Notify     *bool  `json:"notify"`
+	}
+
+	// Everything is optional, so an empty body is fine
+	if r.ContentLength != 0 {
+		if err := app.readJSON(w, r, &input); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if input.Species == "" {
+		input.Species = "cat"
+	}
+	if input.IntakeDate == "" {
+		input.IntakeDate = time.Now().Format(time.DateOnly)
+	}
+
+	v := validator.New()
+	v.Check(application.Type == "surrender", "type", "only surrender applications can be taken in")
+	v.Check(validator.PermittedValue(input.Species, data.Species...), "species", "must be cat or dog")
+	_, err = time.Parse(time.DateOnly, input.IntakeDate)
+	v.Check(err == nil, "intakeDate", "must be a YYYY-MM-DD date")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if application.PetID != nil {
+		app.JSONError(w, http.StatusConflict, fmt.Sprintf("This application was already taken in as pet %s", *application.PetID))
+		return
+	}
+
+	// Only a surrender staff have agreed to take in: approved, or scheduled or
+	// accepted on the waitlist
+	entry, err := app.models.Waitlist.Get(application.ID)
+	if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	agreed := application.Status == "approved" ||
+		(entry != nil && (entry.Status == data.WaitlistScheduled || entry.Status == data.WaitlistAccepted))
+	if !agreed {
+		app.failedValidationResponse(w, r, map[string]string{"status": "the surrender must be approved or scheduled on the waitlist before intake"})
+		return
+	}
+
+	var surrender data.SurrenderApplication
+	if err := json.Unmarshal(application.Data, &surrender); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if strings.TrimSpace(surrender.AnimalName) == "" {
+		app.failedValidationResponse(w, r, map[string]string{"animalName": "the application has no animal name"})
+		return
+	}
+
+	includeMedical := data.HasPermission(app.contextGetRole(r), data.PermMedicalWrite)
+	pet, err := surrender.IntakePet(application.ID, input.Species, input.IntakeDate, includeMedical)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.models.Pets.InsertForApplication(pet, application.ID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.JSONError(w, http.StatusConflict, "This application was already taken in")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	application.PetID = &pet.ID
+
+	app.audit(r, data.AuditCreate, "pet", pet.ID, nil, pet)
+	app.audit(r, "intake", "application", application.ID, nil, envelope{"petId": pet.ID})
+
+	if entry != nil && entry.Status != data.WaitlistAccepted {
+		before := *entry
+		entry.Status = data.WaitlistAccepted
+		entry.IntakeDate = &input.IntakeDate
+		if err := app.models.Waitlist.Update(entry); err != nil {
+			app.logger.Error("Failed to accept waitlist entry at intake", "appId", application.ID, "error", err)
+		} else {
+			app.audit(r, data.AuditUpdate, "surrender_waitlist", entry.ApplicationID, before, entry)
+			if input.Notify == nil || *input.Notify {
+				go app.sendSurrenderStatusEmail(surrender.Email, surrender.FirstName, surrender.AnimalName, entry.Status, entry.IntakeDate, "")
+			}
+		}
+	}
+
+	// New arrivals start in isolation, so say so when it's already full
+	app.JSONResponse(w, http.StatusCreated, envelope{"pet": pet, "application": application, "warnings": app.isolationWarnings(1)})
+}
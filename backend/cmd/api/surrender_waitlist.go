@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/services/scoring"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// listWaitlistHandler is the surrender intake queue, highest priority first.
+// Waiting entries are numbered and marked whether they fit in the room we
+// have now.
+func (app *application) listWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	status := app.readString(qs, "status", data.WaitlistWaiting)
+	if status == "all" {
+		status = ""
+	}
+
+	filters := data.Filters{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "page_size", 50, v),
+		Sort:         "priority",
+		SortSafelist: []string{"priority"},
+	}
+
+	if status != "" {
+		v.Check(validator.PermittedValue(status, data.WaitlistStatuses...), "status", "invalid status")
+	}
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	entries, metadata, err := app.models.Waitlist.GetAll(status, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	capacity, err := app.models.Waitlist.Capacity()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if status == data.WaitlistWaiting {
+		offset := (filters.Page - 1) * filters.PageSize
+		for i, e := range entries {
+			e.Position = offset + i + 1
+			e.FitsCapacity = e.Position <= capacity.Available
+		}
+	}
+
+	app.JSONResponse(w, http.StatusOK, envelope{"waitlist": entries, "capacity": capacity, "metadata": metadata})
+}
+
+// updateWaitlistHandler moves a surrender through the queue. A status change
+// emails the surrenderer unless notify is false; message is added to it.
+// Scheduling or accepting an animal warns when isolation is already full.
+func (app *application) updateWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	entry, err := app.models.Waitlist.Get(id)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if app.preconditionFailed(w, r, entry.Version) {
+		return
+	}
+
+	before := *entry
+
+	var input struct {
+		Status     *string `json:"status"`
+		IntakeDate *string `json:"intakeDate"`
+		Notes      *string `json:"notes"`
+		Message    string  `json:"message"`
+		Notify     *bool   `json:"notify"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Status != nil {
+		entry.Status = *input.Status
+	}
+	if input.IntakeDate != nil {
+		if *input.IntakeDate == "" {
+			entry.IntakeDate = nil
+		} else {
+			entry.IntakeDate = input.IntakeDate
+		}
+	}
+	if input.Notes != nil {
+		entry.Notes = *input.Notes
+	}
+
+	v := validator.New()
+	v.Check(len(input.Message) <= 2000, "message", "must be 2000 characters or fewer")
+	if data.ValidateWaitlistEntry(v, entry); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Waitlist.Update(entry); err != nil {
+		if errors.Is(err, data.ErrEditConflict) {
+			app.editConflictResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.audit(r, data.AuditUpdate, "surrender_waitlist", entry.ApplicationID, before, entry)
+
+	changed := entry.Status != before.Status ||
+		(entry.Status == data.WaitlistScheduled && !sameDate(entry.IntakeDate, before.IntakeDate))
+	if changed && (input.Notify == nil || *input.Notify) {
+		firstName, _, _ := strings.Cut(entry.SurrendererName, " ")
+		go app.sendSurrenderStatusEmail(entry.Email, firstName, entry.AnimalName, entry.Status, entry.IntakeDate, input.Message)
+	}
+
+	warnings := []string{}
+	if entry.Status != before.Status && (entry.Status == data.WaitlistScheduled || entry.Status == data.WaitlistAccepted) {
+		warnings = app.isolationWarnings(1)
+	}
+
+	app.setETag(w, entry.Version)
+	app.JSONResponse(w, http.StatusOK, envelope{"entry": entry, "warnings": warnings})
+}
+
+func sameDate(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// triageApplicationHandler re-scores a surrender application, adding it to
+// the waitlist if it predates triage.
+func (app *application) triageApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	application, err := app.models.Applications.Get(id)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if application.Type != "surrender" {
+		app.failedValidationResponse(w, r, map[string]string{"type": "only surrender applications can be triaged"})
+		return
+	}
+
+	var input data.SurrenderApplication
+	if err := json.Unmarshal(application.Data, &input); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Timeframes are relative to when the request came in, not to today
+	triage := scoring.Surrender(&input, application.CreatedAt)
+	if err := app.models.Waitlist.Triage(application.ID, triage); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	entry, err := app.models.Waitlist.Get(application.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.audit(r, "triage", "surrender_waitlist", entry.ApplicationID, nil, entry)
+
+	app.JSONResponse(w, http.StatusOK, envelope{"entry": entry})
+}
+
+// sendSurrenderStatusEmail tells the surrenderer where their request stands.
+// Scores and queue position are deliberately left out: both move as other
+// requests come in.
+func (app *application) sendSurrenderStatusEmail(to, firstName, animalName, status string, intakeDate *string, message string) {
+	if to == "" {
+		app.logger.Warn("Cannot send surrender status email: missing recipient email", "status", status)
+		return
+	}
+
+	name := html.EscapeString(animalName)
+	var subject, text string
+	switch status {
+	case data.WaitlistWaiting:
+		subject = fmt.Sprintf("We received your request for %s", animalName)
+		text = fmt.Sprintf("<p>We've received your request to surrender <strong>%s</strong> and added them to our intake waitlist.</p><p>We take animals in as space opens up in our foster homes, with the most urgent situations first. We'll be in touch as soon as we can make room.</p>", name)
+	case data.WaitlistScheduled:
+		when := "soon"
+		if intakeDate != nil {
+			if d, err := time.Parse(time.DateOnly, *intakeDate); err == nil {
+				when = "on " + d.Format("Monday, Jan 2, 2006")
+			}
+		}
+		subject = fmt.Sprintf("Intake scheduled for %s", animalName)
+		text = fmt.Sprintf("<p>Good news: we have space for <strong>%s</strong>. Intake is scheduled %s.</p><p>Please bring any medical records, food they're used to and a favorite toy or blanket.</p>", name, when)
+	case data.WaitlistAccepted:
+		subject = fmt.Sprintf("%s is in our care", animalName)
+		text = fmt.Sprintf("<p><strong>%s</strong> is now in our care. Thank you for trusting us to find them a loving home.</p>", name)
+	case data.WaitlistDeclined:
+		subject = fmt.Sprintf("An update on your request for %s", animalName)
+		text = fmt.Sprintf("<p>Unfortunately we're unable to take <strong>%s</strong> at this time. Please reach out to your local shelter or humane society, who may be able to help sooner.</p>", name)
+	case data.WaitlistWithdrawn:
+		subject = fmt.Sprintf("Your request for %s has been withdrawn", animalName)
+		text = fmt.Sprintf("<p>We've removed <strong>%s</strong> from our intake waitlist. If anything changes, you're welcome to submit a new request.</p>", name)
+	default:
+		return
+	}
+	if message != "" {
+		text += fmt.Sprintf("<p>%s</p>", strings.ReplaceAll(html.EscapeString(message), "\n", "<br>"))
+	}
+
+	attachments := make(map[string][]byte)
+	if logoBytes := app.getLogoBytes(); logoBytes != nil {
+		attachments["logo.jpg"] = logoBytes
+	}
+
+	body := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+  body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+  .container { max-width: 600px; margin: 0 auto; padding: 20px; border: 1px solid #e0e0e0; border-radius: 8px; }
+  .header { text-align: center; margin-bottom: 30px; }
+  .logo { max-width: 150px; height: auto; margin-bottom: 20px; }
+  .content { font-size: 16px; }
+</style>
+</head>
+<body>
+<div class="container">
+  <div class="header">
+    <img src="cid:logo.jpg" alt="IDOHR Logo" class="logo">
+  </div>
+
+  <div class="content">
+    <p>Dear %s,</p>
+    %s
+    <p>Warmly,<br>I Dream of Home Rescue Team</p>
+  </div>
+</div>
+</body>
+</html>`, html.EscapeString(firstName), text)
+
+	app.logger.Info("Sending surrender status email", "recipient", to, "status", status)
+	if err := app.mailer.Send(to, subject, body, attachments); err != nil {
+		app.logger.Error("Failed to send surrender status email", "error", err)
+	}
+}
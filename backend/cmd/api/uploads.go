@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"path/filepath"
 	"time"
@@ -27,36 +28,13 @@ func (app *application) uploadPetPhotoHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Limit upload size
-	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize)
-	if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
-		app.badRequestResponse(w, r, err)
-		return
-	}
-
-	file, _, err := r.FormFile("photo")
+	file, err := app.readImageUpload(w, r, "photo")
 	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 	defer file.Close()
 
-	// Detect content type
-	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
-	file.Seek(0, 0)
-	contentType := http.DetectContentType(buffer)
-
-	// Validate Content Type
-	if contentType != "image/jpeg" && contentType != "image/png" {
-		app.badRequestResponse(w, r, fmt.Errorf("unsupported content type: %s", contentType))
-		return
-	}
-
 	// Prepare directories
 	// Structure: [ASSETS_DIR]/pets/[id]/photos/
 	petDir := filepath.Join(app.config.assetsDir, "pets", id, "photos")
@@ -79,6 +57,8 @@ func (app *application) uploadPetPhotoHandler(w http.ResponseWriter, r *http.Req
 	thumbUrl := fmt.Sprintf("pets/%s/photos/%s", id, result.ThumbnailPath)
 	largeUrl := fmt.Sprintf("pets/%s/photos/%s", id, result.LargePath)
 
+	app.audit(r, data.AuditCreate, "pet_photo", id, nil, envelope{"url": largeUrl, "thumbnailUrl": thumbUrl})
+
 	response := envelope{
 		"url":          largeUrl,
 		"thumbnailUrl": thumbUrl,
@@ -86,3 +66,35 @@ func (app *application) uploadPetPhotoHandler(w http.ResponseWriter, r *http.Req
 
 	app.writeJSON(w, http.StatusOK, response, nil)
 }
+
+// readImageUpload reads a JPEG or PNG from the multipart form field. Errors
+// are the client's fault and suit a 400.
+func (app *application) readImageUpload(w http.ResponseWriter, r *http.Request, field string) (multipart.File, error) {
+	// Limit upload size
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize)
+	if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
+		return nil, err
+	}
+
+	file, _, err := r.FormFile(field)
+	if err != nil {
+		return nil, err
+	}
+
+	// Detect content type
+	buffer := make([]byte, 512)
+	if _, err := file.Read(buffer); err != nil {
+		file.Close()
+		return nil, err
+	}
+	file.Seek(0, 0)
+	contentType := http.DetectContentType(buffer)
+
+	// Validate Content Type
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		file.Close()
+		return nil, fmt.Errorf("unsupported content type: %s", contentType)
+	}
+
+	return file, nil
+}
@@ -4,11 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/cconner57/adoption-os/backend/internal/data"
 	"github.com/cconner57/adoption-os/backend/internal/password"
-	"github.com/google/uuid"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
 )
 
 func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -40,26 +41,30 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 
 	// Determine Role
 	// Default to VOLUNTEER_1 (Level 20)
-	role := "VOLUNTEER_1"
-
-	// If token is provided, validate it against DB
-	if input.Token != "" {
-		inviteRole, err := app.verifyAndConsumeInvite(input.Token, input.Email)
-		if err != nil {
-			app.JSONError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		role = inviteRole
-	}
+	role := data.RoleVolunteer1
 
 	user := &data.User{
 		Name:         input.Name,
 		Email:        input.Email,
 		PasswordHash: hash,
-		Activated:    true, // Auto-activate for now
+		Activated:    true,
 		Role:         role,
 	}
 
+	// If token is provided, validate it against DB. The invite link was emailed
+	// to this address, so the account is verified already.
+	var invite *data.Invitation
+	if input.Token != "" {
+		invite, err = app.verifyAndConsumeInvite(input.Token, input.Email)
+		if err != nil {
+			app.JSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		now := time.Now()
+		user.Role = invite.Role
+		user.EmailVerifiedAt = &now
+	}
+
 	err = app.models.Users.Insert(user)
 	if err != nil {
 		// Checks for duplicate email could happen here
@@ -67,36 +72,49 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// A foster portal invite opens that foster home's portal
+	if invite != nil && invite.FosterID != nil {
+		if err := app.models.Fosters.LinkUser(*invite.FosterID, user.ID); err != nil {
+			app.logger.Error("Failed to link foster home to new account", "foster_id", *invite.FosterID, "user_id", user.ID, "error", err)
+		}
+	}
+
+	if user.EmailVerifiedAt == nil {
+		if err := app.sendVerificationEmail(user, false); err != nil {
+			app.logger.Error("Failed to send verification email", "user_id", user.ID, "error", err)
+		}
+	}
+
 	app.writeJSON(w, http.StatusCreated, envelope{"user": user}, nil)
 }
 
-func (app *application) verifyAndConsumeInvite(token, email string) (string, error) {
+func (app *application) verifyAndConsumeInvite(token, email string) (*data.Invitation, error) {
 	invite, err := app.models.Invitations.Get(token)
 	if err != nil {
 		if errors.Is(err, data.ErrRecordNotFound) {
-			return "", errors.New("invalid invite token")
+			return nil, errors.New("invalid invite token")
 		}
-		return "", err
+		return nil, err
 	}
 
 	// Check expiry
 	if time.Now().After(invite.ExpiresAt) {
-		return "", errors.New("invite token has expired")
+		return nil, errors.New("invite token has expired")
 	}
 
 	// Verify email matches (security check)
-	if invite.Email != email {
-		return "", errors.New("email does not match invite")
+	if !strings.EqualFold(invite.Email, email) {
+		return nil, errors.New("email does not match invite")
 	}
 
 	// Delete invite after use
 	// We handle the delete error by logging it but not failing the registration
 	err = app.models.Invitations.Delete(token)
 	if err != nil {
-		app.logger.Error("Failed to delete used invite", "token", token, "error", err)
+		app.logger.Error("Failed to delete used invite", "invite_id", invite.ID, "error", err)
 	}
 
-	return invite.Role, nil
+	return invite, nil
 }
 
 func (app *application) validateInviteHandler(w http.ResponseWriter, r *http.Request) {
@@ -125,10 +143,10 @@ func (app *application) validateInviteHandler(w http.ResponseWriter, r *http.Req
 	app.writeJSON(w, http.StatusOK, envelope{"invite": invite}, nil)
 }
 
-func (app *application) inviteUserHandler(w http.ResponseWriter, r *http.Request) {
+func (app *application) loginUserHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Email string `json:"email"`
-		Role  string `json:"role"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
 	}
 
 	err := app.readJSON(w, r, &input)
@@ -137,50 +155,29 @@ func (app *application) inviteUserHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if input.Email == "" || input.Role == "" {
-		app.badRequestResponse(w, r, errors.New("email and role are required"))
-		return
-	}
+	ip := clientIP(r)
 
-	// Generate Token
-	token := uuid.New().String()
-
-	invite := &data.Invitation{
-		Token:     token,
-		Email:     input.Email,
-		Role:      input.Role,
-		ExpiresAt: time.Now().Add(48 * time.Hour),
-	}
-
-	err = app.models.Invitations.Insert(invite)
+	// 1. Throttle before doing any password work
+	failures, err := app.models.LoginAttempts.RecentFailures(input.Email, ip)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	// Send Email (Mock)
-	app.logger.Info("INVITE LINK GENERATED", "email", input.Email, "link", fmt.Sprintf("https://myapp.com/register?token=%s", token))
-
-	app.writeJSON(w, http.StatusCreated, envelope{"invitation": invite}, nil)
-}
-
-func (app *application) loginUserHandler(w http.ResponseWriter, r *http.Request) {
-	var input struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
-
-	err := app.readJSON(w, r, &input)
-	if err != nil {
-		app.badRequestResponse(w, r, err)
+	if wait := failures.RetryAfter(time.Now()); wait > 0 {
+		app.logger.Info("Login throttled", "email", input.Email, "ip", ip, "account_failures", failures.Account, "ip_failures", failures.IP)
+		app.recordLoginAttempt(r, input.Email, nil, false, data.LoginReasonThrottled)
+		app.tooManyLoginAttemptsResponse(w, r, wait)
 		return
 	}
 
-	// 1. Lookup user by email
+	// 2. Lookup user by email
 	user, err := app.models.Users.GetByEmail(input.Email)
 	if err != nil {
 		app.logger.Info("Login failed: User lookup error", "email", input.Email, "error", err.Error())
 		if errors.Is(err, data.ErrRecordNotFound) {
+			// Unknown addresses count too, so probing for accounts gets throttled
+			app.recordLoginAttempt(r, input.Email, nil, false, data.LoginReasonPassword)
 			app.invalidCredentialsResponse(w, r)
 			return
 		}
@@ -190,7 +187,13 @@ func (app *application) loginUserHandler(w http.ResponseWriter, r *http.Request)
 
 	app.logger.Info("Login: User found", "id", user.ID, "activated", user.Activated)
 
-	// 2. Verify password
+	if user.Locked(time.Now()) {
+		app.recordLoginAttempt(r, input.Email, &user.ID, false, data.LoginReasonLocked)
+		app.accountLockedResponse(w, r)
+		return
+	}
+
+	// 3. Verify password
 	match, err := password.CheckPassword(input.Password, user.PasswordHash)
 	if err != nil {
 		app.logger.Error("Login: Password check error", "error", err)
@@ -200,46 +203,72 @@ func (app *application) loginUserHandler(w http.ResponseWriter, r *http.Request)
 
 	if !match {
 		app.logger.Info("Login failed: Password mismatch", "email", input.Email)
+		app.recordLoginAttempt(r, input.Email, &user.ID, false, data.LoginReasonPassword)
+		if failures.Account+1 >= data.AccountLockThreshold {
+			app.lockAccount(user, failures.Account+1)
+		}
 		app.invalidCredentialsResponse(w, r)
 		return
 	}
 
-	app.logger.Info("Login successful", "email", input.Email)
+	if reason := user.SignInBlocked(); reason != "" {
+		app.JSONError(w, http.StatusForbidden, reason)
+		return
+	}
 
-	// 3. Create Session
-	// 24 hour expiry
-	ttl := 24 * time.Hour
-	token, err := app.models.Sessions.Insert(user.ID, ttl, r.RemoteAddr, r.UserAgent())
-	if err != nil {
+	app.logger.Info("Password verified", "email", input.Email)
+
+	// 4. Second factor, if enrolled
+	mfa, err := app.models.MFA.Get(user.ID)
+	if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	// 4. Set Cookie
-	cookieMode := http.SameSiteLaxMode
-	isSecure := false
+	if mfa != nil && mfa.Enabled {
+		challenge, err := app.models.MFA.CreateChallenge(user.ID, mfaChallengeTTL)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		app.writeJSON(w, http.StatusOK, envelope{
+			"message":        "second factor required",
+			"mfaRequired":    true,
+			"challengeToken": challenge,
+		}, nil)
+		return
+	}
+
+	app.startSession(w, r, user, false)
+}
 
-	if app.config.env == "production" {
-		cookieMode = http.SameSiteNoneMode
-		isSecure = true
+// startSession creates the session, sets the cookie and writes the login response.
+func (app *application) startSession(w http.ResponseWriter, r *http.Request, user *data.User, mfaVerified bool) {
+	reason := data.LoginReasonPassword
+	if mfaVerified {
+		reason = data.LoginReasonMFA
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session_token",
-		Value:    token,
-		Path:     "/",
-		Expires:  time.Now().Add(ttl),
-		HttpOnly: true,
-		Secure:   isSecure,
-		SameSite: cookieMode,
-	})
+	token, err := app.createSession(w, r, user, mfaVerified, reason)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeSession(w, r, token, user, mfaVerified)
+}
 
-	// 5. Return 200 OK + Token/User
-	// We return the token so the frontend can use it in Authorization header if cookies fail
-	err = app.writeJSON(w, http.StatusOK, envelope{
-		"message": "authentication successful",
-		"token":   token,
-		"user":    user,
+// writeSession is the response to a completed sign-in.
+func (app *application) writeSession(w http.ResponseWriter, r *http.Request, token string, user *data.User, mfaVerified bool) {
+	// Return 200 OK + Token/User
+	// We return the token so the frontend can use it in Authorization header if cookies fail.
+	// Admins without MFA can only reach enrollment until they finish it.
+	err := app.writeJSON(w, http.StatusOK, envelope{
+		"message":               "authentication successful",
+		"token":                 token,
+		"user":                  user,
+		"mfaEnrollmentRequired": !mfaVerified && data.IsAdminRole(user.Role),
 	}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -255,6 +284,10 @@ func (app *application) profileUserHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	role, _ := data.GetRole(user.Role)
+	user.Permissions = role.Permissions
+
+	app.setETag(w, user.Version)
 	app.JSONResponse(w, http.StatusOK, user)
 }
 
@@ -267,6 +300,10 @@ func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if app.preconditionFailed(w, r, user.Version) {
+		return
+	}
+
 	var input struct {
 		Name     *string `json:"name"`
 		Email    *string `json:"email"`
@@ -283,52 +320,111 @@ func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request
 		user.Name = *input.Name
 	}
 
-	if input.Email != nil {
-		user.Email = *input.Email
+	// A new address has to be verified before anything trusts it
+	emailChanged := false
+	if input.Email != nil && !strings.EqualFold(strings.TrimSpace(*input.Email), user.Email) {
+		email := strings.TrimSpace(*input.Email)
+		v := validator.New()
+		v.Check(validator.Matches(email, validator.EmailRX), "email", "must be a valid email address")
+		if !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+		user.ChangeEmail(email)
+		emailChanged = true
 	}
 
+	passwordChanged := false
 	if input.Password != nil && *input.Password != "" {
+		if len(*input.Password) < minPasswordLength {
+			v := validator.New()
+			v.AddError("password", fmt.Sprintf("must be at least %d characters", minPasswordLength))
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
 		hash, err := password.HashPassword(*input.Password)
 		if err != nil {
 			app.serverErrorResponse(w, r, err)
 			return
 		}
 		user.PasswordHash = hash
+		passwordChanged = true
 	}
 
 	err = app.models.Users.Update(user)
 	if err != nil {
-		if errors.Is(err, data.ErrEditConflict) {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
 			app.editConflictResponse(w, r)
-		} else {
+		case errors.Is(err, data.ErrDuplicateEmail):
+			v := validator.New()
+			v.AddError("email", "a user with this email address already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
 			app.serverErrorResponse(w, r, err)
 		}
 		return
 	}
 
+	if emailChanged {
+		// Links sent to the old address mustn't verify the new one
+		if err := app.models.Tokens.DeleteAllForUser(data.ScopeVerification, user.ID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if err := app.sendVerificationEmail(user, true); err != nil {
+			app.logger.Error("Failed to send verification email", "user_id", user.ID, "error", err)
+		}
+	}
+
+	// Sign out every other device; this one stays logged in
+	if passwordChanged {
+		current := ""
+		if session := app.contextGetSession(r); session != nil {
+			current = session.Token
+		}
+		if err := app.models.Sessions.DeleteAllForUser(user.ID, current); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if err := app.models.Tokens.DeleteAllForUser(data.ScopePasswordReset, user.ID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	app.setETag(w, user.Version)
 	app.writeJSON(w, http.StatusOK, envelope{"user": user}, nil)
 }
 
 func (app *application) logoutUserHandler(w http.ResponseWriter, r *http.Request) {
-	// Clear the session cookie
-	cookieMode := http.SameSiteLaxMode
-	isSecure := false
-
-	if app.config.env == "production" {
-		cookieMode = http.SameSiteNoneMode
-		isSecure = true
+	// End the session server-side, not just in this browser
+	if token := app.readSessionToken(r); token != "" {
+		if err := app.models.Sessions.DeleteByToken(token); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session_token",
-		Value:    "",
-		Path:     "/",
-		Expires:  time.Unix(0, 0),
-		MaxAge:   -1,
-		HttpOnly: true,
-		Secure:   isSecure,
-		SameSite: cookieMode,
-	})
+	// Clear the session cookie
+	app.clearSessionCookie(w)
 
 	app.writeJSON(w, http.StatusOK, envelope{"message": "logout successful"}, nil)
 }
+
+// createSession records the successful login, inserts the session and sets
+// the cookie. It returns the session token.
+func (app *application) createSession(w http.ResponseWriter, r *http.Request, user *data.User, mfaVerified bool, reason string) (string, error) {
+	app.logger.Info("Login successful", "email", user.Email, "mfa", mfaVerified, "method", reason)
+
+	app.recordLoginAttempt(r, user.Email, &user.ID, true, reason)
+
+	token, err := app.models.Sessions.Insert(user.ID, data.SessionIdleTTL, clientIP(r), r.UserAgent(), mfaVerified)
+	if err != nil {
+		return "", err
+	}
+
+	// The cookie outlives the idle TTL; the server enforces sliding expiry
+	app.setSessionCookie(w, token, time.Now().Add(data.SessionMaxAge))
+	return token, nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/services/pdf"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+const organizationName = "I Dream of Home Rescue"
+
+// readReportRange reads from/to (YYYY-MM-DD), defaulting to the current year to date.
+func (app *application) readReportRange(r *http.Request, v *validator.Validator) (string, string) {
+	qs := r.URL.Query()
+	now := time.Now()
+
+	from := app.readString(qs, "from", fmt.Sprintf("%d-01-01", now.Year()))
+	to := app.readString(qs, "to", now.Format("2006-01-02"))
+
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		v.AddError("from", "must be a valid date (YYYY-MM-DD)")
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		v.AddError("to", "must be a valid date (YYYY-MM-DD)")
+	}
+
+	if v.Valid() {
+		v.Check(!toDate.Before(fromDate), "to", "must not be before from")
+	}
+
+	return from, to
+}
+
+func (app *application) getVolunteerHoursHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	v := validator.New()
+	from, to := app.readReportRange(r, v)
+	format := app.readString(r.URL.Query(), "format", "json")
+	v.Check(data.IsPermittedValue(format, "json", "pdf"), "format", "must be json or pdf")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	volunteer, err := app.models.Volunteers.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	shifts, err := app.models.Shifts.GetForVolunteer(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	worked, totalHours := data.WorkedShiftsBetween(shifts, from, to)
+
+	if format == "json" {
+		app.JSONResponse(w, http.StatusOK, envelope{
+			"volunteerId": volunteer.ID,
+			"name":        volunteer.FirstName + " " + volunteer.LastName,
+			"from":        from,
+			"to":          to,
+			"shifts":      worked,
+			"totalHours":  totalHours,
+		})
+		return
+	}
+
+	// The letter is signed by whoever downloads it
+	signer, err := app.models.Users.Get(app.contextGetUser(r))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	letter := app.renderHoursLetter(volunteer, worked, totalHours, from, to, signer.Name)
+
+	filename := fmt.Sprintf("service-hours-%s-%s.pdf", strings.ToLower(volunteer.FirstName), to)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(letter)
+}
+
+// renderHoursLetter builds the service-hour certificate used for school
+// requirements and employer matching programs.
+func (app *application) renderHoursLetter(volunteer *data.Volunteer, shifts []*data.Shift, totalHours float64, from, to, signer string) []byte {
+	doc := pdf.New()
+	const left, right = 60.0, pdf.PageWidth - 60.0
+
+	y := 50.0
+	if logo := app.getLogoBytes(); logo != nil {
+		if height, err := doc.JPEG(logo, left, y, 90); err == nil {
+			y += height + 20
+		} else {
+			app.logger.Warn("Hours letter: could not embed logo", "error", err)
+		}
+	}
+
+	doc.Text(left, y, 18, true, "Certificate of Volunteer Service")
+	y += 30
+	doc.Text(left, y, 11, false, "Issued "+time.Now().Format("January 2, 2006"))
+	y += 30
+
+	name := strings.TrimSpace(volunteer.FirstName + " " + volunteer.LastName)
+	y = doc.Paragraph(left, y, right-left, 12, fmt.Sprintf(
+		"This letter certifies that %s completed %s hours of volunteer service with %s across %d shift(s) between %s and %s.",
+		name, formatHours(totalHours), organizationName, len(shifts), displayDate(from), displayDate(to),
+	))
+	y += 20
+
+	// Shift table
+	cols := []float64{left, left + 110, left + 300, right - 50}
+	headers := []string{"Date", "Role", "Time", "Hours"}
+	for i, h := range headers {
+		doc.Text(cols[i], y, 10, true, h)
+	}
+	y += 6
+	doc.Line(left, y, right, y)
+	y += 14
+
+	for _, s := range shifts {
+		if y > pdf.PageHeight-140 {
+			doc.AddPage()
+			y = 60
+		}
+		doc.Text(cols[0], y, 10, false, displayDate(s.Date))
+		doc.Text(cols[1], y, 10, false, s.Role)
+		doc.Text(cols[2], y, 10, false, s.StartTime+" - "+s.EndTime)
+		doc.Text(cols[3], y, 10, false, formatHours(data.ShiftHours(s)))
+		y += 16
+	}
+
+	doc.Line(left, y-6, right, y-6)
+	doc.Text(cols[2], y+8, 10, true, "Total")
+	doc.Text(cols[3], y+8, 10, true, formatHours(totalHours))
+	y += 70
+
+	if y > pdf.PageHeight-80 {
+		doc.AddPage()
+		y = 100
+	}
+	doc.Line(left, y, left+220, y)
+	doc.Text(left, y+16, 11, false, signer)
+	doc.Text(left, y+30, 10, false, "Volunteer Coordinator, "+organizationName)
+
+	return doc.Bytes()
+}
+
+// exportVolunteerHoursHandler streams every worked shift in the range as CSV
+// for annual grant reporting.
+func (app *application) exportVolunteerHoursHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	from, to := app.readReportRange(r, v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	shifts, err := app.models.Shifts.GetAll(from, to)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	worked, _ := data.WorkedShiftsBetween(shifts, from, to)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"Volunteer ID", "Volunteer", "Date", "Role", "Start", "End", "Hours", "Status"})
+	for _, s := range worked {
+		writer.Write([]string{
+			strconv.FormatInt(s.VolunteerID, 10),
+			s.VolunteerName,
+			s.Date,
+			s.Role,
+			s.StartTime,
+			s.EndTime,
+			formatHours(data.ShiftHours(s)),
+			s.Status,
+		})
+	}
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("volunteer-hours-%s-to-%s.csv", from, to)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+func formatHours(h float64) string {
+	return strconv.FormatFloat(h, 'f', -1, 64)
+}
+
+func displayDate(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.Format("Jan 2, 2006")
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+// validateMinorShift applies the age-derived policy when the shift's volunteer
+// is a minor: allowed roles, weekly hour cap and adult supervision.
+func (app *application) validateMinorShift(v *validator.Validator, shift *data.Shift, sameDay []*data.Shift) error {
+	// Only gate scheduling; recording attendance afterwards must always work
+	if shift.Status != "scheduled" {
+		return nil
+	}
+
+	volunteer, err := app.models.Volunteers.Get(shift.VolunteerID)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			v.AddError("volunteerId", "does not exist")
+			return nil
+		}
+		return err
+	}
+
+	date, _ := time.Parse("2006-01-02", shift.Date)
+	policy := volunteer.PolicyFor(date)
+	if !policy.IsMinor {
+		return nil
+	}
+
+	monday, sunday := data.WeekOf(date)
+	week, err := app.models.Shifts.GetAll(monday, sunday)
+	if err != nil {
+		return err
+	}
+
+	adults, err := app.adultShifts(sameDay, shift.VolunteerID, date)
+	if err != nil {
+		return err
+	}
+
+	for field, message := range data.CheckMinorShift(policy, shift, week, adults) {
+		v.AddError(field, message)
+	}
+
+	return nil
+}
+
+// adultShifts filters shifts down to those worked by adult volunteers.
+func (app *application) adultShifts(shifts []*data.Shift, excludeVolunteerID int64, on time.Time) ([]*data.Shift, error) {
+	isAdult := map[int64]bool{}
+	adults := []*data.Shift{}
+
+	for _, s := range shifts {
+		if s.VolunteerID == excludeVolunteerID {
+			continue
+		}
+
+		adult, seen := isAdult[s.VolunteerID]
+		if !seen {
+			volunteer, err := app.models.Volunteers.Get(s.VolunteerID)
+			if err != nil {
+				if errors.Is(err, data.ErrRecordNotFound) {
+					isAdult[s.VolunteerID] = false
+					continue
+				}
+				return nil, err
+			}
+			adult = !volunteer.PolicyFor(on).IsMinor
+			isAdult[s.VolunteerID] = adult
+		}
+
+		if adult {
+			adults = append(adults, s)
+		}
+	}
+
+	return adults, nil
+}
+
+// strandedMinors checks whether replacing before with after would leave a
+// minor's shift without an adult on site. after is nil when the shift is
+// deleted. It returns a message naming the minors, or "" when nobody loses
+// cover. Past shifts aren't checked, so the record can always be tidied up.
+func (app *application) strandedMinors(before, after *data.Shift) (string, error) {
+	if !data.CountsTowardStaffing(before.Status) || before.Date < time.Now().Format("2006-01-02") {
+		return "", nil
+	}
+	if after != nil && data.CountsTowardStaffing(after.Status) &&
+		after.Date == before.Date && after.StartTime == before.StartTime && after.EndTime == before.EndTime {
+		return "", nil
+	}
+
+	date, err := time.Parse("2006-01-02", before.Date)
+	if err != nil {
+		return "", nil
+	}
+
+	sameDay, err := app.models.Shifts.GetAll(before.Date, before.Date)
+	if err != nil {
+		return "", err
+	}
+
+	current, next := []*data.Shift{before}, []*data.Shift{}
+	if after != nil && after.Date == before.Date {
+		next = append(next, after)
+	}
+	for _, s := range sameDay {
+		if s.ID != before.ID {
+			current = append(current, s)
+			next = append(next, s)
+		}
+	}
+
+	minors := map[int64]bool{}
+	names := map[int64]string{}
+	for _, s := range current {
+		if _, seen := minors[s.VolunteerID]; seen {
+			continue
+		}
+		volunteer, err := app.models.Volunteers.Get(s.VolunteerID)
+		if err != nil {
+			if errors.Is(err, data.ErrRecordNotFound) {
+				minors[s.VolunteerID] = false
+				continue
+			}
+			return "", err
+		}
+		minors[s.VolunteerID] = volunteer.PolicyFor(date).RequiresSupervisor
+		names[s.VolunteerID] = volunteer.FirstName
+	}
+
+	stranded := data.StrandedMinorShifts(current, next, minors)
+	if len(stranded) == 0 {
+		return "", nil
+	}
+
+	who := make([]string, 0, len(stranded))
+	for _, s := range stranded {
+		who = append(who, fmt.Sprintf("%s (%s-%s)", names[s.VolunteerID], s.StartTime, s.EndTime))
+	}
+	return fmt.Sprintf("would leave %s without an adult volunteer on site; schedule another adult first", strings.Join(who, ", ")), nil
+}
+
+// notifyParentOfScheduleChange emails a minor's parent/guardian when one of
+// their shifts is added, changed or cancelled. Adults are skipped.
+func (app *application) notifyParentOfScheduleChange(shift *data.Shift, change string) {
+	volunteer, err := app.models.Volunteers.Get(shift.VolunteerID)
+	if err != nil {
+		app.logger.Error("Parent notification: failed to load volunteer", "volunteer_id", shift.VolunteerID, "error", err)
+		return
+	}
+
+	date, _ := time.Parse("2006-01-02", shift.Date)
+	if !volunteer.PolicyFor(date).IsMinor {
+		return
+	}
+
+	if volunteer.ParentEmail == "" {
+		app.logger.Warn("Parent notification: no parent email on file", "volunteer_id", volunteer.ID)
+		return
+	}
+
+	parentName := volunteer.ParentName
+	if parentName == "" {
+		parentName = "Parent/Guardian"
+	}
+
+	subject := fmt.Sprintf("Schedule update for %s: shift %s", volunteer.FirstName, change)
+	body := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+  body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+  .container { max-width: 600px; margin: 0 auto; padding: 20px; border: 1px solid #e0e0e0; border-radius: 8px; }
+  h1 { color: #00a5ad; font-size: 22px; }
+  .details { background-color: #f9f9f9; padding: 12px 16px; border-radius: 6px; }
+</style>
+</head>
+<body>
+<div class="container">
+  <h1>Volunteer Schedule Update</h1>
+  <p>Dear %s,</p>
+  <p>A shift for %s has been <strong>%s</strong>:</p>
+  <div class="details">
+    <p><strong>Date:</strong> %s<br>
+    <strong>Time:</strong> %s - %s<br>
+    <strong>Role:</strong> %s</p>
+  </div>
+  <p>An adult volunteer is always scheduled alongside volunteers under %d. Please contact us if you have any questions.</p>
+  <p>Warmly,<br>I Dream of Home Rescue Team</p>
+</div>
+</body>
+</html>`,
+		html.EscapeString(parentName), html.EscapeString(volunteer.FirstName), change,
+		displayDate(shift.Date), html.EscapeString(shift.StartTime), html.EscapeString(shift.EndTime), html.EscapeString(shift.Role),
+		data.AdultVolunteerAge,
+	)
+
+	if app.config.smtp.password == "" || app.config.smtp.username == "" {
+		app.logger.Info("Development Mode: Simulating sending email", "recipient", volunteer.ParentEmail, "subject", subject)
+		return
+	}
+
+	if err := app.mailer.Send(volunteer.ParentEmail, subject, body, nil); err != nil {
+		app.logger.Error("Parent notification: failed to send email", "volunteer_id", volunteer.ID, "error", err)
+	}
+}
@@ -88,6 +88,8 @@ func (app *application) createVolunteerHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	app.audit(r, data.AuditCreate, "volunteer", volunteer.ID, nil, volunteer)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/volunteers/%d", volunteer.ID))
 
@@ -156,7 +158,8 @@ func (app *application) getVolunteerHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	err = app.JSONResponse(w, http.StatusOK, map[string]any{"volunteer": volunteer})
+	app.setETag(w, volunteer.Version)
+	err = app.JSONResponse(w, http.StatusOK, map[string]any{"volunteer": volunteer, "agePolicy": volunteer.PolicyFor(time.Now())})
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -180,6 +183,12 @@ func (app *application) updateVolunteerHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if app.preconditionFailed(w, r, volunteer.Version) {
+		return
+	}
+
+	before := *volunteer
+
 	var input struct {
 		ID                    *int64   `json:"id"`        // Ignored
 		CreatedAt             *string  `json:"createdAt"` // Ignored
@@ -210,6 +219,10 @@ func (app *application) updateVolunteerHandler(w http.ResponseWriter, r *http.Re
 		PositionPreferences   []string `json:"positionPreferences"`
 		Availability          []string `json:"availability"`
 		Badges                []string `json:"badges"`
+		ParentName            *string  `json:"parentName"`
+		ParentEmail           *string  `json:"parentEmail"`
+		ParentPhone           *string  `json:"parentPhone"`
+		LeaderboardOptIn      *bool    `json:"leaderboardOptIn"`
 	}
 
 	err = app.readJSON(w, r, &input)
@@ -293,8 +306,23 @@ func (app *application) updateVolunteerHandler(w http.ResponseWriter, r *http.Re
 	if input.Badges != nil {
 		volunteer.Badges = input.Badges
 	}
+	if input.ParentName != nil {
+		volunteer.ParentName = *input.ParentName
+	}
+	if input.ParentEmail != nil {
+		volunteer.ParentEmail = *input.ParentEmail
+	}
+	if input.ParentPhone != nil {
+		volunteer.ParentPhone = *input.ParentPhone
+	}
+	if input.LeaderboardOptIn != nil {
+		volunteer.LeaderboardOptIn = *input.LeaderboardOptIn
+	}
 
 	v := validator.New()
+	if volunteer.ParentEmail != "" {
+		v.Check(validator.Matches(volunteer.ParentEmail, validator.EmailRX), "parentEmail", "must be a valid email address")
+	}
 	if !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -311,6 +339,9 @@ func (app *application) updateVolunteerHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	app.audit(r, data.AuditUpdate, "volunteer", volunteer.ID, &before, volunteer)
+
+	app.setETag(w, volunteer.Version)
 	err = app.JSONResponse(w, http.StatusOK, map[string]any{"volunteer": volunteer})
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
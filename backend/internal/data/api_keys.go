@@ -0,0 +1,181 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// API key scopes. Keys are for devices and scripts, so these are narrower
+// than the staff permissions in permissions.go.
+const (
+	ScopeKennelCardRead = "kennel-card:read"
+	ScopeMetricsWrite   = "metrics:write"
+)
+
+var APIKeyScopes = []string{
+	ScopeKennelCardRead,
+	ScopeMetricsWrite,
+}
+
+// apiKeyPrefix makes leaked keys easy to spot in logs and secret scanners.
+const apiKeyPrefix = "idohr_"
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *string    `json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP *string    `json:"lastUsedIp,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Active reports whether the key can still authenticate.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+type APIKeyModel struct {
+	DB *sql.DB
+}
+
+// Insert generates the key, stores its hash and returns the plaintext. The
+// plaintext is never retrievable again.
+func (m APIKeyModel) Insert(key *APIKey) (string, error) {
+	secret, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+	plaintext := apiKeyPrefix + secret
+	key.Prefix = plaintext[:len(apiKeyPrefix)+6]
+
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := []any{key.Name, key.Prefix, hashToken(plaintext), pq.Array(key.Scopes), key.CreatedBy, key.ExpiresAt}
+	err = m.DB.QueryRowContext(ctx, query, args...).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+
+	return plaintext, nil
+}
+
+const apiKeyColumns = `id, name, prefix, scopes, created_by, created_at, expires_at, last_used_at, last_used_ip, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (*APIKey, error) {
+	var k APIKey
+	err := row.Scan(
+		&k.ID,
+		&k.Name,
+		&k.Prefix,
+		pq.Array(&k.Scopes),
+		&k.CreatedBy,
+		&k.CreatedAt,
+		&k.ExpiresAt,
+		&k.LastUsedAt,
+		&k.LastUsedIP,
+		&k.RevokedAt,
+	)
+	return &k, err
+}
+
+// GetByKey looks a key up by its plaintext. Revoked and expired keys are
+// returned too; callers check Active.
+func (m APIKeyModel) GetByKey(plaintext string) (*APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	key, err := scanAPIKey(m.DB.QueryRowContext(ctx, query, hashToken(plaintext)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (m APIKeyModel) GetAll() ([]*APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY revoked_at IS NOT NULL, created_at DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Revoke is permanent; issue a new key to restore access.
+func (m APIKeyModel) Revoke(id int64) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed records use at most once a minute; kennel cards poll often.
+func (m APIKeyModel) TouchLastUsed(id int64, ip string) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW(), last_used_ip = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, id, ip)
+	return err
+}
@@ -13,10 +13,12 @@ import (
 
 type Application struct {
 	ID           int64           `json:"id"`
-	Type         string          `json:"type"`          // 'volunteer', 'adoption', 'surrender'
-	Status       string          `json:"status"`        // 'pending', 'approved', 'denied', 'needs_info'
-	Data         json.RawMessage `json:"data"`          // Full form data
-	OriginalHTML *string         `json:"original_html"` // Generated email HTML
+	Type         string          `json:"type"`                  // 'volunteer', 'adoption', 'surrender', 'foster'
+	Status       string          `json:"status"`                // 'pending', 'approved', 'denied', 'needs_info'
+	Data         json.RawMessage `json:"data"`                  // Full form data
+	OriginalHTML *string         `json:"original_html"`         // Generated email HTML
+	PetID        *string         `json:"petId"`                 // Pet created from this application, e.g. at surrender intake
+	ReturnFlags  []ReturnFlag    `json:"returnFlags,omitempty"` // Earlier returns by an adoption applicant; not stored
 	CreatedAt    time.Time       `json:"created_at"`
 	UpdatedAt    time.Time       `json:"updated_at"`
 	Version      int32           `json:"version"`
@@ -68,18 +70,6 @@ func (m ApplicationModel) ArchiveOldPending(ctx context.Context) error {
 	}
 	defer rows.Close()
 
-	// Prepare updates
-	type minimalData struct {
-		FirstName     string `json:"firstName,omitempty"`
-		LastName      string `json:"lastName,omitempty"`
-		Email         string `json:"email,omitempty"`
-		ApplicantName string `json:"applicantName,omitempty"` // Sometimes stored as NameFull
-		// Surrender Specific
-		AnimalName           string `json:"animalName,omitempty"`
-		AnimalAge            string `json:"animalAge,omitempty"`
-		AnimalWhySurrendered string `json:"animalWhySurrendered,omitempty"`
-	}
-
 	for rows.Next() {
 		var id int64
 		var appType string
@@ -89,39 +79,11 @@ func (m ApplicationModel) ArchiveOldPending(ctx context.Context) error {
 			continue
 		}
 
-		// Parse full data
-		var fullMap map[string]interface{}
-		if err := json.Unmarshal(rawData, &fullMap); err != nil {
+		newJSON, err := redactApplicationData(appType, rawData, true)
+		if err != nil {
 			continue // corrupted data, maybe just force delete? ignoring for now.
 		}
 
-		// Extract Minimal Data
-		sanitized := minimalData{}
-
-		// Helper to safely get string
-		getString := func(key string) string {
-			if v, ok := fullMap[key].(string); ok {
-				return v
-			}
-			return ""
-		}
-
-		sanitized.FirstName = getString("firstName")
-		sanitized.LastName = getString("lastName")
-		sanitized.Email = getString("email")
-		if sanitized.Email == "" {
-			sanitized.Email = getString("Email") // Case sensitivity check
-		}
-		sanitized.ApplicantName = getString("nameFull") // fallback if First/Last empty?
-
-		if appType == "surrender" {
-			sanitized.AnimalName = getString("animalName")
-			sanitized.AnimalAge = getString("animalAge")
-			sanitized.AnimalWhySurrendered = getString("animalWhySurrendered")
-		}
-
-		newJSON, _ := json.Marshal(sanitized)
-
 		// Update Row
 		updateQuery := `
 			UPDATE applications 
@@ -139,6 +101,60 @@ func (m ApplicationModel) ArchiveOldPending(ctx context.Context) error {
 	return nil
 }
 
+// redactApplicationData reduces an application's form data to the few fields
+// worth keeping. keepContact keeps the applicant's name and email (archiving
+// stale applications); without it nothing identifies the person (erasure).
+func redactApplicationData(appType string, rawData []byte, keepContact bool) ([]byte, error) {
+	type minimalData struct {
+		FirstName     string `json:"firstName,omitempty"`
+		LastName      string `json:"lastName,omitempty"`
+		Email         string `json:"email,omitempty"`
+		ApplicantName string `json:"applicantName,omitempty"` // Sometimes stored as NameFull
+		// Surrender Specific
+		AnimalName           string `json:"animalName,omitempty"`
+		AnimalAge            string `json:"animalAge,omitempty"`
+		AnimalWhySurrendered string `json:"animalWhySurrendered,omitempty"`
+		Redacted             bool   `json:"redacted,omitempty"`
+	}
+
+	// Parse full data
+	var fullMap map[string]interface{}
+	if err := json.Unmarshal(rawData, &fullMap); err != nil {
+		return nil, err
+	}
+
+	// Extract Minimal Data
+	sanitized := minimalData{}
+
+	// Helper to safely get string
+	getString := func(key string) string {
+		if v, ok := fullMap[key].(string); ok {
+			return v
+		}
+		return ""
+	}
+
+	if keepContact {
+		sanitized.FirstName = getString("firstName")
+		sanitized.LastName = getString("lastName")
+		sanitized.Email = getString("email")
+		if sanitized.Email == "" {
+			sanitized.Email = getString("Email") // Case sensitivity check
+		}
+		sanitized.ApplicantName = getString("nameFull") // fallback if First/Last empty?
+	} else {
+		sanitized.Redacted = true
+	}
+
+	if appType == "surrender" {
+		sanitized.AnimalName = getString("animalName")
+		sanitized.AnimalAge = getString("animalAge")
+		sanitized.AnimalWhySurrendered = getString("animalWhySurrendered")
+	}
+
+	return json.Marshal(sanitized)
+}
+
 func (m ApplicationModel) DeleteDeniedApplications(ctx context.Context) error {
 	query := `
 		SELECT id, type, data 
@@ -228,7 +244,7 @@ func (m ApplicationModel) DeleteDeniedApplications(ctx context.Context) error {
 
 func (m ApplicationModel) Get(id int64) (*Application, error) {
 	query := `
-		SELECT id, type, status, data, original_html, created_at, updated_at, version
+		SELECT id, type, status, data, original_html, pet_id, created_at, updated_at, version
 		FROM applications
 		WHERE id = $1`
 
@@ -242,6 +258,7 @@ func (m ApplicationModel) Get(id int64) (*Application, error) {
 		&app.Status,
 		&app.Data,
 		&app.OriginalHTML,
+		&app.PetID,
 		&app.CreatedAt,
 		&app.UpdatedAt,
 		&app.Version,
@@ -295,7 +312,7 @@ func (m ApplicationModel) GetAll(typeFilter string, statusFilter string, yearFil
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, type, status, data, created_at, updated_at, version
+		SELECT id, type, status, data, pet_id, created_at, updated_at, version
 		FROM applications
 		%s
 		ORDER BY %s %s, id ASC
@@ -318,6 +335,7 @@ func (m ApplicationModel) GetAll(typeFilter string, statusFilter string, yearFil
 			&app.Type,
 			&app.Status,
 			&app.Data,
+			&app.PetID,
 			&app.CreatedAt,
 			&app.UpdatedAt,
 			&app.Version,
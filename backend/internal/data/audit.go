@@ -0,0 +1,223 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// auditIgnoredFields change on every write and would only add noise.
+var auditIgnoredFields = map[string]bool{
+	"version":    true,
+	"updatedAt":  true,
+	"updated_at": true,
+}
+
+// auditRedacted is stored in place of values whose key looks like a secret.
+const auditRedacted = "[redacted]"
+
+type AuditEntry struct {
+	ID           int64                  `json:"id"`
+	CreatedAt    time.Time              `json:"createdAt"`
+	ActorID      *string                `json:"actorId,omitempty"`
+	ActorName    *string                `json:"actorName,omitempty"`
+	APIKeyID     *int64                 `json:"apiKeyId,omitempty"`
+	RequestID    string                 `json:"requestId"`
+	Action       string                 `json:"action"`
+	ResourceType string                 `json:"resourceType"`
+	ResourceID   string                 `json:"resourceId"`
+	Changes      map[string]AuditChange `json:"changes"`
+	IP           string                 `json:"ip"`
+	Method       string                 `json:"method"`
+	Path         string                 `json:"path"`
+}
+
+// AuditChange is one field's value before and after a write. Nested objects
+// are flattened to dotted keys, e.g. "details.status".
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditFilter narrows GetAll; zero values match everything.
+type AuditFilter struct {
+	ActorID      string
+	ResourceType string
+	ResourceID   string
+	Action       string
+	RequestID    string
+	Since        *time.Time
+	Until        *time.Time
+}
+
+// AuditDiff compares the JSON forms of before and after and returns the
+// fields that differ. Either side may be nil (create or delete).
+func AuditDiff(before, after any) (map[string]AuditChange, error) {
+	b, err := auditFlatten(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := auditFlatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]AuditChange{}
+	for k, bv := range b {
+		if av, ok := a[k]; !ok || !reflect.DeepEqual(bv, av) {
+			changes[k] = AuditChange{Before: bv, After: a[k]}
+		}
+	}
+	for k, av := range a {
+		if _, ok := b[k]; !ok {
+			changes[k] = AuditChange{Before: nil, After: av}
+		}
+	}
+
+	return changes, nil
+}
+
+func auditFlatten(v any) (map[string]any, error) {
+	out := map[string]any{}
+	if v == nil {
+		return out, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+
+	obj, ok := decoded.(map[string]any)
+	if !ok {
+		out["value"] = decoded
+		return out, nil
+	}
+
+	flattenInto(out, "", obj)
+	return out, nil
+}
+
+func flattenInto(out map[string]any, prefix string, obj map[string]any) {
+	for k, v := range obj {
+		if prefix == "" && auditIgnoredFields[k] {
+			continue
+		}
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		if isSecretField(k) {
+			out[key] = auditRedacted
+			continue
+		}
+
+		// Arrays are compared whole; index paths would make reorders unreadable
+		if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+			flattenInto(out, key, nested)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+func isSecretField(key string) bool {
+	k := strings.ToLower(key)
+	for _, s := range []string{"password", "secret", "token", "apikey", "api_key"} {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+type AuditModel struct {
+	DB *sql.DB
+}
+
+func (m AuditModel) Insert(entry *AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_log (actor_id, api_key_id, request_id, action, resource_type, resource_id, changes, ip, method, path)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`
+
+	args := []any{entry.ActorID, entry.APIKeyID, entry.RequestID, entry.Action, entry.ResourceType,
+		entry.ResourceID, changes, entry.IP, entry.Method, entry.Path}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func (m AuditModel) GetAll(f AuditFilter, filters Filters) ([]*AuditEntry, Metadata, error) {
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), a.id, a.created_at, a.actor_id, u.name, a.api_key_id, a.request_id,
+			a.action, a.resource_type, a.resource_id, a.changes, a.ip, a.method, a.path
+		FROM audit_log a
+		LEFT JOIN users u ON u.id = a.actor_id
+		WHERE ($1 = '' OR a.actor_id = $1)
+		AND ($2 = '' OR a.resource_type = $2)
+		AND ($3 = '' OR a.resource_id = $3)
+		AND ($4 = '' OR a.action = $4)
+		AND ($5 = '' OR a.request_id = $5)
+		AND ($6::timestamptz IS NULL OR a.created_at >= $6)
+		AND ($7::timestamptz IS NULL OR a.created_at < $7)
+		ORDER BY a.%s %s, a.id DESC
+		LIMIT $8 OFFSET $9`, filters.sortColumn(), filters.sortDirection())
+
+	args := []any{f.ActorID, f.ResourceType, f.ResourceID, f.Action, f.RequestID, f.Since, f.Until,
+		filters.limit(), filters.offset()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	entries := []*AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var changes []byte
+		err := rows.Scan(&totalRecords, &e.ID, &e.CreatedAt, &e.ActorID, &e.ActorName, &e.APIKeyID, &e.RequestID,
+			&e.Action, &e.ResourceType, &e.ResourceID, &changes, &e.IP, &e.Method, &e.Path)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, Metadata{}, err
+		}
+		entries = append(entries, &e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return entries, calculateMetadata(totalRecords, filters.Page, filters.PageSize), nil
+}
@@ -0,0 +1,51 @@
+package data
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAuditDiff(t *testing.T) {
+	before := map[string]any{
+		"name":     "Miso",
+		"version":  3,
+		"details":  json.RawMessage(`{"status":"available","intakeDate":"2024-01-02"}`),
+		"photos":   []string{"a.jpg"},
+		"password": "old",
+	}
+	after := map[string]any{
+		"name":     "Miso",
+		"version":  4,
+		"details":  json.RawMessage(`{"status":"adopted","intakeDate":"2024-01-02"}`),
+		"photos":   []string{"a.jpg", "b.jpg"},
+		"password": "new",
+		"slug":     "miso",
+	}
+
+	got, err := AuditDiff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]AuditChange{
+		"details.status": {Before: "available", After: "adopted"},
+		"photos":         {Before: []any{"a.jpg"}, After: []any{"a.jpg", "b.jpg"}},
+		"slug":           {Before: nil, After: "miso"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AuditDiff = %#v\nwant %#v", got, want)
+	}
+}
+
+func TestAuditDiffCreateAndDelete(t *testing.T) {
+	created, _ := AuditDiff(nil, map[string]any{"name": "Miso", "apiKey": "idohr_abc"})
+	if created["name"].After != "Miso" || created["apiKey"].After != auditRedacted {
+		t.Errorf("create diff = %#v", created)
+	}
+
+	deleted, _ := AuditDiff(map[string]any{"name": "Miso"}, nil)
+	if deleted["name"].Before != "Miso" || deleted["name"].After != nil {
+		t.Errorf("delete diff = %#v", deleted)
+	}
+}
@@ -192,11 +192,42 @@ var DogBreeds = []string{
 	"Yorkshire Terrier",
 }
 
+// -------------------------------------------------------------------------
+//  5. VOLUNTEER SHIFTS
+// -------------------------------------------------------------------------
+
+var DayParts = []string{
+	"morning",
+	"afternoon",
+	"evening",
+}
+
+// Statuses where the volunteer is no longer expected on site, so the shift
+// doesn't occupy a slot or count towards minimum staffing.
+var NonStaffingShiftStatuses = []string{
+	"cancelled",
+	"missed",
+	"no_show",
+	"covered",
+	"covered_24h",
+	"covered 24h",
+	"covered_less_24h",
+	"covered_late",
+	"covered <24h notice",
+	"covered late",
+	"covered_less_1h",
+	"covered <1h notice",
+}
+
 // -------------------------------------------------------------------------
 //  6. APPLICATIONS
 // -------------------------------------------------------------------------
 
 var ApplicationStatuses = []string{
+	"pending",
+	"approved",
+	"denied",
+	"needs_info",
 	"submitted",
 	"under_review",
 	"video_requested",
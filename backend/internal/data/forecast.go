@@ -0,0 +1,100 @@
+package data
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PetFlow is when a pet came in and, if it has been adopted, when it left.
+type PetFlow struct {
+	Species string
+	Intake  *time.Time
+	Adopted *time.Time
+}
+
+// Census is the population right now and the room we have for it.
+type Census struct {
+	InCare          int `json:"inCare"`
+	ShelterCapacity int `json:"shelterCapacity"`
+	FosterCapacity  int `json:"fosterCapacity"`
+}
+
+func (c Census) Capacity() int {
+	return c.ShelterCapacity + c.FosterCapacity
+}
+
+// inCareStatuses are the pets the rescue is responsible for today.
+var inCareStatuses = []string{"available", "adoption-pending", "foster", "hold", "intake"}
+
+var recordDateLayouts = []string{time.DateOnly, "1/2/2006", "1/2/06", time.RFC3339}
+
+// ParseRecordDate reads a date out of a pet record. Older records were
+// imported from the spreadsheet as M/D/YYYY; newer ones are ISO dates.
+func ParseRecordDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range recordDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Flow lists the intake and adoption dates of every pet that has either.
+// Dates that can't be read are left nil rather than guessed.
+func (m PetModel) Flow() ([]PetFlow, error) {
+	query := `
+		SELECT COALESCE(species, ''), COALESCE(details->>'intakeDate', ''), COALESCE(adoption->>'date', '')
+		FROM pets
+		WHERE details->>'intakeDate' IS NOT NULL OR adoption->>'date' IS NOT NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flows := []PetFlow{}
+	for rows.Next() {
+		var species, intake, adopted string
+		if err := rows.Scan(&species, &intake, &adopted); err != nil {
+			return nil, err
+		}
+
+		f := PetFlow{Species: species}
+		if t, ok := ParseRecordDate(intake); ok {
+			f.Intake = &t
+		}
+		if t, ok := ParseRecordDate(adopted); ok {
+			f.Adopted = &t
+		}
+		if f.Intake != nil || f.Adopted != nil {
+			flows = append(flows, f)
+		}
+	}
+
+	return flows, rows.Err()
+}
+
+// Census counts the pets in care, of one species if given, and adds up
+// shelter and foster capacity.
+func (m PetModel) Census(species string) (Census, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM pets WHERE LOWER(status) = ANY($2) AND ($1 = '' OR species = $1)),
+			(SELECT COALESCE(SUM(capacity), 0) FROM shelter_locations WHERE active),
+			(SELECT COALESCE(SUM(capacity), 0) FROM foster_homes WHERE status = 'approved')`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var c Census
+	err := m.DB.QueryRowContext(ctx, query, species, pq.Array(inCareStatuses)).Scan(&c.InCare, &c.ShelterCapacity, &c.FosterCapacity)
+	return c, err
+}
@@ -0,0 +1,148 @@
+package data
+
+import (
+	"slices"
+
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+var (
+	FosterHomeTypes      = []string{"house", "apartment", "condo", "townhouse", "mobile_home", "other"}
+	FosterHomeOwnerships = []string{"own", "rent"}
+)
+
+// FosterApplication is the public foster form, stored as an application of
+// type "foster". Contact keys match the other forms so privacy search and
+// archiving find them.
+type FosterApplication struct {
+	FirstName   string `json:"firstName"`
+	LastName    string `json:"lastName"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phoneNumber"`
+	Address     string `json:"address"`
+	City        string `json:"city"`
+	State       string `json:"state"`
+	Zip         string `json:"zip"`
+
+	// Home setup
+	HomeType         string            `json:"homeType"`
+	HomeOwnership    string            `json:"homeOwnership"`
+	LandlordApproval *bool             `json:"landlordApproval"` // renters only
+	LandlordName     string            `json:"landlordName"`
+	LandlordPhone    string            `json:"landlordPhone"`
+	HouseholdMembers []HouseholdMember `json:"householdMembers"`
+	HoursHomeAlone   int               `json:"hoursHomeAlone"` // typical weekday hours the fosters would be alone
+
+	HasIsolationRoom         bool   `json:"hasIsolationRoom"`
+	IsolationRoomDescription string `json:"isolationRoomDescription"`
+
+	// Experience
+	FosteredBefore         bool   `json:"fosteredBefore"`
+	FosterExperience       string `json:"fosterExperience"`
+	BottleBabyExperience   bool   `json:"bottleBabyExperience"`
+	BottleBabyDetails      string `json:"bottleBabyDetails"`
+	OtherPets              string `json:"otherPets"`
+	ResidentPetsVaccinated *bool  `json:"residentPetsVaccinated"` // nil when there are no other pets
+	CanTransportToVet      bool   `json:"canTransportToVet"`
+
+	// What they can take on
+	Capacity           int      `json:"capacity"`
+	SpeciesPreferences []string `json:"speciesPreferences"`
+	AgePreferences     []string `json:"agePreferences"`
+
+	NameFull      string  `json:"nameFull"`
+	SignatureData *string `json:"signatureData"`
+	SignatureDate string  `json:"signatureDate"`
+
+	// Set by the server at submission, never read from the form
+	Score *ApplicationScore `json:"score,omitempty"`
+
+	// Honeypot
+	FaxNumber string `json:"fax_number"`
+}
+
+// ApplicationScore is a screening aid for reviewers, not a decision: Factors
+// explain the points and Flags call out answers worth a follow-up.
+type ApplicationScore struct {
+	Total   int           `json:"total"`
+	Max     int           `json:"max"`
+	Factors []ScoreFactor `json:"factors"`
+	Flags   []string      `json:"flags"`
+}
+
+type ScoreFactor struct {
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+	Max    int    `json:"max"`
+}
+
+func ValidateFosterApplication(v *validator.Validator, a *FosterApplication) {
+	// Contact Info
+	v.Check(a.FirstName != "", "firstName", "must be provided")
+	v.Check(a.LastName != "", "lastName", "must be provided")
+	v.Check(a.Email != "", "email", "must be provided")
+	if a.Email != "" {
+		v.Check(validator.Matches(a.Email, validator.EmailRX), "email", "must be a valid email address")
+	}
+	v.Check(a.PhoneNumber != "", "phoneNumber", "must be provided")
+	v.Check(a.Address != "", "address", "must be provided")
+	v.Check(a.City != "", "city", "must be provided")
+	v.Check(a.Zip != "", "zip", "must be provided")
+
+	// Home setup
+	v.Check(validator.PermittedValue(a.HomeType, FosterHomeTypes...), "homeType", "must be a valid home type")
+	v.Check(validator.PermittedValue(a.HomeOwnership, FosterHomeOwnerships...), "homeOwnership", "must be own or rent")
+	if a.HomeOwnership == "rent" {
+		v.Check(a.LandlordApproval != nil, "landlordApproval", "must be answered by renters")
+		if a.LandlordApproval != nil && *a.LandlordApproval {
+			v.Check(a.LandlordName != "", "landlordName", "must be provided")
+			v.Check(a.LandlordPhone != "", "landlordPhone", "must be provided")
+		}
+	}
+	v.Check(len(a.HouseholdMembers) > 0, "householdMembers", "must have at least one member")
+	for _, member := range a.HouseholdMembers {
+		v.Check(member.Count > 0, "householdMembers", "count must be positive")
+	}
+	v.Check(a.HoursHomeAlone >= 0 && a.HoursHomeAlone <= 24, "hoursHomeAlone", "must be between 0 and 24")
+	if a.HasIsolationRoom {
+		v.Check(a.IsolationRoomDescription != "", "isolationRoomDescription", "must describe the room")
+	}
+
+	// Experience
+	if a.FosteredBefore {
+		v.Check(a.FosterExperience != "", "fosterExperience", "must be provided")
+	}
+	if a.BottleBabyExperience {
+		v.Check(a.BottleBabyDetails != "", "bottleBabyDetails", "must be provided")
+	}
+	if a.OtherPets != "" {
+		v.Check(a.ResidentPetsVaccinated != nil, "residentPetsVaccinated", "must be answered when there are other pets")
+	}
+
+	// Preferences
+	v.Check(a.Capacity >= 1, "capacity", "must be at least 1")
+	v.Check(a.Capacity <= 10, "capacity", "must be 10 or fewer")
+	for _, age := range a.AgePreferences {
+		v.Check(slices.Contains(FosterAgeGroups, age), "agePreferences", "must be one of bottle_baby, kitten, adult, senior")
+	}
+
+	v.Check(a.NameFull != "", "nameFull", "must be provided")
+	v.Check(a.SignatureData != nil && *a.SignatureData != "", "signatureData", "must be provided")
+}
+
+// FosterHome maps an approved application to a new foster home record.
+func (a *FosterApplication) FosterHome() *FosterHome {
+	return &FosterHome{
+		Name:               a.FirstName + " " + a.LastName,
+		Email:              a.Email,
+		Phone:              a.PhoneNumber,
+		Address:            a.Address,
+		City:               a.City,
+		Zip:                a.Zip,
+		Status:             FosterStatusApproved,
+		Capacity:           a.Capacity,
+		SpeciesPreferences: a.SpeciesPreferences,
+		AgePreferences:     a.AgePreferences,
+		OtherPets:          a.OtherPets,
+	}
+}
@@ -0,0 +1,216 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	FosterUpdateWeight = "weight"
+	FosterUpdateNote   = "note"
+	FosterUpdatePhoto  = "photo"
+)
+
+const (
+	FosterUpdatePending  = "pending"
+	FosterUpdateApproved = "approved"
+	FosterUpdateRejected = "rejected"
+)
+
+var FosterUpdateStatuses = []string{FosterUpdatePending, FosterUpdateApproved, FosterUpdateRejected}
+
+var ErrUpdateReviewed = errors.New("update has already been reviewed")
+
+// FosterUpdate is something a foster submitted through the portal. Approved
+// updates are applied to the pet: weights set physical.currentWeight, notes
+// are added to descriptions.additionalInformation and photos to photos.
+type FosterUpdate struct {
+	ID           int64      `json:"id"`
+	FosterID     int64      `json:"fosterId"`
+	FosterName   string     `json:"fosterName"`
+	PetID        string     `json:"petId"`
+	PetName      string     `json:"petName"`
+	Kind         string     `json:"kind"`
+	WeightLbs    *float64   `json:"weightLbs,omitempty"`
+	Note         string     `json:"note"`
+	PhotoURL     *string    `json:"photoUrl,omitempty"`
+	ThumbnailURL *string    `json:"thumbnailUrl,omitempty"`
+	Status       string     `json:"status"`
+	SubmittedBy  *string    `json:"submittedBy,omitempty"`
+	ReviewedBy   *string    `json:"reviewedBy,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+	ReviewNote   string     `json:"reviewNote"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// FosterUpdateFilter narrows GetAll; zero values match everything.
+type FosterUpdateFilter struct {
+	Status   string
+	FosterID int64
+	PetID    string
+	Kind     string
+}
+
+type FosterUpdateModel struct {
+	DB *sql.DB
+}
+
+const fosterUpdateColumns = `u.id, u.foster_id, COALESCE(f.name, ''), u.pet_id, COALESCE(p.name, ''), u.kind, u.weight_lbs, u.note,
+	u.photo_url, u.thumbnail_url, u.status, u.submitted_by, u.reviewed_by, u.reviewed_at, u.review_note, u.created_at`
+
+const fosterUpdateJoins = `
+	FROM foster_updates u
+	LEFT JOIN foster_homes f ON f.id = u.foster_id
+	LEFT JOIN pets p ON p.id::text = u.pet_id`
+
+func scanFosterUpdate(row interface{ Scan(...any) error }, extra ...any) (*FosterUpdate, error) {
+	var u FosterUpdate
+	dest := append(extra, &u.ID, &u.FosterID, &u.FosterName, &u.PetID, &u.PetName, &u.Kind, &u.WeightLbs, &u.Note,
+		&u.PhotoURL, &u.ThumbnailURL, &u.Status, &u.SubmittedBy, &u.ReviewedBy, &u.ReviewedAt, &u.ReviewNote, &u.CreatedAt)
+
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (m FosterUpdateModel) Insert(u *FosterUpdate) error {
+	query := `
+		INSERT INTO foster_updates (foster_id, pet_id, kind, weight_lbs, note, photo_url, thumbnail_url, submitted_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at`
+
+	args := []any{u.FosterID, u.PetID, u.Kind, u.WeightLbs, u.Note, u.PhotoURL, u.ThumbnailURL, u.SubmittedBy}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&u.ID, &u.Status, &u.CreatedAt)
+}
+
+func (m FosterUpdateModel) Get(id int64) (*FosterUpdate, error) {
+	query := `SELECT ` + fosterUpdateColumns + fosterUpdateJoins + ` WHERE u.id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanFosterUpdate(m.DB.QueryRowContext(ctx, query, id))
+}
+
+// GetAll lists submissions; the review queue is Status "pending" sorted
+// oldest first.
+func (m FosterUpdateModel) GetAll(f FosterUpdateFilter, filters Filters) ([]*FosterUpdate, Metadata, error) {
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), `+fosterUpdateColumns+fosterUpdateJoins+`
+		WHERE ($1 = '' OR u.status = $1)
+		AND ($2 = 0 OR u.foster_id = $2)
+		AND ($3 = '' OR u.pet_id = $3)
+		AND ($4 = '' OR u.kind = $4)
+		ORDER BY u.%s %s, u.id
+		LIMIT $5 OFFSET $6`, filters.sortColumn(), filters.sortDirection())
+
+	args := []any{f.Status, f.FosterID, f.PetID, f.Kind, filters.limit(), filters.offset()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	updates := []*FosterUpdate{}
+	for rows.Next() {
+		u, err := scanFosterUpdate(rows, &totalRecords)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		updates = append(updates, u)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return updates, calculateMetadata(totalRecords, filters.Page, filters.PageSize), nil
+}
+
+// Approve applies a pending update to the pet and marks it approved, in one
+// transaction.
+func (m FosterUpdateModel) Approve(id int64, reviewerID, note string) (*FosterUpdate, error) {
+	return m.review(id, reviewerID, note, FosterUpdateApproved)
+}
+
+// Reject marks a pending update rejected; the pet is left untouched.
+func (m FosterUpdateModel) Reject(id int64, reviewerID, note string) (*FosterUpdate, error) {
+	return m.review(id, reviewerID, note, FosterUpdateRejected)
+}
+
+func (m FosterUpdateModel) review(id int64, reviewerID, note, status string) (*FosterUpdate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	u, err := scanFosterUpdate(tx.QueryRowContext(ctx, `SELECT `+fosterUpdateColumns+fosterUpdateJoins+` WHERE u.id = $1 FOR UPDATE OF u`, id))
+	if err != nil {
+		return nil, err
+	}
+	if u.Status != FosterUpdatePending {
+		return nil, ErrUpdateReviewed
+	}
+
+	if status == FosterUpdateApproved {
+		var apply string
+		var args []any
+		switch u.Kind {
+		case FosterUpdateWeight:
+			apply = `physical = COALESCE(physical, '{}'::jsonb) || jsonb_build_object('currentWeight', $2::numeric)`
+			args = []any{u.PetID, u.WeightLbs}
+		case FosterUpdateNote:
+			apply = `descriptions = jsonb_set(COALESCE(descriptions, '{}'::jsonb), '{additionalInformation}',
+				COALESCE(descriptions->'additionalInformation', '[]'::jsonb) || to_jsonb($2::text))`
+			args = []any{u.PetID, u.Note}
+		case FosterUpdatePhoto:
+			apply = `photos = COALESCE(photos, '[]'::jsonb) || jsonb_build_array(jsonb_build_object(
+				'url', $2::text, 'thumbnailUrl', $3::text, 'isPrimary', false, 'uploadedAt', $4::text))`
+			args = []any{u.PetID, u.PhotoURL, u.ThumbnailURL, u.CreatedAt.UTC().Format(time.RFC3339)}
+		default:
+			return nil, fmt.Errorf("unknown foster update kind %q", u.Kind)
+		}
+
+		result, err := tx.ExecContext(ctx, `UPDATE pets SET `+apply+`, updated_at = NOW(), version = version + 1 WHERE id::text = $1`, args...)
+		if err != nil {
+			return nil, err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if n == 0 {
+			return nil, ErrRecordNotFound
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE foster_updates
+		SET status = $2, reviewed_by = $3, reviewed_at = NOW(), review_note = $4
+		WHERE id = $1
+		RETURNING status, reviewed_by, reviewed_at, review_note`, id, status, reviewerID, note).
+		Scan(&u.Status, &u.ReviewedBy, &u.ReviewedAt, &u.ReviewNote)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, tx.Commit()
+}
@@ -0,0 +1,484 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+	"github.com/lib/pq"
+)
+
+const (
+	FosterStatusPending  = "pending"
+	FosterStatusApproved = "approved"
+	FosterStatusOnHold   = "on_hold"
+	FosterStatusInactive = "inactive"
+	FosterStatusRejected = "rejected"
+)
+
+var FosterStatuses = []string{FosterStatusPending, FosterStatusApproved, FosterStatusOnHold, FosterStatusInactive, FosterStatusRejected}
+
+// FosterAgeGroups are the age preferences a home can take on.
+var FosterAgeGroups = []string{"bottle_baby", "kitten", "adult", "senior"}
+
+var (
+	ErrFosterNotApproved = errors.New("foster home is not approved")
+	ErrFosterAtCapacity  = errors.New("foster home is at capacity")
+)
+
+type FosterHome struct {
+	ID                 int64      `json:"id"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+	Name               string     `json:"name"`
+	Email              string     `json:"email"`
+	Phone              string     `json:"phone"`
+	Address            string     `json:"address"`
+	City               string     `json:"city"`
+	Zip                string     `json:"zip"`
+	Status             string     `json:"status"`
+	Capacity           int        `json:"capacity"`
+	SpeciesPreferences []string   `json:"speciesPreferences"`
+	AgePreferences     []string   `json:"agePreferences"`
+	OtherPets          string     `json:"otherPets"`
+	Notes              string     `json:"notes"`
+	ApprovedAt         *time.Time `json:"approvedAt"`
+	CurrentPets        int        `json:"currentPets"` // open placements
+	OpenSpots          int        `json:"openSpots"`
+	UserID             *string    `json:"userId"` // portal login, once the foster has registered
+	Version            int        `json:"version"`
+}
+
+// FosterPlacement is one stay of a pet in a foster home. EndDate is nil
+// while the pet is still there.
+type FosterPlacement struct {
+	ID        int64     `json:"id"`
+	FosterID  int64     `json:"fosterId"`
+	PetID     string    `json:"petId"`
+	PetName   string    `json:"petName"`
+	Species   string    `json:"species"`
+	PetStatus string    `json:"petStatus"`
+	StartDate string    `json:"startDate"`
+	EndDate   *string   `json:"endDate"`
+	EndReason string    `json:"endReason"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// FosterFilter narrows GetAll; zero values match everything.
+type FosterFilter struct {
+	Search   string
+	Status   string
+	Species  string
+	AgeGroup string
+	HasRoom  bool // approved homes with an open spot
+}
+
+func ValidateFosterHome(v *validator.Validator, f *FosterHome) {
+	v.Check(f.Name != "", "name", "must be provided")
+	v.Check(f.Email != "" || f.Phone != "", "email", "an email or phone number is required")
+	if f.Email != "" {
+		v.Check(validator.Matches(f.Email, validator.EmailRX), "email", "must be a valid email address")
+	}
+	v.Check(validator.PermittedValue(f.Status, FosterStatuses...), "status", "invalid status")
+	v.Check(f.Capacity >= 0, "capacity", "must not be negative")
+	v.Check(f.Capacity <= 50, "capacity", "must be 50 or fewer")
+	for _, age := range f.AgePreferences {
+		v.Check(slices.Contains(FosterAgeGroups, age), "agePreferences", "must be one of bottle_baby, kitten, adult, senior")
+	}
+}
+
+func (f *FosterHome) setOpenSpots() {
+	f.OpenSpots = max(f.Capacity-f.CurrentPets, 0)
+}
+
+type FosterModel struct {
+	DB *sql.DB
+}
+
+const fosterColumns = `f.id, f.created_at, f.updated_at, f.name, f.email, f.phone, f.address, f.city, f.zip,
+	f.status, f.capacity, f.species_preferences, f.age_preferences, f.other_pets, f.notes, f.approved_at,
+	(SELECT COUNT(*) FROM foster_placements pl WHERE pl.foster_id = f.id AND pl.end_date IS NULL), f.user_id, f.version`
+
+func scanFoster(row interface{ Scan(...any) error }, extra ...any) (*FosterHome, error) {
+	var f FosterHome
+	dest := append(extra,
+		&f.ID, &f.CreatedAt, &f.UpdatedAt, &f.Name, &f.Email, &f.Phone, &f.Address, &f.City, &f.Zip,
+		&f.Status, &f.Capacity, pq.Array(&f.SpeciesPreferences), pq.Array(&f.AgePreferences), &f.OtherPets, &f.Notes, &f.ApprovedAt,
+		&f.CurrentPets, &f.UserID, &f.Version)
+
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	f.setOpenSpots()
+	return &f, nil
+}
+
+func (m FosterModel) Insert(f *FosterHome) error {
+	query := `
+		INSERT INTO foster_homes (name, email, phone, address, city, zip, status, capacity,
+			species_preferences, age_preferences, other_pets, notes, approved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, CASE WHEN $7 = 'approved' THEN NOW() END)
+		RETURNING id, created_at, updated_at, approved_at, version`
+
+	args := []any{f.Name, f.Email, f.Phone, f.Address, f.City, f.Zip, f.Status, f.Capacity,
+		pq.Array(f.SpeciesPreferences), pq.Array(f.AgePreferences), f.OtherPets, f.Notes}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&f.ID, &f.CreatedAt, &f.UpdatedAt, &f.ApprovedAt, &f.Version)
+	if err != nil {
+		return err
+	}
+	f.setOpenSpots()
+	return nil
+}
+
+func (m FosterModel) Get(id int64) (*FosterHome, error) {
+	query := `SELECT ` + fosterColumns + ` FROM foster_homes f WHERE f.id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanFoster(m.DB.QueryRowContext(ctx, query, id))
+}
+
+// GetAll lists foster homes. With HasRoom only approved homes with an open
+// spot are returned, so coordinators can find room for a new litter.
+func (m FosterModel) GetAll(f FosterFilter, filters Filters) ([]*FosterHome, Metadata, error) {
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), * FROM (
+			SELECT %s
+			FROM foster_homes f
+			WHERE ($1 = '' OR f.name ILIKE '%%' || $1 || '%%' OR f.email ILIKE '%%' || $1 || '%%')
+			AND ($2 = '' OR f.status = $2)
+			AND ($3 = '' OR cardinality(f.species_preferences) = 0 OR $3 = ANY(f.species_preferences))
+			AND ($4 = '' OR cardinality(f.age_preferences) = 0 OR $4 = ANY(f.age_preferences))
+		) homes (id, created_at, updated_at, name, email, phone, address, city, zip, status, capacity,
+			species_preferences, age_preferences, other_pets, notes, approved_at, current_pets, user_id, version)
+		WHERE (NOT $5 OR (status = 'approved' AND current_pets < capacity))
+		ORDER BY %s %s, id DESC
+		LIMIT $6 OFFSET $7`, fosterColumns, filters.sortColumn(), filters.sortDirection())
+
+	args := []any{f.Search, f.Status, f.Species, f.AgeGroup, f.HasRoom, filters.limit(), filters.offset()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	homes := []*FosterHome{}
+	for rows.Next() {
+		home, err := scanFoster(rows, &totalRecords)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		homes = append(homes, home)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return homes, calculateMetadata(totalRecords, filters.Page, filters.PageSize), nil
+}
+
+func (m FosterModel) Update(f *FosterHome) error {
+	query := `
+		UPDATE foster_homes
+		SET name = $1, email = $2, phone = $3, address = $4, city = $5, zip = $6, status = $7, capacity = $8,
+			species_preferences = $9, age_preferences = $10, other_pets = $11, notes = $12,
+			approved_at = CASE WHEN $7 = 'approved' THEN COALESCE(approved_at, NOW()) END,
+			updated_at = NOW(), version = version + 1
+		WHERE id = $13 AND version = $14
+		RETURNING updated_at, approved_at, version`
+
+	args := []any{f.Name, f.Email, f.Phone, f.Address, f.City, f.Zip, f.Status, f.Capacity,
+		pq.Array(f.SpeciesPreferences), pq.Array(f.AgePreferences), f.OtherPets, f.Notes, f.ID, f.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&f.UpdatedAt, &f.ApprovedAt, &f.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+	f.setOpenSpots()
+	return nil
+}
+
+const placementColumns = `pl.id, pl.foster_id, pl.pet_id, COALESCE(p.name, ''), COALESCE(p.species, 'cat'), COALESCE(p.status, ''),
+	TO_CHAR(pl.start_date, 'YYYY-MM-DD'), TO_CHAR(pl.end_date, 'YYYY-MM-DD'), pl.end_reason, pl.notes, pl.created_at`
+
+func scanPlacement(row interface{ Scan(...any) error }) (*FosterPlacement, error) {
+	var pl FosterPlacement
+	err := row.Scan(&pl.ID, &pl.FosterID, &pl.PetID, &pl.PetName, &pl.Species, &pl.PetStatus,
+		&pl.StartDate, &pl.EndDate, &pl.EndReason, &pl.Notes, &pl.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &pl, nil
+}
+
+// Placements lists the pets placed with a foster home, current ones first.
+// Ended placements are only included with history.
+func (m FosterModel) Placements(fosterID int64, history bool) ([]*FosterPlacement, error) {
+	query := `
+		SELECT ` + placementColumns + `
+		FROM foster_placements pl
+		LEFT JOIN pets p ON p.id::text = pl.pet_id
+		WHERE pl.foster_id = $1 AND ($2 OR pl.end_date IS NULL)
+		ORDER BY pl.end_date DESC NULLS FIRST, pl.start_date DESC, pl.id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, fosterID, history)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	placements := []*FosterPlacement{}
+	for rows.Next() {
+		pl, err := scanPlacement(rows)
+		if err != nil {
+			return nil, err
+		}
+		placements = append(placements, pl)
+	}
+
+	return placements, rows.Err()
+}
+
+// PetHistory lists every foster placement for a pet, newest first.
+func (m FosterModel) PetHistory(petID string) ([]*FosterPlacement, error) {
+	query := `
+		SELECT ` + placementColumns + `
+		FROM foster_placements pl
+		LEFT JOIN pets p ON p.id::text = pl.pet_id
+		WHERE pl.pet_id = $1
+		ORDER BY pl.start_date DESC, pl.id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, petID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	placements := []*FosterPlacement{}
+	for rows.Next() {
+		pl, err := scanPlacement(rows)
+		if err != nil {
+			return nil, err
+		}
+		placements = append(placements, pl)
+	}
+
+	return placements, rows.Err()
+}
+
+// Place moves a pet into a foster home. Any open placement elsewhere is
+// closed as a transfer on the same day, and the pet's foster details are
+// updated to match. overCapacity lets a coordinator place anyway in an
+// emergency; unapproved homes are always refused.
+func (m FosterModel) Place(fosterID int64, petID, startDate, notes string, overCapacity bool) (*FosterPlacement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the home so two placements can't both take the last spot
+	var name, email, phone, status string
+	var capacity, current int
+	err = tx.QueryRowContext(ctx, `
+		SELECT name, email, phone, status, capacity,
+			(SELECT COUNT(*) FROM foster_placements WHERE foster_id = $1 AND end_date IS NULL AND pet_id <> $2)
+		FROM foster_homes WHERE id = $1
+		FOR UPDATE`, fosterID, petID).Scan(&name, &email, &phone, &status, &capacity, &current)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if status != FosterStatusApproved {
+		return nil, ErrFosterNotApproved
+	}
+	if current >= capacity && !overCapacity {
+		return nil, ErrFosterAtCapacity
+	}
+
+	contact, err := json.Marshal(map[string]string{"name": name, "email": email, "phone": phone})
+	if err != nil {
+		return nil, err
+	}
+	result, err := tx.ExecContext(ctx, `
+		UPDATE pets
+		SET foster = COALESCE(foster, '{}'::jsonb) || jsonb_build_object(
+				'parentName', $2::text, 'startDate', $3::text, 'endDate', NULL, 'fosterContactInfo', $4::jsonb),
+			updated_at = NOW(), version = version + 1
+		WHERE id::text = $1`, petID, name, startDate, contact)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE foster_placements
+		SET end_date = GREATEST($2::date, start_date), end_reason = 'transferred'
+		WHERE pet_id = $1 AND end_date IS NULL`, petID, startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// A pet going out to foster leaves its room or kennel at the shelter
+	_, err = tx.ExecContext(ctx, `UPDATE pet_locations SET end_at = NOW() WHERE pet_id = $1 AND end_at IS NULL`, petID)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO foster_placements (foster_id, pet_id, start_date, notes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`, fosterID, petID, startDate, notes).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	placement, err := scanPlacement(tx.QueryRowContext(ctx, `
+		SELECT `+placementColumns+`
+		FROM foster_placements pl
+		LEFT JOIN pets p ON p.id::text = pl.pet_id
+		WHERE pl.id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	return placement, tx.Commit()
+}
+
+// EndPlacement closes an open placement and records the end date on the pet.
+func (m FosterModel) EndPlacement(fosterID, placementID int64, endDate, reason string) (*FosterPlacement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var petID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE foster_placements
+		SET end_date = GREATEST($3::date, start_date), end_reason = $4
+		WHERE id = $1 AND foster_id = $2 AND end_date IS NULL
+		RETURNING pet_id`, placementID, fosterID, endDate, reason).Scan(&petID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE pets
+		SET foster = COALESCE(foster, '{}'::jsonb) || jsonb_build_object('endDate', $2::text),
+			updated_at = NOW(), version = version + 1
+		WHERE id::text = $1`, petID, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	placement, err := scanPlacement(tx.QueryRowContext(ctx, `
+		SELECT `+placementColumns+`
+		FROM foster_placements pl
+		LEFT JOIN pets p ON p.id::text = pl.pet_id
+		WHERE pl.id = $1`, placementID))
+	if err != nil {
+		return nil, err
+	}
+
+	return placement, tx.Commit()
+}
+
+// GetForUser returns the foster home a portal login belongs to.
+func (m FosterModel) GetForUser(userID string) (*FosterHome, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanFoster(m.DB.QueryRowContext(ctx, `SELECT `+fosterColumns+` FROM foster_homes f WHERE f.user_id = $1`, userID))
+}
+
+// LinkUser gives the account userID the foster home's portal. It is only
+// called when a foster portal invite is accepted, never by matching emails,
+// so registering with a foster's address doesn't open their portal.
+func (m FosterModel) LinkUser(fosterID int64, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, `
+		UPDATE foster_homes
+		SET user_id = $1, updated_at = NOW()
+		WHERE id = $2 AND user_id IS NULL`, userID, fosterID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrEditConflict
+	}
+	return nil
+}
+
+// HasPet reports whether the pet is currently placed with the foster home.
+func (m FosterModel) HasPet(fosterID int64, petID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM foster_placements
+			WHERE foster_id = $1 AND pet_id = $2 AND end_date IS NULL
+		)`, fosterID, petID).Scan(&exists)
+	return exists, err
+}
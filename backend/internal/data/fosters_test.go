@@ -0,0 +1,45 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+func TestValidateFosterHome(t *testing.T) {
+	valid := FosterHome{Name: "Sam Lee", Email: "sam@example.com", Status: FosterStatusApproved, Capacity: 3, AgePreferences: []string{"kitten"}}
+
+	tests := []struct {
+		name  string
+		edit  func(*FosterHome)
+		field string
+	}{
+		{"valid", func(*FosterHome) {}, ""},
+		{"no contact", func(f *FosterHome) { f.Email = "" }, "email"},
+		{"phone only", func(f *FosterHome) { f.Email = ""; f.Phone = "555-0100" }, ""},
+		{"bad status", func(f *FosterHome) { f.Status = "maybe" }, "status"},
+		{"negative capacity", func(f *FosterHome) { f.Capacity = -1 }, "capacity"},
+		{"unknown age", func(f *FosterHome) { f.AgePreferences = []string{"puppy"} }, "agePreferences"},
+	}
+
+	for _, tt := range tests {
+		f := valid
+		tt.edit(&f)
+		v := validator.New()
+		ValidateFosterHome(v, &f)
+		if tt.field == "" && !v.Valid() {
+			t.Errorf("%s: unexpected errors %v", tt.name, v.Errors)
+		}
+		if tt.field != "" && v.Errors[tt.field] == "" {
+			t.Errorf("%s: expected error on %s, got %v", tt.name, tt.field, v.Errors)
+		}
+	}
+}
+
+func TestFosterOpenSpots(t *testing.T) {
+	f := FosterHome{Capacity: 2, CurrentPets: 3}
+	f.setOpenSpots()
+	if f.OpenSpots != 0 {
+		t.Errorf("OpenSpots = %d; an over-capacity home has no room", f.OpenSpots)
+	}
+}
@@ -4,72 +4,183 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 )
 
 type Invitation struct {
-	Token     string    `json:"token"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	ExpiresAt time.Time `json:"expires_at"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         int64     `json:"id"`
+	Token      string    `json:"-"` // plaintext, only set when minted for the invite email; stored hashed
+	Email      string    `json:"email"`
+	Role       string    `json:"role"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	InvitedBy  *string   `json:"invited_by,omitempty"`
+	FosterID   *int64    `json:"foster_id,omitempty"` // foster home the new account gets linked to
+	SentCount  int       `json:"sent_count"`
+	LastSentAt time.Time `json:"last_sent_at"`
+}
+
+func (i *Invitation) Expired(now time.Time) bool {
+	return now.After(i.ExpiresAt)
 }
 
 type InvitationModel struct {
 	DB *sql.DB
 }
 
+const invitationColumns = `id, email, role, expires_at, created_at, invited_by, foster_id, sent_count, last_sent_at`
+
+func scanInvitation(row interface{ Scan(...any) error }) (*Invitation, error) {
+	var invite Invitation
+	err := row.Scan(
+		&invite.ID,
+		&invite.Email,
+		&invite.Role,
+		&invite.ExpiresAt,
+		&invite.CreatedAt,
+		&invite.InvitedBy,
+		&invite.FosterID,
+		&invite.SentCount,
+		&invite.LastSentAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
 func (m InvitationModel) Insert(invite *Invitation) error {
 	query := `
-		INSERT INTO invitations (token, email, role, expires_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING created_at`
+		INSERT INTO invitations (token_hash, email, role, expires_at, invited_by, foster_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, sent_count, last_sent_at`
 
-	args := []any{invite.Token, invite.Email, invite.Role, invite.ExpiresAt}
+	args := []any{hashToken(invite.Token), invite.Email, invite.Role, invite.ExpiresAt, invite.InvitedBy, invite.FosterID}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	return m.DB.QueryRowContext(ctx, query, args...).Scan(&invite.CreatedAt)
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&invite.ID, &invite.CreatedAt, &invite.SentCount, &invite.LastSentAt)
 }
 
+// Get looks an invitation up by the token from its link.
 func (m InvitationModel) Get(token string) (*Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE token_hash = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanInvitation(m.DB.QueryRowContext(ctx, query, hashToken(token)))
+}
+
+func (m InvitationModel) GetByID(id int64) (*Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanInvitation(m.DB.QueryRowContext(ctx, query, id))
+}
+
+// GetPendingByEmail returns the unexpired invitation for email, if any.
+func (m InvitationModel) GetPendingByEmail(email string) (*Invitation, error) {
 	query := `
-		SELECT token, email, role, expires_at, created_at
+		SELECT ` + invitationColumns + `
 		FROM invitations
-		WHERE token = $1`
+		WHERE LOWER(email) = $1 AND expires_at > NOW()
+		ORDER BY created_at DESC
+		LIMIT 1`
 
-	var invite Invitation
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanInvitation(m.DB.QueryRowContext(ctx, query, strings.ToLower(email)))
+}
+
+// GetAll lists invitations newest first. Expired ones are left out unless
+// includeExpired is set.
+func (m InvitationModel) GetAll(includeExpired bool) ([]*Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM invitations
+		WHERE ($1 OR expires_at > NOW())
+		ORDER BY created_at DESC, id DESC`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, token).Scan(
-		&invite.Token,
-		&invite.Email,
-		&invite.Role,
-		&invite.ExpiresAt,
-		&invite.CreatedAt,
-	)
+	rows, err := m.DB.QueryContext(ctx, query, includeExpired)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	invites := []*Invitation{}
+	for rows.Next() {
+		invite, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, invite)
+	}
+
+	return invites, rows.Err()
+}
+
+// Renew swaps in a fresh token and expiry for a resend, so a link that was
+// forwarded or leaked stops working.
+func (m InvitationModel) Renew(invite *Invitation) error {
+	query := `
+		UPDATE invitations
+		SET token_hash = $1, expires_at = $2, sent_count = sent_count + 1, last_sent_at = NOW()
+		WHERE id = $3
+		RETURNING sent_count, last_sent_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, hashToken(invite.Token), invite.ExpiresAt, invite.ID).Scan(&invite.SentCount, &invite.LastSentAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrRecordNotFound
+			return ErrRecordNotFound
 		}
-		return nil, err
+		return err
 	}
-
-	return &invite, nil
+	return nil
 }
 
 func (m InvitationModel) Delete(token string) error {
 	query := `
 		DELETE FROM invitations
-		WHERE token = $1`
+		WHERE token_hash = $1`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err := m.DB.ExecContext(ctx, query, token)
+	_, err := m.DB.ExecContext(ctx, query, hashToken(token))
 	return err
 }
+
+// DeleteByID revokes an invitation.
+func (m InvitationModel) DeleteByID(id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, `DELETE FROM invitations WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// KennelCard is what the e-ink card on a kennel door shows. Device keys only
+// ever get this, never the pet's full record: no microchip, vet history,
+// adoption or foster contacts.
+type KennelCard struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Species    string    `json:"species"`
+	Sex        string    `json:"sex"`
+	LitterName *string   `json:"litterName,omitempty"`
+	Status     string    `json:"status"`
+	Location   *string   `json:"location"`
+	AgeGroup   *string   `json:"ageGroup"`
+	Breed      *string   `json:"breed"`
+	Color      *string   `json:"color"`
+	Size       *string   `json:"size"`
+	Photo      *string   `json:"photo"`
+	Care       CareNotes `json:"care"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// CareNotes are what volunteers need to know at the kennel.
+type CareNotes struct {
+	EnergyLevel     *string  `json:"energyLevel"`
+	PersonalityTags []string `json:"personalityTags"`
+	GoodWithCats    *bool    `json:"goodWithCats"`
+	GoodWithDogs    *bool    `json:"goodWithDogs"`
+	GoodWithKids    *bool    `json:"goodWithKids"`
+	SpecialNeeds    *string  `json:"specialNeeds"`
+	Medications     []string `json:"medications"`
+	HealthConcerns  []string `json:"healthConcerns"`
+}
+
+// KennelCard picks the kennel card fields out of the pet's record.
+func (p *Pet) KennelCard() KennelCard {
+	var physical struct {
+		AgeGroup *string `json:"ageGroup"`
+		Breed    *string `json:"breed"`
+		Color    *string `json:"color"`
+		Size     *string `json:"size"`
+	}
+	var behavior struct {
+		EnergyLevel     *string  `json:"energyLevel"`
+		PersonalityTags []string `json:"personalityTags"`
+		GoodWithCats    *bool    `json:"isGoodWithCats"`
+		GoodWithDogs    *bool    `json:"isGoodWithDogs"`
+		GoodWithKids    *bool    `json:"isGoodWithKids"`
+		SpecialNeeds    *string  `json:"specialNeeds"`
+	}
+	var medical struct {
+		Medications    []string `json:"currentMedications"`
+		HealthConcerns []string `json:"healthConcerns"`
+	}
+	var details struct {
+		ShelterLocation *string `json:"shelterLocation"`
+	}
+	var photos []struct {
+		URL       string `json:"url"`
+		IsPrimary bool   `json:"isPrimary"`
+	}
+	_ = json.Unmarshal(p.Physical, &physical)
+	_ = json.Unmarshal(p.Behavior, &behavior)
+	_ = json.Unmarshal(p.Medical, &medical)
+	_ = json.Unmarshal(p.Details, &details)
+	_ = json.Unmarshal(p.Photos, &photos)
+
+	card := KennelCard{
+		ID:         p.ID,
+		Name:       p.Name,
+		Species:    p.Species,
+		Sex:        p.Sex,
+		LitterName: p.LitterName,
+		Status:     p.Status(),
+		Location:   details.ShelterLocation,
+		AgeGroup:   physical.AgeGroup,
+		Breed:      physical.Breed,
+		Color:      physical.Color,
+		Size:       physical.Size,
+		Care: CareNotes{
+			EnergyLevel:     behavior.EnergyLevel,
+			PersonalityTags: behavior.PersonalityTags,
+			GoodWithCats:    behavior.GoodWithCats,
+			GoodWithDogs:    behavior.GoodWithDogs,
+			GoodWithKids:    behavior.GoodWithKids,
+			SpecialNeeds:    behavior.SpecialNeeds,
+			Medications:     medical.Medications,
+			HealthConcerns:  medical.HealthConcerns,
+		},
+		UpdatedAt: p.UpdatedAt,
+	}
+
+	// The primary photo, or the first one if none is marked
+	for _, photo := range photos {
+		if photo.IsPrimary || card.Photo == nil {
+			url := photo.URL
+			card.Photo = &url
+		}
+		if photo.IsPrimary {
+			break
+		}
+	}
+
+	return card
+}
@@ -0,0 +1,43 @@
+package data
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestKennelCard(t *testing.T) {
+	pet := &Pet{
+		ID:       "p1",
+		Name:     "Biscuit",
+		Physical: json.RawMessage(`{"ageGroup": "kitten", "color": "orange"}`),
+		Behavior: json.RawMessage(`{"isGoodWithCats": true, "specialNeeds": "shy at first"}`),
+		Medical: json.RawMessage(`{"currentMedications": ["amoxicillin"],
+			"microchip": {"microchipID": "985112345678901", "microchipped": true},
+			"vaccinations": {"rabies": {"dateAdministered": "2026-01-02", "veterinarian": "Dr. Lee"}}}`),
+		Details:  json.RawMessage(`{"status": "available", "shelterLocation": "Cat Room A"}`),
+		Adoption: json.RawMessage(`{"adopterContactInfo": {"email": "someone@example.com"}}`),
+		Photos:   json.RawMessage(`[{"url": "/a.jpg"}, {"url": "/b.jpg", "isPrimary": true}]`),
+	}
+
+	card := pet.KennelCard()
+	if card.Status != "available" || card.Location == nil || *card.Location != "Cat Room A" {
+		t.Errorf("want status and location from details; got %q, %v", card.Status, card.Location)
+	}
+	if card.Photo == nil || *card.Photo != "/b.jpg" {
+		t.Errorf("want the primary photo; got %v", card.Photo)
+	}
+	if len(card.Care.Medications) != 1 || card.Care.GoodWithCats == nil || !*card.Care.GoodWithCats {
+		t.Errorf("want care notes carried over; got %+v", card.Care)
+	}
+
+	out, err := json.Marshal(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{"985112345678901", "Dr. Lee", "someone@example.com"} {
+		if strings.Contains(string(out), leak) {
+			t.Errorf("kennel card leaks %q: %s", leak, out)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+	"github.com/lib/pq"
+)
+
+const (
+	LocationRoom      = "room"
+	LocationKennel    = "kennel"
+	LocationIsolation = "isolation"
+)
+
+var LocationKinds = []string{LocationRoom, LocationKennel, LocationIsolation}
+
+var (
+	ErrLocationFull      = errors.New("location is at capacity")
+	ErrDuplicateLocation = errors.New("duplicate location name")
+)
+
+// isUniqueViolation reports whether err is Postgres refusing a duplicate key.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// occupiedSQL counts the pets in location l now. Pets that have left (an
+// adopted or archived pet whose assignment was never closed) don't count.
+const occupiedSQL = `(
+	SELECT COUNT(*) FROM pet_locations pl
+	JOIN pets p ON p.id::text = pl.pet_id
+	WHERE pl.location_id = l.id AND pl.end_at IS NULL AND COALESCE(p.status, '') NOT IN ('adopted', 'archived'))`
+
+// ShelterLocation is a room or kennel at the shelter. Occupied and
+// Available are computed.
+type ShelterLocation struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Capacity  int       `json:"capacity"`
+	Notes     string    `json:"notes"`
+	Active    bool      `json:"active"`
+	Occupied  int       `json:"occupied"`
+	Available int       `json:"available"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Version   int       `json:"version"`
+}
+
+func (l *ShelterLocation) setAvailable() {
+	l.Available = max(l.Capacity-l.Occupied, 0)
+}
+
+// Warning says when the location is full, or would be over capacity, once
+// adding more pets come in. It is empty while there's room to spare.
+func (l *ShelterLocation) Warning(adding int) string {
+	after := l.Occupied + adding
+	switch {
+	case after > l.Capacity:
+		return fmt.Sprintf("%s would be over capacity (%d of %d)", l.Name, after, l.Capacity)
+	case after == l.Capacity:
+		return fmt.Sprintf("%s is full (%d of %d)", l.Name, after, l.Capacity)
+	}
+	return ""
+}
+
+// PetLocation is one stay of a pet in a location. EndAt is nil while the
+// pet is still there.
+type PetLocation struct {
+	ID           int64      `json:"id"`
+	PetID        string     `json:"petId"`
+	PetName      string     `json:"petName"`
+	LocationID   int64      `json:"locationId"`
+	LocationName string     `json:"locationName"`
+	StartAt      time.Time  `json:"startAt"`
+	EndAt        *time.Time `json:"endAt"`
+	Notes        string     `json:"notes"`
+	MovedBy      *string    `json:"movedBy,omitempty"`
+}
+
+// LocationOccupancy is one row of the occupancy dashboard.
+type LocationOccupancy struct {
+	ShelterLocation
+	Percent int            `json:"percent"`
+	Pets    []*PetLocation `json:"pets"`
+}
+
+func ValidateShelterLocation(v *validator.Validator, l *ShelterLocation) {
+	v.Check(l.Name != "", "name", "must be provided")
+	v.Check(len(l.Name) <= 100, "name", "must be 100 characters or fewer")
+	v.Check(validator.PermittedValue(l.Kind, LocationKinds...), "kind", "must be room, kennel or isolation")
+	v.Check(l.Capacity >= 0, "capacity", "must not be negative")
+	v.Check(l.Capacity <= 200, "capacity", "must be 200 or fewer")
+}
+
+type LocationModel struct {
+	DB *sql.DB
+}
+
+const locationColumns = `l.id, l.name, l.kind, l.capacity, l.notes, l.active, ` + occupiedSQL + `, l.created_at, l.updated_at, l.version`
+
+func scanLocation(row interface{ Scan(...any) error }) (*ShelterLocation, error) {
+	var l ShelterLocation
+	err := row.Scan(&l.ID, &l.Name, &l.Kind, &l.Capacity, &l.Notes, &l.Active, &l.Occupied, &l.CreatedAt, &l.UpdatedAt, &l.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	l.setAvailable()
+	return &l, nil
+}
+
+const petLocationColumns = `pl.id, pl.pet_id, COALESCE(p.name, ''), pl.location_id, l.name, pl.start_at, pl.end_at, pl.notes, pl.moved_by`
+
+const petLocationJoins = `
+	FROM pet_locations pl
+	JOIN shelter_locations l ON l.id = pl.location_id
+	LEFT JOIN pets p ON p.id::text = pl.pet_id`
+
+func scanPetLocation(row interface{ Scan(...any) error }) (*PetLocation, error) {
+	var pl PetLocation
+	err := row.Scan(&pl.ID, &pl.PetID, &pl.PetName, &pl.LocationID, &pl.LocationName, &pl.StartAt, &pl.EndAt, &pl.Notes, &pl.MovedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &pl, nil
+}
+
+func (m LocationModel) Insert(l *ShelterLocation) error {
+	query := `
+		INSERT INTO shelter_locations (name, kind, capacity, notes, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, l.Name, l.Kind, l.Capacity, l.Notes, l.Active).Scan(&l.ID, &l.CreatedAt, &l.UpdatedAt, &l.Version)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateLocation
+		}
+		return err
+	}
+	l.setAvailable()
+	return nil
+}
+
+func (m LocationModel) Get(id int64) (*ShelterLocation, error) {
+	query := `SELECT ` + locationColumns + ` FROM shelter_locations l WHERE l.id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanLocation(m.DB.QueryRowContext(ctx, query, id))
+}
+
+// GetAll lists locations by kind and name; inactive ones only on request.
+func (m LocationModel) GetAll(includeInactive bool) ([]*ShelterLocation, error) {
+	query := `
+		SELECT ` + locationColumns + `
+		FROM shelter_locations l
+		WHERE $1 OR l.active
+		ORDER BY l.kind, LOWER(l.name)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []*ShelterLocation{}
+	for rows.Next() {
+		l, err := scanLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+
+	return locations, rows.Err()
+}
+
+func (m LocationModel) Update(l *ShelterLocation) error {
+	query := `
+		UPDATE shelter_locations
+		SET name = $1, kind = $2, capacity = $3, notes = $4, active = $5, updated_at = NOW(), version = version + 1
+		WHERE id = $6 AND version = $7
+		RETURNING updated_at, version`
+
+	args := []any{l.Name, l.Kind, l.Capacity, l.Notes, l.Active, l.ID, l.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&l.UpdatedAt, &l.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case isUniqueViolation(err):
+			return ErrDuplicateLocation
+		default:
+			return err
+		}
+	}
+	l.setAvailable()
+	return nil
+}
+
+// Occupancy is the dashboard: every active location with who is in it.
+func (m LocationModel) Occupancy() ([]*LocationOccupancy, error) {
+	locations, err := m.GetAll(false)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + petLocationColumns + petLocationJoins + `
+		WHERE pl.end_at IS NULL AND l.active AND COALESCE(p.status, '') NOT IN ('adopted', 'archived')
+		ORDER BY pl.start_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byLocation := map[int64][]*PetLocation{}
+	for rows.Next() {
+		pl, err := scanPetLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		byLocation[pl.LocationID] = append(byLocation[pl.LocationID], pl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	occupancy := make([]*LocationOccupancy, 0, len(locations))
+	for _, l := range locations {
+		o := &LocationOccupancy{ShelterLocation: *l, Pets: byLocation[l.ID]}
+		if o.Pets == nil {
+			o.Pets = []*PetLocation{}
+		}
+		if l.Capacity > 0 {
+			o.Percent = l.Occupied * 100 / l.Capacity
+		}
+		occupancy = append(occupancy, o)
+	}
+	return occupancy, nil
+}
+
+// IsolationWarnings checks whether isolation can take adding more pets,
+// counting all isolation rooms together since a new animal can go in any of
+// them. With no isolation rooms set up there's nothing to check.
+func (m LocationModel) IsolationWarnings(adding int) ([]string, error) {
+	locations, err := m.GetAll(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var isolation []*ShelterLocation
+	for _, l := range locations {
+		if l.Kind == LocationIsolation {
+			isolation = append(isolation, l)
+		}
+	}
+	return isolationWarnings(isolation, adding), nil
+}
+
+func isolationWarnings(rooms []*ShelterLocation, adding int) []string {
+	warnings := []string{}
+	if len(rooms) == 0 {
+		return warnings
+	}
+	if len(rooms) == 1 {
+		if w := rooms[0].Warning(adding); w != "" {
+			warnings = append(warnings, w)
+		}
+		return warnings
+	}
+
+	var total ShelterLocation
+	total.Name = "Isolation"
+	for _, l := range rooms {
+		total.Capacity += l.Capacity
+		total.Occupied += l.Occupied
+	}
+	if w := total.Warning(adding); w != "" {
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+// PetHistory lists every location a pet has been in, newest first.
+func (m LocationModel) PetHistory(petID string) ([]*PetLocation, error) {
+	query := `SELECT ` + petLocationColumns + petLocationJoins + `
+		WHERE pl.pet_id = $1
+		ORDER BY pl.start_at DESC, pl.id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, petID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []*PetLocation{}
+	for rows.Next() {
+		pl, err := scanPetLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, pl)
+	}
+
+	return history, rows.Err()
+}
+
+// Move puts a pet in a location, closing wherever it was before, and sets
+// details.shelterLocation to match. A nil locationID just closes the current
+// stay (the pet has left the shelter). A full location is refused with
+// ErrLocationFull unless overCapacity is set.
+func (m LocationModel) Move(petID string, locationID *int64, notes string, movedBy string, overCapacity bool) (*PetLocation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var name any
+	if locationID != nil {
+		// Lock the location so two moves can't both take the last spot
+		var locName string
+		var active bool
+		var capacity, occupied int
+		err = tx.QueryRowContext(ctx, `
+			SELECT l.name, l.active, l.capacity, (
+				SELECT COUNT(*) FROM pet_locations pl
+				JOIN pets p ON p.id::text = pl.pet_id
+				WHERE pl.location_id = l.id AND pl.end_at IS NULL AND pl.pet_id <> $2
+				AND COALESCE(p.status, '') NOT IN ('adopted', 'archived'))
+			FROM shelter_locations l WHERE l.id = $1
+			FOR UPDATE`, *locationID, petID).Scan(&locName, &active, &capacity, &occupied)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrRecordNotFound
+			}
+			return nil, err
+		}
+		if !active {
+			return nil, ErrRecordNotFound
+		}
+		if occupied >= capacity && !overCapacity {
+			return nil, ErrLocationFull
+		}
+		name = locName
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE pets
+		SET details = COALESCE(details, '{}'::jsonb) || jsonb_build_object('shelterLocation', $2::text),
+			updated_at = NOW(), version = version + 1
+		WHERE id::text = $1`, petID, name)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE pet_locations SET end_at = NOW() WHERE pet_id = $1 AND end_at IS NULL`, petID)
+	if err != nil {
+		return nil, err
+	}
+
+	if locationID == nil {
+		return nil, tx.Commit()
+	}
+
+	var movedByArg any
+	if movedBy != "" {
+		movedByArg = movedBy
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO pet_locations (pet_id, location_id, notes, moved_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`, petID, *locationID, notes, movedByArg).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	pl, err := scanPetLocation(tx.QueryRowContext(ctx, `SELECT `+petLocationColumns+petLocationJoins+` WHERE pl.id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	return pl, tx.Commit()
+}
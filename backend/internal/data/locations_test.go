@@ -0,0 +1,58 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+func TestIsolationWarnings(t *testing.T) {
+	rooms := func(occupied ...int) []*ShelterLocation {
+		var ls []*ShelterLocation
+		for _, o := range occupied {
+			ls = append(ls, &ShelterLocation{Name: "Iso", Kind: LocationIsolation, Capacity: 2, Occupied: o})
+		}
+		return ls
+	}
+
+	tests := []struct {
+		name   string
+		rooms  []*ShelterLocation
+		adding int
+		want   string
+	}{
+		{"no isolation rooms", nil, 1, ""},
+		{"room to spare", rooms(0), 1, ""},
+		{"last spot", rooms(1), 1, "Iso is full (2 of 2)"},
+		{"overfilled", rooms(2), 1, "Iso would be over capacity (3 of 2)"},
+		{"full now", rooms(2), 0, "Iso is full (2 of 2)"},
+		{"space in another room", rooms(2, 0), 1, ""},
+		{"all rooms full", rooms(2, 2), 1, "Isolation would be over capacity (5 of 4)"},
+	}
+
+	for _, tt := range tests {
+		got := isolationWarnings(tt.rooms, tt.adding)
+		switch {
+		case tt.want == "" && len(got) != 0:
+			t.Errorf("%s: got %v; want no warnings", tt.name, got)
+		case tt.want != "" && (len(got) != 1 || got[0] != tt.want):
+			t.Errorf("%s: got %v; want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateShelterLocation(t *testing.T) {
+	v := validator.New()
+	ValidateShelterLocation(v, &ShelterLocation{Name: "Kennel 1", Kind: LocationKennel, Capacity: 1})
+	if !v.Valid() {
+		t.Fatalf("valid location rejected: %v", v.Errors)
+	}
+
+	v = validator.New()
+	ValidateShelterLocation(v, &ShelterLocation{Kind: "closet", Capacity: -1})
+	for _, field := range []string{"name", "kind", "capacity"} {
+		if _, ok := v.Errors[field]; !ok {
+			t.Errorf("expected an error for %s", field)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Login throttling policy. Failures count within LoginAttemptWindow. An
+// account's count resets on a successful login (or an unlock); an IP's doesn't,
+// so one working password can't launder guesses against other accounts.
+const (
+	LoginAttemptWindow = 15 * time.Minute
+	// Failures allowed before each further attempt has to wait.
+	LoginDelayAfter = 3
+	MaxLoginDelay   = time.Minute
+	// Consecutive failures that lock the account and send an unlock email.
+	AccountLockThreshold = 10
+	AccountLockDuration  = 30 * time.Minute
+	// Failures from one IP across all accounts before it's blocked for the window.
+	IPFailureLimit = 50
+
+	ScopeUnlock = "unlock"
+)
+
+const (
+	LoginReasonPassword  = "password"
+	LoginReasonMFA       = "mfa"
+	LoginReasonLocked    = "locked"
+	LoginReasonThrottled = "throttled"
+	LoginReasonUnlocked  = "unlocked"
+	LoginReasonOIDC      = "oidc"
+)
+
+type LoginAttempt struct {
+	ID        int64     `json:"id"`
+	Email     string    `json:"email"`
+	UserID    *string   `json:"userId,omitempty"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// LoginFailures summarises recent failures for an account and an IP.
+type LoginFailures struct {
+	Account     int
+	LastAccount time.Time
+	IP          int
+	LastIP      time.Time
+}
+
+// LoginDelay is the wait required after the given number of consecutive
+// failures: nothing for the first few, then 1s, 2s, 4s... up to MaxLoginDelay.
+func LoginDelay(failures int) time.Duration {
+	if failures < LoginDelayAfter {
+		return 0
+	}
+	shift := failures - LoginDelayAfter
+	if shift > 6 {
+		return MaxLoginDelay
+	}
+	delay := time.Second << shift
+	if delay > MaxLoginDelay {
+		return MaxLoginDelay
+	}
+	return delay
+}
+
+// RetryAfter returns how long the caller must wait before another attempt is
+// considered, or 0 if they may try now.
+func (f LoginFailures) RetryAfter(now time.Time) time.Duration {
+	var wait time.Duration
+
+	if f.IP >= IPFailureLimit {
+		wait = f.LastIP.Add(LoginAttemptWindow).Sub(now)
+	}
+
+	if d := f.LastAccount.Add(LoginDelay(f.Account)).Sub(now); d > wait {
+		wait = d
+	}
+
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// NormalizeLoginEmail makes "Foo@Example.com " and "foo@example.com" count
+// as the same account.
+func NormalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+type LoginAttemptModel struct {
+	DB *sql.DB
+}
+
+func (m LoginAttemptModel) Insert(a *LoginAttempt) error {
+	query := `
+		INSERT INTO login_attempts (email, user_id, ip, user_agent, success, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := []any{NormalizeLoginEmail(a.Email), a.UserID, a.IP, a.UserAgent, a.Success, a.Reason}
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&a.ID, &a.CreatedAt)
+}
+
+// RecentFailures counts password/MFA failures within the attempt window: for
+// the email since its last success, and for the IP across the whole window.
+func (m LoginAttemptModel) RecentFailures(email, ip string) (LoginFailures, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	since := time.Now().Add(-LoginAttemptWindow)
+	var f LoginFailures
+
+	count := func(query, value string) (int, time.Time, error) {
+		var n int
+		var last sql.NullTime
+		err := m.DB.QueryRowContext(ctx, query, value, since, LoginReasonPassword, LoginReasonMFA).Scan(&n, &last)
+		return n, last.Time, err
+	}
+
+	accountQuery := `
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_attempts
+		WHERE email = $1 AND success = false AND reason IN ($3, $4)
+		AND created_at > GREATEST($2, COALESCE(
+			(SELECT MAX(created_at) FROM login_attempts WHERE email = $1 AND success = true), $2))`
+
+	ipQuery := `
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_attempts
+		WHERE ip = $1 AND success = false AND reason IN ($3, $4) AND created_at > $2`
+
+	var err error
+	f.Account, f.LastAccount, err = count(accountQuery, NormalizeLoginEmail(email))
+	if err != nil {
+		return f, err
+	}
+	f.IP, f.LastIP, err = count(ipQuery, ip)
+	return f, err
+}
+
+// GetAll returns attempts for the admin audit view, newest first.
+func (m LoginAttemptModel) GetAll(email, ip string, failedOnly bool, filters Filters) ([]*LoginAttempt, Metadata, error) {
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), id, email, user_id, ip, user_agent, success, reason, created_at
+		FROM login_attempts
+		WHERE ($1 = '' OR email = $1)
+		AND ($2 = '' OR ip = $2)
+		AND (NOT $3 OR success = false)
+		ORDER BY %s %s, id DESC
+		LIMIT $4 OFFSET $5`, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, NormalizeLoginEmail(email), ip, failedOnly, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	attempts := []*LoginAttempt{}
+	for rows.Next() {
+		var a LoginAttempt
+		err := rows.Scan(&totalRecords, &a.ID, &a.Email, &a.UserID, &a.IP, &a.UserAgent, &a.Success, &a.Reason, &a.CreatedAt)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		attempts = append(attempts, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return attempts, calculateMetadata(totalRecords, filters.Page, filters.PageSize), nil
+}
+
+// DeleteOlderThan keeps the audit trail to a bounded size.
+func (m LoginAttemptModel) DeleteOlderThan(ctx context.Context, age time.Duration) error {
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM login_attempts WHERE created_at < $1`, time.Now().Add(-age))
+	return err
+}
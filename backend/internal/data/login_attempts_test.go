@@ -0,0 +1,44 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{LoginDelayAfter - 1, 0},
+		{LoginDelayAfter, time.Second},
+		{LoginDelayAfter + 2, 4 * time.Second},
+		{LoginDelayAfter + 30, MaxLoginDelay},
+	}
+
+	for _, tt := range tests {
+		if got := LoginDelay(tt.failures); got != tt.want {
+			t.Errorf("LoginDelay(%d) = %v; want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLoginFailuresRetryAfter(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	f := LoginFailures{Account: LoginDelayAfter + 2, LastAccount: now.Add(-time.Second)}
+	if got := f.RetryAfter(now); got != 3*time.Second {
+		t.Errorf("want 3s left of a 4s delay; got %v", got)
+	}
+
+	f = LoginFailures{Account: LoginDelayAfter + 2, LastAccount: now.Add(-time.Minute)}
+	if got := f.RetryAfter(now); got != 0 {
+		t.Errorf("want no wait once the delay has passed; got %v", got)
+	}
+
+	f = LoginFailures{IP: IPFailureLimit, LastIP: now.Add(-5 * time.Minute)}
+	if got := f.RetryAfter(now); got != LoginAttemptWindow-5*time.Minute {
+		t.Errorf("want IP blocked for the rest of the window; got %v", got)
+	}
+}
@@ -36,6 +36,7 @@ type Campaign struct {
 	Participants json.RawMessage `json:"participants,omitempty"`
 	CreatedAt   time.Time       `json:"createdAt"`
 	UpdatedAt   time.Time       `json:"updatedAt"`
+	Version     int             `json:"version"`
 }
 
 type MarketingModel struct {
@@ -50,7 +51,7 @@ func (m MarketingModel) GetCampaigns(status string) ([]*Campaign, error) {
 	query := `
 		SELECT 
 			id, name, status, start_date, end_date, goal, progress, metric, type, 
-			prize, ticket_price, winner_id, COALESCE(participants, '[]'), created_at, updated_at
+			prize, ticket_price, winner_id, COALESCE(participants, '[]'), created_at, updated_at, version
 		FROM marketing_campaigns
 		WHERE 1=1
 	`
@@ -79,7 +80,7 @@ func (m MarketingModel) GetCampaigns(status string) ([]*Campaign, error) {
 
 		err := rows.Scan(
 			&c.ID, &c.Name, &c.Status, &c.StartDate, &c.EndDate, &c.Goal, &c.Progress, &c.Metric, &c.Type,
-			&prize, &ticketPrice, &winnerId, &c.Participants, &c.CreatedAt, &c.UpdatedAt,
+			&prize, &ticketPrice, &winnerId, &c.Participants, &c.CreatedAt, &c.UpdatedAt, &c.Version,
 		)
 		if err != nil {
 			return nil, err
@@ -109,7 +110,7 @@ func (m MarketingModel) GetCampaign(id string) (*Campaign, error) {
 	query := `
 		SELECT 
 			id, name, status, start_date, end_date, goal, progress, metric, type, 
-			prize, ticket_price, winner_id, COALESCE(participants, '[]'), created_at, updated_at
+			prize, ticket_price, winner_id, COALESCE(participants, '[]'), created_at, updated_at, version
 		FROM marketing_campaigns
 		WHERE id = $1
 	`
@@ -123,7 +124,7 @@ func (m MarketingModel) GetCampaign(id string) (*Campaign, error) {
 
 	err := m.DB.QueryRowContext(ctx, query, id).Scan(
 		&c.ID, &c.Name, &c.Status, &c.StartDate, &c.EndDate, &c.Goal, &c.Progress, &c.Metric, &c.Type,
-		&prize, &ticketPrice, &winnerId, &c.Participants, &c.CreatedAt, &c.UpdatedAt,
+		&prize, &ticketPrice, &winnerId, &c.Participants, &c.CreatedAt, &c.UpdatedAt, &c.Version,
 	)
 
 	if err != nil {
@@ -158,15 +159,15 @@ func (m MarketingModel) CreateCampaign(c *Campaign) error {
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW()
 		)
+		RETURNING created_at, updated_at, version
 	`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err := m.DB.ExecContext(ctx, query,
+	return m.DB.QueryRowContext(ctx, query,
 		c.ID, c.Name, c.Status, c.StartDate, c.EndDate, c.Goal, c.Progress, c.Metric, c.Type,
 		c.Prize, c.TicketPrice, c.WinnerID, c.Participants,
-	)
-	return err
+	).Scan(&c.CreatedAt, &c.UpdatedAt, &c.Version)
 }
 
 func (m MarketingModel) UpdateCampaign(c *Campaign) error {
@@ -179,15 +180,22 @@ func (m MarketingModel) UpdateCampaign(c *Campaign) error {
 		SET 
 			name = $1, status = $2, start_date = $3, end_date = $4, goal = $5, 
 			progress = $6, metric = $7, type = $8, prize = $9, ticket_price = $10, 
-			winner_id = $11, participants = $12, updated_at = NOW()
-		WHERE id = $13
+			winner_id = $11, participants = $12, updated_at = NOW(), version = version + 1
+		WHERE id = $13 AND version = $14
+		RETURNING updated_at, version
 	`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err := m.DB.ExecContext(ctx, query,
+	err := m.DB.QueryRowContext(ctx, query,
 		c.Name, c.Status, c.StartDate, c.EndDate, c.Goal, c.Progress, c.Metric, c.Type,
-		c.Prize, c.TicketPrice, c.WinnerID, c.Participants, c.ID,
-	)
-	return err
+		c.Prize, c.TicketPrice, c.WinnerID, c.Participants, c.ID, c.Version,
+	).Scan(&c.UpdatedAt, &c.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrEditConflict
+		}
+		return err
+	}
+	return nil
 }
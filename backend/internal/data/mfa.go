@@ -0,0 +1,270 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	RecoveryCodeCount = 10
+	// MaxMFAAttempts is how many wrong codes a login challenge tolerates.
+	MaxMFAAttempts = 5
+)
+
+type UserMFA struct {
+	UserID       string     `json:"-"`
+	TOTPSecret   string     `json:"-"`
+	Enabled      bool       `json:"enabled"`
+	LastUsedStep int64      `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmedAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+type MFAModel struct {
+	DB *sql.DB
+}
+
+func (m MFAModel) Get(userID string) (*UserMFA, error) {
+	query := `
+		SELECT user_id, totp_secret, enabled, last_used_step, confirmed_at, created_at
+		FROM user_mfa
+		WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var mfa UserMFA
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(
+		&mfa.UserID,
+		&mfa.TOTPSecret,
+		&mfa.Enabled,
+		&mfa.LastUsedStep,
+		&mfa.ConfirmedAt,
+		&mfa.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &mfa, nil
+}
+
+// BeginEnrollment stores a new unconfirmed secret, replacing any earlier
+// unfinished attempt. Confirmed enrollments are left alone.
+func (m MFAModel) BeginEnrollment(userID, secret string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, totp_secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET totp_secret = EXCLUDED.totp_secret, last_used_step = 0, created_at = NOW()
+		WHERE user_mfa.enabled = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID, secret)
+	return err
+}
+
+func (m MFAModel) Enable(userID string, step int64) error {
+	query := `
+		UPDATE user_mfa
+		SET enabled = true, confirmed_at = NOW(), last_used_step = $2
+		WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID, step)
+	return err
+}
+
+// RecordStep advances last_used_step. It returns false if the step was already
+// used, which makes a captured code useless for a second login.
+func (m MFAModel) RecordStep(userID string, step int64) (bool, error) {
+	query := `UPDATE user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+// Delete removes the enrollment and any recovery codes.
+func (m MFAModel) Delete(userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes invalidates the old set and stores hashes of codes.
+func (m MFAModel) ReplaceRecoveryCodes(userID string, codes []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, code := range codes {
+		_, err := tx.ExecContext(ctx, `INSERT INTO user_mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hashRecoveryCode(code))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode burns a matching unused code.
+func (m MFAModel) UseRecoveryCode(userID, code string) (bool, error) {
+	query := `
+		UPDATE user_mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, hashRecoveryCode(code))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (m MFAModel) CountRecoveryCodes(userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM user_mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var count int
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// CreateChallenge issues the token exchanged for a session once the second
+// factor is verified.
+func (m MFAModel) CreateChallenge(userID string, ttl time.Duration) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `INSERT INTO mfa_challenges (token_hash, user_id, expiry) VALUES ($1, $2, $3)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err = m.DB.ExecContext(ctx, query, hashToken(token), userID, time.Now().Add(ttl))
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// UseChallengeAttempt counts an attempt against the challenge and returns its
+// user. Expired or exhausted challenges return ErrRecordNotFound.
+func (m MFAModel) UseChallengeAttempt(token string) (string, error) {
+	query := `
+		UPDATE mfa_challenges
+		SET attempts = attempts + 1
+		WHERE token_hash = $1 AND expiry > NOW() AND attempts < $2
+		RETURNING user_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var userID string
+	err := m.DB.QueryRowContext(ctx, query, hashToken(token), MaxMFAAttempts).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrRecordNotFound
+		}
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// DeleteChallenge removes a used challenge along with any expired ones.
+func (m MFAModel) DeleteChallenge(token string) error {
+	query := `DELETE FROM mfa_challenges WHERE token_hash = $1 OR expiry < NOW()`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, hashToken(token))
+	return err
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCodes returns n codes formatted as "xxxxx-xxxxx".
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		s := strings.ToLower(recoveryEncoding.EncodeToString(b))[:10]
+		codes[i] = s[:5] + "-" + s[5:]
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode ignores case, spaces and dashes so codes can be typed loosely.
+func normalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+}
+
+func hashRecoveryCode(code string) []byte {
+	return hashToken(normalizeRecoveryCode(code))
+}
+
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -0,0 +1,393 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+const (
+	RegistrationPending     = "pending"
+	RegistrationTransferred = "transferred"
+	RegistrationWaived      = "waived"
+)
+
+var RegistrationStatuses = []string{RegistrationPending, RegistrationTransferred, RegistrationWaived}
+
+const (
+	// Adopters get a first reminder this long after adoption, then again at
+	// the same interval until MaxRegistrationReminders have gone out.
+	RegistrationReminderDays = 14
+	MaxRegistrationReminders = 3
+)
+
+// chipSQL normalizes a stored chip number the same way NormalizeMicrochip
+// does, matching the expression indexes in migration 053.
+func chipSQL(column string) string {
+	return `UPPER(REGEXP_REPLACE(` + column + `, '[^A-Za-z0-9]', '', 'g'))`
+}
+
+var petChipSQL = chipSQL(`p.medical->'microchip'->>'microchipID'`)
+
+// NormalizeMicrochip strips the spaces, dashes and case that chip numbers
+// pick up when they're typed in from a scanner or a vet record.
+func NormalizeMicrochip(number string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r >= 'A' && r <= 'Z':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return -1
+	}, number)
+}
+
+// ValidMicrochip accepts the lengths in use in the US: 15-digit ISO chips,
+// and the older 10-character and 9-digit ones.
+func ValidMicrochip(number string) bool {
+	n := len(NormalizeMicrochip(number))
+	return n == 9 || n == 10 || n == 15
+}
+
+// Microchip returns the chip recorded in the pet's medical details.
+func (p *Pet) Microchip() (number, company string) {
+	var medical struct {
+		Microchip struct {
+			ID      *string `json:"microchipID"`
+			Company *string `json:"microchipCompany"`
+		} `json:"microchip"`
+	}
+	_ = json.Unmarshal(p.Medical, &medical)
+	if medical.Microchip.ID != nil {
+		number = strings.TrimSpace(*medical.Microchip.ID)
+	}
+	if medical.Microchip.Company != nil {
+		company = strings.TrimSpace(*medical.Microchip.Company)
+	}
+	return number, company
+}
+
+// MicrochipMatch is a record found for a chip number: a pet we have or had,
+// or a stray that came in with the chip.
+type MicrochipMatch struct {
+	Source       string                 `json:"source"` // "pet" or "stray"
+	PetID        string                 `json:"petId"`
+	PetName      string                 `json:"petName"`
+	Species      string                 `json:"species"`
+	Status       string                 `json:"status"`
+	ChipNumber   string                 `json:"chipNumber"`
+	Company      string                 `json:"company"`
+	AdopterName  string                 `json:"adopterName,omitempty"`
+	AdopterEmail string                 `json:"adopterEmail,omitempty"`
+	AdopterPhone string                 `json:"adopterPhone,omitempty"`
+	StrayID      *int64                 `json:"strayId,omitempty"`
+	FoundDate    string                 `json:"foundDate,omitempty"`
+	Registration *MicrochipRegistration `json:"registration,omitempty"`
+}
+
+// MicrochipRegistration tracks moving an adopted pet's chip registration
+// from the rescue to the adopter.
+type MicrochipRegistration struct {
+	PetID          string     `json:"petId"`
+	PetName        string     `json:"petName"`
+	ChipNumber     string     `json:"chipNumber"`
+	Company        string     `json:"company"`
+	AdoptionDate   string     `json:"adoptionDate"`
+	AdopterName    string     `json:"adopterName"`
+	AdopterEmail   string     `json:"adopterEmail"`
+	Status         string     `json:"status"`
+	TransferredAt  *time.Time `json:"transferredAt"`
+	RemindersSent  int        `json:"remindersSent"`
+	LastReminderAt *time.Time `json:"lastReminderAt"`
+	Overdue        bool       `json:"overdue"`
+	Notes          string     `json:"notes"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	Version        int        `json:"version"`
+}
+
+// setOverdue marks a pending registration that is past the first reminder.
+func (r *MicrochipRegistration) setOverdue(today time.Time) {
+	adopted, err := time.Parse(time.DateOnly, r.AdoptionDate)
+	r.Overdue = err == nil && r.Status == RegistrationPending &&
+		!today.Before(adopted.AddDate(0, 0, RegistrationReminderDays))
+}
+
+func ValidateMicrochipRegistration(v *validator.Validator, r *MicrochipRegistration) {
+	v.Check(validator.PermittedValue(r.Status, RegistrationStatuses...), "status", "must be pending, transferred or waived")
+	v.Check(len(r.Notes) <= 2000, "notes", "must be 2000 characters or fewer")
+}
+
+type MicrochipModel struct {
+	DB *sql.DB
+}
+
+// Owner finds the pet, other than exceptPetID, that already has the chip.
+func (m MicrochipModel) Owner(number, exceptPetID string) (*MicrochipMatch, error) {
+	query := `
+		SELECT p.id::text, p.name
+		FROM pets p
+		WHERE ` + petChipSQL + ` = $1 AND p.id::text <> $2
+		LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	match := MicrochipMatch{Source: "pet", ChipNumber: NormalizeMicrochip(number)}
+	err := m.DB.QueryRowContext(ctx, query, match.ChipNumber, exceptPetID).Scan(&match.PetID, &match.PetName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &match, nil
+}
+
+// Lookup finds everything we know about a chip: the pet it belongs to, with
+// its adopter when it has gone home, and any stray that was scanned with it.
+func (m MicrochipModel) Lookup(number string) ([]*MicrochipMatch, error) {
+	chip := NormalizeMicrochip(number)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, `
+		SELECT p.id::text, p.name, COALESCE(p.species, ''), COALESCE(p.status, ''),
+			COALESCE(p.medical->'microchip'->>'microchipID', ''), COALESCE(p.medical->'microchip'->>'microchipCompany', ''),
+			COALESCE(p.adoption->'adopterContactInfo'->>'name', ''),
+			COALESCE(p.adoption->'adopterContactInfo'->>'email', ''),
+			COALESCE(p.adoption->'adopterContactInfo'->>'phone', '')
+		FROM pets p
+		WHERE `+petChipSQL+` = $1
+		ORDER BY p.name`, chip)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []*MicrochipMatch{}
+	for rows.Next() {
+		match := MicrochipMatch{Source: "pet"}
+		err := rows.Scan(&match.PetID, &match.PetName, &match.Species, &match.Status, &match.ChipNumber, &match.Company,
+			&match.AdopterName, &match.AdopterEmail, &match.AdopterPhone)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, &match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	strays, err := m.DB.QueryContext(ctx, `
+		SELECT s.id, s.pet_id, COALESCE(p.name, ''), COALESCE(p.species, ''), s.status, s.microchip_number,
+			to_char(s.found_date, 'YYYY-MM-DD')
+		FROM stray_intakes s
+		LEFT JOIN pets p ON p.id::text = s.pet_id
+		WHERE `+chipSQL("s.microchip_number")+` = $1
+		ORDER BY s.found_date DESC, s.id DESC`, chip)
+	if err != nil {
+		return nil, err
+	}
+	defer strays.Close()
+
+	for strays.Next() {
+		match := MicrochipMatch{Source: "stray"}
+		var strayID int64
+		err := strays.Scan(&strayID, &match.PetID, &match.PetName, &match.Species, &match.Status, &match.ChipNumber, &match.FoundDate)
+		if err != nil {
+			return nil, err
+		}
+		match.StrayID = &strayID
+		matches = append(matches, &match)
+	}
+	if err := strays.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, match := range matches {
+		if match.Source != "pet" {
+			continue
+		}
+		reg, err := m.GetRegistration(match.PetID)
+		switch {
+		case err == nil:
+			match.Registration = reg
+		case !errors.Is(err, ErrRecordNotFound):
+			return nil, err
+		}
+	}
+
+	return matches, nil
+}
+
+const registrationColumns = `r.pet_id, COALESCE(p.name, ''), r.chip_number, r.company, to_char(r.adoption_date, 'YYYY-MM-DD'),
+	COALESCE(p.adoption->'adopterContactInfo'->>'name', ''), COALESCE(p.adoption->'adopterContactInfo'->>'email', ''),
+	r.status, r.transferred_at, r.reminders_sent, r.last_reminder_at, r.notes, r.created_at, r.updated_at, r.version`
+
+const registrationJoins = `
+	FROM microchip_registrations r
+	LEFT JOIN pets p ON p.id::text = r.pet_id`
+
+func scanRegistration(row interface{ Scan(...any) error }, extra ...any) (*MicrochipRegistration, error) {
+	var r MicrochipRegistration
+	dest := append(extra, &r.PetID, &r.PetName, &r.ChipNumber, &r.Company, &r.AdoptionDate, &r.AdopterName, &r.AdopterEmail,
+		&r.Status, &r.TransferredAt, &r.RemindersSent, &r.LastReminderAt, &r.Notes, &r.CreatedAt, &r.UpdatedAt, &r.Version)
+
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	r.setOverdue(time.Now())
+	return &r, nil
+}
+
+// Track starts following the chip registration of a pet that has just been
+// adopted. A pet adopted again (after a return) starts over as pending.
+func (m MicrochipModel) Track(petID, chipNumber, company, adoptionDate string) error {
+	query := `
+		INSERT INTO microchip_registrations (pet_id, chip_number, company, adoption_date)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (pet_id) DO UPDATE
+		SET chip_number = EXCLUDED.chip_number, company = EXCLUDED.company, adoption_date = EXCLUDED.adoption_date,
+			status = CASE WHEN microchip_registrations.adoption_date = EXCLUDED.adoption_date
+				THEN microchip_registrations.status ELSE 'pending' END,
+			transferred_at = CASE WHEN microchip_registrations.adoption_date = EXCLUDED.adoption_date
+				THEN microchip_registrations.transferred_at END,
+			reminders_sent = CASE WHEN microchip_registrations.adoption_date = EXCLUDED.adoption_date
+				THEN microchip_registrations.reminders_sent ELSE 0 END,
+			last_reminder_at = CASE WHEN microchip_registrations.adoption_date = EXCLUDED.adoption_date
+				THEN microchip_registrations.last_reminder_at END,
+			updated_at = NOW(), version = microchip_registrations.version + 1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, petID, NormalizeMicrochip(chipNumber), company, adoptionDate)
+	return err
+}
+
+func (m MicrochipModel) GetRegistration(petID string) (*MicrochipRegistration, error) {
+	query := `SELECT ` + registrationColumns + registrationJoins + ` WHERE r.pet_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanRegistration(m.DB.QueryRowContext(ctx, query, petID))
+}
+
+// Registrations lists tracked registrations, oldest adoption first. An
+// empty status lists all of them.
+func (m MicrochipModel) Registrations(status string, filters Filters) ([]*MicrochipRegistration, Metadata, error) {
+	query := `
+		SELECT count(*) OVER(), ` + registrationColumns + registrationJoins + `
+		WHERE ($1 = '' OR r.status = $1)
+		ORDER BY r.adoption_date, r.pet_id
+		LIMIT $2 OFFSET $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, status, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	registrations := []*MicrochipRegistration{}
+	for rows.Next() {
+		r, err := scanRegistration(rows, &totalRecords)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		registrations = append(registrations, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return registrations, calculateMetadata(totalRecords, filters.Page, filters.PageSize), nil
+}
+
+// UpdateRegistration saves the status and notes. Marking it transferred
+// records when; moving it back to pending clears that.
+func (m MicrochipModel) UpdateRegistration(r *MicrochipRegistration) error {
+	query := `
+		UPDATE microchip_registrations
+		SET status = $1, notes = $2,
+			transferred_at = CASE WHEN $1 = 'transferred' THEN COALESCE(transferred_at, NOW()) END,
+			updated_at = NOW(), version = version + 1
+		WHERE pet_id = $3 AND version = $4
+		RETURNING transferred_at, updated_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, r.Status, r.Notes, r.PetID, r.Version).Scan(&r.TransferredAt, &r.UpdatedAt, &r.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+	r.setOverdue(time.Now())
+	return nil
+}
+
+// DueReminders lists pending registrations whose adopter is due another
+// reminder: RegistrationReminderDays after adoption and after each earlier
+// reminder, up to MaxRegistrationReminders.
+func (m MicrochipModel) DueReminders() ([]*MicrochipRegistration, error) {
+	query := `
+		SELECT ` + registrationColumns + registrationJoins + `
+		WHERE r.status = 'pending'
+			AND r.reminders_sent < $1
+			AND r.adoption_date <= CURRENT_DATE - $2::int
+			AND (r.last_reminder_at IS NULL OR r.last_reminder_at <= NOW() - make_interval(days => $2::int))
+			AND LOWER(COALESCE(p.status, '')) = 'adopted'
+		ORDER BY r.adoption_date`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, MaxRegistrationReminders, RegistrationReminderDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []*MicrochipRegistration{}
+	for rows.Next() {
+		r, err := scanRegistration(rows)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, r)
+	}
+
+	return due, rows.Err()
+}
+
+func (m MicrochipModel) MarkReminded(petID string) error {
+	query := `
+		UPDATE microchip_registrations
+		SET reminders_sent = reminders_sent + 1, last_reminder_at = NOW()
+		WHERE pet_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, petID)
+	return err
+}
@@ -0,0 +1,60 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNormalizeMicrochip(t *testing.T) {
+	tests := map[string]string{
+		"985 112 003 456 789": "985112003456789",
+		"0a1-B2c-3D4e":        "0A1B2C3D4E",
+		"  ":                  "",
+	}
+	for in, want := range tests {
+		if got := NormalizeMicrochip(in); got != want {
+			t.Errorf("NormalizeMicrochip(%q) = %q; want %q", in, got, want)
+		}
+	}
+
+	if !ValidMicrochip("985-112-003-456-789") || !ValidMicrochip("0A1B2C3D4E") || !ValidMicrochip("123456789") {
+		t.Error("valid chip lengths rejected")
+	}
+	if ValidMicrochip("12345") || ValidMicrochip("") {
+		t.Error("short chip accepted")
+	}
+}
+
+func TestPetMicrochip(t *testing.T) {
+	p := &Pet{Medical: json.RawMessage(`{"microchip": {"microchipID": " 985112003456789 ", "microchipCompany": "HomeAgain", "microchipped": true}}`)}
+	number, company := p.Microchip()
+	if number != "985112003456789" || company != "HomeAgain" {
+		t.Errorf("Microchip() = %q, %q", number, company)
+	}
+
+	p.Medical = json.RawMessage(`{"microchip": {"microchipID": null}}`)
+	if number, _ := p.Microchip(); number != "" {
+		t.Errorf("Microchip() = %q; want empty", number)
+	}
+}
+
+func TestRegistrationOverdue(t *testing.T) {
+	today, _ := time.Parse(time.DateOnly, "2025-06-15")
+
+	r := &MicrochipRegistration{AdoptionDate: "2025-06-01", Status: RegistrationPending}
+	if r.setOverdue(today); !r.Overdue {
+		t.Error("pending two weeks after adoption should be overdue")
+	}
+
+	r.AdoptionDate = "2025-06-02"
+	if r.setOverdue(today); r.Overdue {
+		t.Error("should not be overdue before the first reminder")
+	}
+
+	r.AdoptionDate = "2025-01-01"
+	r.Status = RegistrationTransferred
+	if r.setOverdue(today); r.Overdue {
+		t.Error("a transferred registration is never overdue")
+	}
+}
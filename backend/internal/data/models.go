@@ -3,31 +3,63 @@ package data
 import "database/sql"
 
 type Models struct {
-	Volunteers    VolunteerModel
-	Users         UserModel
-	Pets          PetModel
-	Metrics       MetricModel
-	Sessions      SessionModel
-	Shifts        ShiftModel
-	Applications  ApplicationModel
-	Marketing     MarketingModel
-	Notifications NotificationModel
-	Contracts     ContractModel
-	Invitations   InvitationModel
+	Volunteers     VolunteerModel
+	Users          UserModel
+	Pets           PetModel
+	Metrics        MetricModel
+	Sessions       SessionModel
+	Shifts         ShiftModel
+	Applications   ApplicationModel
+	Marketing      MarketingModel
+	Notifications  NotificationModel
+	Contracts      ContractModel
+	Invitations    InvitationModel
+	StaffingRules  StaffingRuleModel
+	VolunteerStats VolunteerStatsModel
+	MFA            MFAModel
+	Tokens         TokenModel
+	APIKeys        APIKeyModel
+	LoginAttempts  LoginAttemptModel
+	OIDCStates     OIDCStateModel
+	Audit          AuditModel
+	Privacy        PrivacyModel
+	Fosters        FosterModel
+	FosterUpdates  FosterUpdateModel
+	Waitlist       SurrenderWaitlistModel
+	Strays         StrayModel
+	Locations      LocationModel
+	Microchips     MicrochipModel
+	Returns        PetReturnModel
 }
 
 func NewModels(db *sql.DB) Models {
 	return Models{
-		Volunteers:    VolunteerModel{DB: db},
-		Users:         UserModel{DB: db},
-		Pets:          PetModel{DB: db},
-		Metrics:       MetricModel{DB: db},
-		Sessions:      SessionModel{DB: db},
-		Shifts:        ShiftModel{DB: db},
-		Applications:  ApplicationModel{DB: db},
-		Marketing:     MarketingModel{DB: db},
-		Notifications: NotificationModel{DB: db},
-		Contracts:     ContractModel{DB: db},
-		Invitations:   InvitationModel{DB: db},
+		Volunteers:     VolunteerModel{DB: db},
+		Users:          UserModel{DB: db},
+		Pets:           PetModel{DB: db},
+		Metrics:        MetricModel{DB: db},
+		Sessions:       SessionModel{DB: db},
+		Shifts:         ShiftModel{DB: db},
+		Applications:   ApplicationModel{DB: db},
+		Marketing:      MarketingModel{DB: db},
+		Notifications:  NotificationModel{DB: db},
+		Contracts:      ContractModel{DB: db},
+		Invitations:    InvitationModel{DB: db},
+		StaffingRules:  StaffingRuleModel{DB: db},
+		VolunteerStats: VolunteerStatsModel{DB: db},
+		MFA:            MFAModel{DB: db},
+		Tokens:         TokenModel{DB: db},
+		APIKeys:        APIKeyModel{DB: db},
+		LoginAttempts:  LoginAttemptModel{DB: db},
+		OIDCStates:     OIDCStateModel{DB: db},
+		Audit:          AuditModel{DB: db},
+		Privacy:        PrivacyModel{DB: db},
+		Fosters:        FosterModel{DB: db},
+		FosterUpdates:  FosterUpdateModel{DB: db},
+		Waitlist:       SurrenderWaitlistModel{DB: db},
+		Strays:         StrayModel{DB: db},
+		Locations:      LocationModel{DB: db},
+		Microchips:     MicrochipModel{DB: db},
+		Returns:        PetReturnModel{DB: db},
 	}
 }
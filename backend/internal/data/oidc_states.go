@@ -0,0 +1,60 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// OIDCState is what we remember about a single sign-on login between the
+// redirect to the provider and its callback.
+type OIDCState struct {
+	CodeVerifier string
+	Nonce        string
+	RedirectPath string
+}
+
+type OIDCStateModel struct {
+	DB *sql.DB
+}
+
+func (m OIDCStateModel) Insert(state string, s *OIDCState, ttl time.Duration) error {
+	query := `
+		INSERT INTO oidc_states (state_hash, code_verifier, nonce, redirect_path, expiry)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, hashToken(state), s.CodeVerifier, s.Nonce, s.RedirectPath, time.Now().Add(ttl))
+	return err
+}
+
+// Consume deletes and returns a live state so each callback can only be
+// completed once.
+func (m OIDCStateModel) Consume(state string) (*OIDCState, error) {
+	query := `
+		DELETE FROM oidc_states
+		WHERE state_hash = $1 AND expiry > NOW()
+		RETURNING code_verifier, nonce, redirect_path`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var s OIDCState
+	err := m.DB.QueryRowContext(ctx, query, hashToken(state)).Scan(&s.CodeVerifier, &s.Nonce, &s.RedirectPath)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (m OIDCStateModel) DeleteExpired(ctx context.Context) error {
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM oidc_states WHERE expiry < NOW()`)
+	return err
+}
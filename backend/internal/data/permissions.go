@@ -0,0 +1,146 @@
+package data
+
+import (
+	"slices"
+	"strings"
+)
+
+// Permissions are "<resource>:<action>" strings checked per route.
+const (
+	PermPetsWrite              = "pets:write"
+	PermMedicalWrite           = "medical:write"
+	PermApplicationsRead       = "applications:read"
+	PermApplicationsWrite      = "applications:write"
+	PermVolunteersRead         = "volunteers:read"
+	PermVolunteersWrite        = "volunteers:write"
+	PermShiftsRead             = "shifts:read"
+	PermShiftsWrite            = "shifts:write"
+	PermStaffingWrite          = "staffing:write"
+	PermReportsRead            = "reports:read"
+	PermMarketingRead          = "marketing:read"
+	PermMarketingWrite         = "marketing:write"
+	PermNotificationsBroadcast = "notifications:broadcast"
+	PermContractsWrite         = "contracts:write"
+	PermUsersManage            = "users:manage"
+	PermAuditRead              = "audit:read"
+	PermPrivacyManage          = "privacy:manage"
+	PermFostersRead            = "fosters:read"
+	PermFostersWrite           = "fosters:write"
+	PermFosterPortal           = "foster:portal"
+	PermStraysManage           = "strays:manage"
+	PermLocationsManage        = "locations:manage"
+	PermMicrochipsManage       = "microchips:manage"
+	PermReturnsManage          = "returns:manage"
+	PermRefundsOverride        = "refunds:override"
+)
+
+var Permissions = []string{
+	PermPetsWrite,
+	PermMedicalWrite,
+	PermApplicationsRead,
+	PermApplicationsWrite,
+	PermVolunteersRead,
+	PermVolunteersWrite,
+	PermShiftsRead,
+	PermShiftsWrite,
+	PermStaffingWrite,
+	PermReportsRead,
+	PermMarketingRead,
+	PermMarketingWrite,
+	PermNotificationsBroadcast,
+	PermContractsWrite,
+	PermUsersManage,
+	PermAuditRead,
+	PermPrivacyManage,
+	PermFostersRead,
+	PermFostersWrite,
+	PermFosterPortal,
+	PermStraysManage,
+	PermLocationsManage,
+	PermMicrochipsManage,
+	PermReturnsManage,
+	PermRefundsOverride,
+}
+
+// Roles stored in users.role. Migration 037 normalised the legacy lowercase
+// values to these.
+const (
+	RoleSuperAdmin           = "SUPER_ADMIN"
+	RoleAdmin                = "ADMIN"
+	RoleFosterCoordinator    = "FOSTER_COORDINATOR"
+	RoleMedicalLead          = "MEDICAL_LEAD"
+	RoleVolunteerCoordinator = "VOLUNTEER_COORDINATOR"
+	RoleMarketing            = "MARKETING"
+	RoleBoardMember          = "BOARD_MEMBER"
+	RoleVolunteer2           = "VOLUNTEER_2"
+	RoleVolunteer1           = "VOLUNTEER_1"
+	RoleTeen                 = "TEEN"
+	RoleFoster               = "FOSTER"
+)
+
+type Role struct {
+	Name        string   `json:"name"`
+	Label       string   `json:"label"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// Roles is ordered from most to least privileged.
+var Roles = []Role{
+	{RoleSuperAdmin, "Super Admin", "Everything, including granting Super Admin.", Permissions},
+	{RoleAdmin, "Admin", "Everything except granting Super Admin.", Permissions},
+	{RoleFosterCoordinator, "Foster Coordinator", "Pets, strays, microchips, returns, shelter locations, foster homes, adoption/surrender applications and contracts.", []string{
+		PermPetsWrite, PermStraysManage, PermLocationsManage, PermMicrochipsManage, PermReturnsManage, PermFostersRead, PermFostersWrite, PermApplicationsRead, PermApplicationsWrite, PermContractsWrite, PermVolunteersRead, PermShiftsRead,
+	}},
+	{RoleMedicalLead, "Medical Lead", "Pet records including medical history, strays, microchips and shelter locations.", []string{
+		PermPetsWrite, PermMedicalWrite, PermStraysManage, PermLocationsManage, PermMicrochipsManage, PermApplicationsRead, PermVolunteersRead, PermShiftsRead,
+	}},
+	{RoleVolunteerCoordinator, "Volunteer Coordinator", "Volunteers, shifts, staffing rules and hour reports.", []string{
+		PermVolunteersRead, PermVolunteersWrite, PermShiftsRead, PermShiftsWrite, PermStaffingWrite, PermReportsRead, PermApplicationsRead,
+	}},
+	// Marketing keeps pets:write for listings; stray finders, locations,
+	// microchip owners and returns have their own permissions. Overriding a
+	// policy refund is admin-only (refunds:override).
+	{RoleMarketing, "Marketing", "Campaigns, broadcasts and public pet listings (no medical).", []string{
+		PermPetsWrite, PermMarketingRead, PermMarketingWrite, PermNotificationsBroadcast,
+	}},
+	{RoleBoardMember, "Board Member", "Read-only access to applications, volunteers, foster homes, shifts, reports and campaigns.", []string{
+		PermApplicationsRead, PermFostersRead, PermVolunteersRead, PermShiftsRead, PermReportsRead, PermMarketingRead,
+	}},
+	{RoleVolunteer2, "Volunteer (Tier 2)", "Senior volunteer: schedule and roster.", []string{
+		PermVolunteersRead, PermShiftsRead,
+	}},
+	{RoleVolunteer1, "Volunteer (Tier 1)", "Schedule only.", []string{
+		PermShiftsRead,
+	}},
+	{RoleTeen, "Teen Volunteer", "Schedule only.", []string{
+		PermShiftsRead,
+	}},
+	{RoleFoster, "Foster Parent", "Foster portal: updates and photos for the pets in their care.", []string{
+		PermFosterPortal,
+	}},
+}
+
+// GetRole looks up a role by name (case-insensitive).
+func GetRole(name string) (Role, bool) {
+	for _, role := range Roles {
+		if strings.EqualFold(role.Name, name) {
+			return role, true
+		}
+	}
+	return Role{}, false
+}
+
+// HasPermission reports whether role grants perm. Unknown roles grant nothing.
+func HasPermission(role, perm string) bool {
+	r, ok := GetRole(role)
+	if !ok {
+		return false
+	}
+	return slices.Contains(r.Permissions, perm)
+}
+
+// IsAdminRole reports whether role is ADMIN or SUPER_ADMIN.
+func IsAdminRole(role string) bool {
+	return strings.EqualFold(role, RoleAdmin) || strings.EqualFold(role, RoleSuperAdmin)
+}
@@ -0,0 +1,53 @@
+package data
+
+import "testing"
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		role string
+		perm string
+		want bool
+	}{
+		{"SUPER_ADMIN", PermUsersManage, true},
+		{"admin", PermMedicalWrite, true}, // legacy lowercase value
+		{RoleMedicalLead, PermMedicalWrite, true},
+		{RoleFosterCoordinator, PermPetsWrite, true},
+		{RoleFosterCoordinator, PermMedicalWrite, false},
+		{RoleBoardMember, PermApplicationsRead, true},
+		{RoleBoardMember, PermApplicationsWrite, false},
+		{RoleVolunteer1, PermVolunteersRead, false},
+		{RoleFoster, PermFosterPortal, true},
+		{RoleFoster, PermFostersRead, false},
+		{RoleMarketing, PermPetsWrite, true},
+		{RoleMarketing, PermStraysManage, false},
+		{RoleMarketing, PermLocationsManage, false},
+		{RoleMarketing, PermMicrochipsManage, false},
+		{RoleMedicalLead, PermMicrochipsManage, true},
+		{RoleMarketing, PermReturnsManage, false},
+		{RoleFosterCoordinator, PermReturnsManage, true},
+		{RoleFosterCoordinator, PermRefundsOverride, false},
+		{RoleAdmin, PermRefundsOverride, true},
+		{"tier_1", PermShiftsRead, false}, // unmigrated values grant nothing
+		{"", PermShiftsRead, false},
+	}
+
+	for _, tt := range tests {
+		if got := HasPermission(tt.role, tt.perm); got != tt.want {
+			t.Errorf("HasPermission(%q, %q) = %v; want %v", tt.role, tt.perm, got, tt.want)
+		}
+	}
+}
+
+func TestRolePermissionsAreKnown(t *testing.T) {
+	known := map[string]bool{}
+	for _, p := range Permissions {
+		known[p] = true
+	}
+	for _, role := range Roles {
+		for _, p := range role.Permissions {
+			if !known[p] {
+				t.Errorf("role %s grants unknown permission %q", role.Name, p)
+			}
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 )
@@ -285,6 +286,17 @@ type Pet struct {
 	Sponsored       json.RawMessage `json:"sponsored"`
 	Photos          json.RawMessage `json:"photos"`
 	ProfileSettings json.RawMessage `json:"profileSettings"`
+
+	Version int `json:"version"`
+}
+
+// Status is details.status, the pet's lifecycle status.
+func (p *Pet) Status() string {
+	var details struct {
+		Status string `json:"status"`
+	}
+	_ = json.Unmarshal(p.Details, &details)
+	return details.Status
 }
 
 func (m PetModel) GetAll(status string, search, sort string, filters map[string]string) ([]*Pet, error) {
@@ -311,7 +323,8 @@ func (m PetModel) GetAll(status string, search, sort string, filters map[string]
 			COALESCE(returned, '{}'),
 			COALESCE(sponsored, '{}'),
 			COALESCE(photos, '[]'),
-			COALESCE(profile_settings, '{}')
+			COALESCE(profile_settings, '{}'),
+			version
 		FROM pets
 		WHERE 1=1
 	`
@@ -423,6 +436,7 @@ func (m PetModel) GetAll(status string, search, sort string, filters map[string]
 			&p.Sponsored,
 			&p.Photos,
 			&p.ProfileSettings,
+			&p.Version,
 		)
 		if err != nil {
 			fmt.Println("GetAll Scan Error:", err)
@@ -467,7 +481,8 @@ func (m PetModel) Get(id string) (*Pet, error) {
 			COALESCE(returned, '{}'),
 			COALESCE(sponsored, '{}'),
 			COALESCE(photos, '[]'),
-			COALESCE(profile_settings, '{}')
+			COALESCE(profile_settings, '{}'),
+			version
 		FROM pets
 		WHERE id = $1
 	`
@@ -494,6 +509,7 @@ func (m PetModel) Get(id string) (*Pet, error) {
 		&p.Sponsored,
 		&p.Photos,
 		&p.ProfileSettings,
+		&p.Version,
 	)
 
 	if err != nil {
@@ -538,7 +554,8 @@ func (m PetModel) GetByName(name string) (*Pet, error) {
 			COALESCE(returned, '{}'),
 			COALESCE(sponsored, '{}'),
 			COALESCE(photos, '[]'),
-			COALESCE(profile_settings, '{}')
+			COALESCE(profile_settings, '{}'),
+			version
 		FROM pets
 		WHERE LOWER(name) = LOWER($1)
 	`
@@ -565,6 +582,7 @@ func (m PetModel) GetByName(name string) (*Pet, error) {
 		&p.Sponsored,
 		&p.Photos,
 		&p.ProfileSettings,
+		&p.Version,
 	)
 
 	if err != nil {
@@ -624,7 +642,8 @@ func (m PetModel) Update(p *Pet) error {
 		if len(p.Adoption) > 0 {
 			if err := json.Unmarshal(p.Adoption, &adoptionMap); err == nil {
 				date, ok := adoptionMap["date"].(string)
-				if !ok || date == "" {
+				// A returned pet still carries its previous adoption
+				if !ok || date == "" || adoptedBeforeReturn(date, p.Returned) {
 					// Default to today using M/D/YYYY format to match CSV/existing data
 					adoptionMap["date"] = time.Now().Format("1/2/2006")
 					newAdoption, _ := json.Marshal(adoptionMap)
@@ -649,6 +668,15 @@ func (m PetModel) Update(p *Pet) error {
 	// --- Bidirectional Bonding Logic ---
 	// 1. Fetch current state to compare
 	currentPet, err := m.Get(p.ID)
+
+	// A stray can't be listed, fostered or adopted until its owner has had the
+	// chance to reclaim it
+	if err == nil && leavesStrayHold(currentPet.Status(), status) {
+		if err := m.checkStrayHold(p.ID); err != nil {
+			return err
+		}
+	}
+
 	if err == nil {
 		if err := m.syncBonding(p, currentPet); err != nil {
 			fmt.Println("Warning: Bonding sync failed:", err)
@@ -679,8 +707,10 @@ func (m PetModel) Update(p *Pet) error {
 			status = $14,
 			litter_name = $16,
 			species = $17,
-			slug = $18
-		WHERE id = $15
+			slug = $18,
+			version = version + 1
+		WHERE id = $15 AND version = $19
+		RETURNING version
 	`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -728,22 +758,69 @@ func (m PetModel) Update(p *Pet) error {
 		p.LitterName, // $16
 		p.Species,    // $17
 		p.Slug,       // $18
+		p.Version,    // $19
 	}
 
-	result, err := m.DB.ExecContext(ctx, query, args...)
+	err = m.DB.QueryRowContext(ctx, query, args...).Scan(&p.Version)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			// No row matched: either it was deleted or the version moved on
+			var exists bool
+			if err := m.DB.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM pets WHERE id = $1)`, p.ID).Scan(&exists); err != nil {
+				return err
+			}
+			if !exists {
+				return ErrRecordNotFound
+			}
+			return ErrEditConflict
+		}
 		return err
 	}
 
-	rows, err := result.RowsAffected()
+	return nil
+}
+
+// adoptedBeforeReturn reports whether an adoption date is from before the
+// pet's last return, so belongs to the previous adoption.
+func adoptedBeforeReturn(date string, returned json.RawMessage) bool {
+	var r struct {
+		Date string `json:"date"`
+	}
+	if len(returned) == 0 || json.Unmarshal(returned, &r) != nil {
+		return false
+	}
+	adopted, ok := ParseRecordDate(date)
+	if !ok {
+		return false
+	}
+	back, ok := ParseRecordDate(r.Date)
+	return ok && adopted.Before(back)
+}
+
+// leavesStrayHold reports whether a status change would list the pet or take
+// it out of the shelter, which a stray on hold mustn't do.
+func leavesStrayHold(from, to string) bool {
+	return !strings.EqualFold(from, to) && !slices.Contains(StrayHoldStatuses, strings.ToLower(to))
+}
+
+// checkStrayHold returns ErrStrayHold if the pet is a stray whose hold
+// hasn't ended.
+func (m PetModel) checkStrayHold(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var held bool
+	err := m.DB.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM stray_intakes
+			WHERE pet_id = $1 AND status = 'holding' AND hold_until > CURRENT_DATE
+		)`, id).Scan(&held)
 	if err != nil {
 		return err
 	}
-
-	if rows == 0 {
-		return ErrRecordNotFound
+	if held {
+		return ErrStrayHold
 	}
-
 	return nil
 }
 
@@ -752,6 +829,60 @@ func (m PetModel) Insert(p *Pet) error {
 		return fmt.Errorf(ErrDBNotAvailable)
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return insertPet(ctx, m.DB, p)
+}
+
+// InsertForApplication inserts the pet taken in from a surrender and links
+// the application to it in one transaction. If the application already has
+// a pet, by the time its row lock is ours, nothing is inserted and it
+// returns ErrEditConflict.
+func (m PetModel) InsertForApplication(p *Pet, applicationID int64) error {
+	if m.DB == nil {
+		return fmt.Errorf(ErrDBNotAvailable)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertPet(ctx, tx, p); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE applications
+		SET pet_id = $1, updated_at = NOW(), version = version + 1
+		WHERE id = $2 AND pet_id IS NULL`, p.ID, applicationID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrEditConflict
+	}
+
+	return tx.Commit()
+}
+
+// petWriter is a *sql.DB or *sql.Tx.
+type petWriter interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertPet(ctx context.Context, db petWriter, p *Pet) error {
+
 	// Generate Slug
 	slug := strings.ToLower(strings.Join(strings.Fields(p.Name), "-"))
 	slug = strings.Map(func(r rune) rune {
@@ -798,7 +929,7 @@ func (m PetModel) Insert(p *Pet) error {
 			status, litter_name, species, created_at, updated_at
 		)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW(), NOW())
-		RETURNING id, created_at, updated_at
+		RETURNING id, created_at, updated_at, version
 	`
 
 	var detailsMap map[string]interface{}
@@ -830,10 +961,7 @@ func (m PetModel) Insert(p *Pet) error {
 		p.Species,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	err = m.DB.QueryRowContext(ctx, query, args...).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	err = db.QueryRowContext(ctx, query, args...).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt, &p.Version)
 	if err != nil {
 		return err
 	}
@@ -848,7 +976,7 @@ func (m PetModel) Insert(p *Pet) error {
 	finalSlug := fmt.Sprintf("%s-%s", slug, suffix)
 
 	updateSlugQuery := `UPDATE pets SET slug = $1 WHERE id = $2`
-	_, _ = m.DB.ExecContext(ctx, updateSlugQuery, finalSlug, p.ID)
+	_, _ = db.ExecContext(ctx, updateSlugQuery, finalSlug, p.ID)
 	// Ignore error on slug update for now, or log it? It's non-critical but desired.
 
 	return nil
@@ -0,0 +1,672 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	DSRExport  = "export"
+	DSRErasure = "erasure"
+)
+
+var ErrSubjectHasPrivilegedAccount = errors.New("data subject has an admin account")
+
+// Subject identifies the person a privacy request is about. Either field may
+// be empty, not both.
+type Subject struct {
+	Email string
+	Phone string
+}
+
+// NewSubject normalises the email (lowercase) and phone (last 10 digits, so
+// "+1 (555) 010-0000" and "5550100000" match).
+func NewSubject(email, phone string) Subject {
+	return Subject{
+		Email: strings.ToLower(strings.TrimSpace(email)),
+		Phone: NormalizePhone(phone),
+	}
+}
+
+func NormalizePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if len(digits) > 10 {
+		digits = digits[len(digits)-10:]
+	}
+	return digits
+}
+
+func (s Subject) hashes() (email, phone []byte) {
+	if s.Email != "" {
+		sum := sha256.Sum256([]byte(s.Email))
+		email = sum[:]
+	}
+	if s.Phone != "" {
+		sum := sha256.Sum256([]byte(s.Phone))
+		phone = sum[:]
+	}
+	return email, phone
+}
+
+// phoneSQL normalises a phone column the same way as NormalizePhone.
+func phoneSQL(expr string) string {
+	return fmt.Sprintf(`right(regexp_replace(COALESCE(%s, ''), '\D', '', 'g'), 10)`, expr)
+}
+
+// Match conditions take the email as $1 and the phone as $2; an empty value
+// matches nothing.
+var (
+	applicationSubjectSQL = fmt.Sprintf(`(
+		($1 <> '' AND $1 IN (LOWER(COALESCE(a.data->>'email', a.data->>'Email', '')), LOWER(COALESCE(a.data->>'parentEmail', ''))))
+		OR ($2 <> '' AND $2 IN (%s, %s, %s)))`,
+		phoneSQL("a.data->>'phoneNumber'"), phoneSQL("a.data->>'cellPhoneNumber'"), phoneSQL("a.data->>'parentPhone'"))
+
+	volunteerSelfSQL = fmt.Sprintf(`(
+		($1 <> '' AND LOWER(v.email) = $1) OR ($2 <> '' AND %s = $2))`, phoneSQL("v.phone"))
+
+	volunteerParentSQL = fmt.Sprintf(`(
+		($1 <> '' AND LOWER(v.parent_email) = $1) OR ($2 <> '' AND %s = $2))`, phoneSQL("v.parent_phone"))
+
+	fosterSubjectSQL = fmt.Sprintf(`(
+		($1 <> '' AND LOWER(f.email) = $1) OR ($2 <> '' AND %s = $2))`, phoneSQL("f.phone"))
+
+	strayFinderSQL = fmt.Sprintf(`(
+		($1 <> '' AND LOWER(s.finder_email) = $1) OR ($2 <> '' AND %s = $2))`, phoneSQL("s.finder_phone"))
+
+	strayOwnerSQL = fmt.Sprintf(`(
+		($1 <> '' AND LOWER(s.owner_contact) = $1) OR ($2 <> '' AND %s = $2))`, phoneSQL("s.owner_contact"))
+
+	returnAdopterSQL = fmt.Sprintf(`(
+		($1 <> '' AND LOWER(r.adopter_email) = $1) OR ($2 <> '' AND %s = $2))`, phoneSQL("r.adopter_phone"))
+
+	petSubjectSQL = fmt.Sprintf(`(
+		($1 <> '' AND $1 IN (LOWER(COALESCE(p.adoption->'adopterContactInfo'->>'email', '')), LOWER(COALESCE(p.foster->'fosterContactInfo'->>'email', ''))))
+		OR ($2 <> '' AND $2 IN (%s, %s)))`,
+		phoneSQL("p.adoption->'adopterContactInfo'->>'phone'"), phoneSQL("p.foster->'fosterContactInfo'->>'phone'"))
+)
+
+// subjectSources are the exported record sets, in bundle order. Queries
+// select one jsonb document per row and take the email as $1 and, unless
+// emailOnly, the phone as $2.
+var subjectSources = []struct {
+	name      string
+	query     string
+	emailOnly bool
+}{
+	{"applications", `SELECT to_jsonb(a) - 'original_html' FROM applications a WHERE ` + applicationSubjectSQL + ` ORDER BY a.id`, false},
+	{"contracts", `SELECT to_jsonb(c) - 'token' FROM contracts c WHERE c.application_id IN (SELECT a.id FROM applications a WHERE ` + applicationSubjectSQL + `) ORDER BY c.created_at`, false},
+	{"volunteers", `SELECT to_jsonb(v) FROM volunteers v WHERE ` + volunteerSelfSQL + ` OR ` + volunteerParentSQL + ` ORDER BY v.id`, false},
+	{"pets", `SELECT jsonb_build_object('id', p.id, 'name', p.name, 'adoption', p.adoption, 'foster', p.foster) FROM pets p WHERE ` + petSubjectSQL + ` ORDER BY p.id`, false},
+	{"fosterHomes", `SELECT to_jsonb(f) FROM foster_homes f WHERE ` + fosterSubjectSQL + ` ORDER BY f.id`, false},
+	{"strayIntakes", `SELECT to_jsonb(s) FROM stray_intakes s WHERE ` + strayFinderSQL + ` OR ` + strayOwnerSQL + ` ORDER BY s.id`, false},
+	{"petReturns", `SELECT to_jsonb(r) FROM pet_returns r WHERE ` + returnAdopterSQL + ` ORDER BY r.id`, false},
+	{"users", `SELECT to_jsonb(u) - 'password_hash' FROM users u WHERE LOWER(u.email) = $1 ORDER BY u.id`, true},
+	{"invitations", `SELECT to_jsonb(i) - 'token_hash' FROM invitations i WHERE LOWER(i.email) = $1 ORDER BY i.created_at`, true},
+	{"loginAttempts", `SELECT to_jsonb(l) FROM login_attempts l WHERE l.email = $1 ORDER BY l.created_at`, true},
+}
+
+// SubjectRecords holds every record found for a subject, keyed by record
+// set ("applications", "volunteers", ...).
+type SubjectRecords map[string][]json.RawMessage
+
+func (s SubjectRecords) Counts() map[string]int {
+	counts := map[string]int{}
+	for _, source := range subjectSources {
+		counts[source.name] = len(s[source.name])
+	}
+	return counts
+}
+
+// DataSubjectRequest is the receipt kept for an export or erasure.
+type DataSubjectRequest struct {
+	ID          int64             `json:"id"`
+	Kind        string            `json:"kind"`
+	RequestedBy *string           `json:"requestedBy,omitempty"`
+	Counts      map[string]int    `json:"counts"`
+	Retained    map[string]string `json:"retained,omitempty"` // record set -> why it was kept
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
+type PrivacyModel struct {
+	DB *sql.DB
+}
+
+// Find gathers every record that mentions the subject.
+func (m PrivacyModel) Find(subject Subject) (SubjectRecords, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	records := SubjectRecords{}
+	for _, source := range subjectSources {
+		args := []any{subject.Email, subject.Phone}
+		if source.emailOnly {
+			if subject.Email == "" {
+				records[source.name] = []json.RawMessage{}
+				continue
+			}
+			args = args[:1]
+		}
+
+		rows, err := m.DB.QueryContext(ctx, source.query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.name, err)
+		}
+
+		docs := []json.RawMessage{}
+		for rows.Next() {
+			var doc []byte
+			if err := rows.Scan(&doc); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			docs = append(docs, doc)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		records[source.name] = docs
+	}
+
+	return records, nil
+}
+
+// Erase redacts or deletes everything held about the subject in one
+// transaction and stores the receipt. Records kept for a legal or security
+// reason are listed in the receipt's Retained.
+func (m PrivacyModel) Erase(subject Subject, requestedBy string) (*DataSubjectRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	counts := map[string]int{}
+	retained := map[string]string{}
+	args := []any{subject.Email, subject.Phone}
+
+	exec := func(key, query string, args ...any) error {
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			counts[key] += int(n)
+		}
+		return nil
+	}
+
+	// erased collects the audited resources the subject's data came out of,
+	// by audit resource type, so their audit entries can be redacted too.
+	erased := map[string][]string{}
+	execReturning := func(key, resourceType, query string, args ...any) error {
+		rows, err := tx.QueryContext(ctx, query+` RETURNING id::text`, args...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			counts[key]++
+			erased[resourceType] = append(erased[resourceType], id)
+		}
+		return rows.Err()
+	}
+
+	// Accounts with admin roles have to be demoted first, so an erasure can't
+	// lock the organisation out of its own system.
+	if subject.Email != "" {
+		var role string
+		err := tx.QueryRowContext(ctx, `SELECT role FROM users WHERE LOWER(email) = $1`, subject.Email).Scan(&role)
+		switch {
+		case err == nil && IsAdminRole(role):
+			return nil, ErrSubjectHasPrivilegedAccount
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			return nil, err
+		}
+	}
+
+	// Applications: reduce the form data to non-identifying fields, the same
+	// way stale applications are archived.
+	rows, err := tx.QueryContext(ctx, `SELECT a.id, a.type, a.data FROM applications a WHERE `+applicationSubjectSQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	type matchedApp struct {
+		id      int64
+		appType string
+		data    []byte
+	}
+	var apps []matchedApp
+	for rows.Next() {
+		var a matchedApp
+		if err := rows.Scan(&a.id, &a.appType, &a.data); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		apps = append(apps, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	appIDs := make([]int64, 0, len(apps))
+	for _, a := range apps {
+		redacted, err := redactApplicationData(a.appType, a.data, false)
+		if err != nil {
+			redacted = []byte(`{"redacted":true}`)
+		}
+		err = exec("applications", `
+			UPDATE applications
+			SET data = $1, original_html = NULL, updated_at = NOW(), version = version + 1
+			WHERE id = $2`, redacted, a.id)
+		if err != nil {
+			return nil, err
+		}
+		appIDs = append(appIDs, a.id)
+		erased["application"] = append(erased["application"], fmt.Sprint(a.id))
+	}
+
+	if len(appIDs) > 0 {
+		if err := exec("contracts", `DELETE FROM contracts WHERE application_id = ANY($1) AND signed_at IS NULL`, pq.Array(appIDs)); err != nil {
+			return nil, err
+		}
+
+		var signed int
+		err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM contracts WHERE application_id = ANY($1)`, pq.Array(appIDs)).Scan(&signed)
+		if err != nil {
+			return nil, err
+		}
+		if signed > 0 {
+			retained["contracts"] = fmt.Sprintf("%d signed adoption contract(s) kept as legal records", signed)
+		}
+	}
+
+	// Volunteers: the person's own record is blanked but kept so shift and
+	// hour history still add up; a matched guardian only loses their fields.
+	err = execReturning("volunteers", "volunteer", `
+		UPDATE volunteers v SET
+			first_name = 'Redacted', last_name = 'Volunteer', email = '', phone = '',
+			address = '', city = '', zip = '', bio = '', photo_url = '', birthday = NULL,
+			emergency_contact_name = '', emergency_contact_phone = '',
+			interest_reason = '', volunteer_experience = '',
+			parent_name = '', parent_email = '', parent_phone = '',
+			status = 'inactive', updated_at = NOW(), version = version + 1
+		WHERE `+volunteerSelfSQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	err = execReturning("volunteerGuardians", "volunteer", `
+		UPDATE volunteers v SET
+			parent_name = '', parent_email = '', parent_phone = '',
+			updated_at = NOW(), version = version + 1
+		WHERE `+volunteerParentSQL, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Foster homes keep their placement history, like volunteers
+	err = execReturning("fosterHomes", "foster_home", `
+		UPDATE foster_homes f SET
+			name = 'Redacted Foster', email = '', phone = '', address = '', city = '', zip = '',
+			other_pets = '', notes = '', status = 'inactive', updated_at = NOW(), version = version + 1
+		WHERE `+fosterSubjectSQL, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Strays keep the found details and hold; only the finder or owner goes
+	err = execReturning("strayIntakes", "stray_intake", `
+		UPDATE stray_intakes s SET
+			finder_name = '', finder_email = '', finder_phone = '', updated_at = NOW(), version = version + 1
+		WHERE `+strayFinderSQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	err = execReturning("strayIntakes", "stray_intake", `
+		UPDATE stray_intakes s SET
+			owner_name = '', owner_contact = '', updated_at = NOW(), version = version + 1
+		WHERE `+strayOwnerSQL, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Returns keep the reason and refund for reporting; the adopter goes
+	err = execReturning("petReturns", "pet_return", `
+		UPDATE pet_returns r SET
+			adopter_name = '', adopter_email = '', adopter_phone = '', updated_at = NOW(), version = version + 1
+		WHERE `+returnAdopterSQL, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pets keep their history; only the adopter/foster contact goes
+	err = exec("pets", `
+		UPDATE pets p SET
+			adoption = CASE WHEN p.adoption ? 'adopterContactInfo'
+				THEN jsonb_set(p.adoption, '{adopterContactInfo}', '{"name": "", "email": "", "phone": ""}') || '{"adoptedBy": ""}'
+				ELSE p.adoption END,
+			foster = CASE WHEN p.foster ? 'fosterContactInfo'
+				THEN jsonb_set(p.foster, '{fosterContactInfo}', '{"name": "", "email": "", "phone": ""}')
+				ELSE p.foster END,
+			version = version + 1
+		WHERE `+petSubjectSQL, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if subject.Email != "" {
+		// The account is anonymised rather than deleted so the audit log and
+		// history that point at it still resolve.
+		var userID string
+		err := tx.QueryRowContext(ctx, `
+			UPDATE users
+			SET name = 'Erased user', email = 'erased-' || id || '@invalid', activated = false, version = version + 1
+			WHERE LOWER(email) = $1
+			RETURNING id`, subject.Email).Scan(&userID)
+		switch {
+		case err == nil:
+			counts["users"] = 1
+			erased["user"] = append(erased["user"], userID)
+			for _, table := range []string{"sessions", "user_tokens", "user_mfa", "user_mfa_recovery_codes", "mfa_challenges"} {
+				if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_id = $1`, userID); err != nil {
+					return nil, fmt.Errorf("%s: %w", table, err)
+				}
+			}
+		case !errors.Is(err, sql.ErrNoRows):
+			return nil, err
+		}
+
+		if err := execReturning("invitations", "invitation", `DELETE FROM invitations WHERE LOWER(email) = $1`, subject.Email); err != nil {
+			return nil, err
+		}
+		if err := exec("loginAttempts", `DELETE FROM login_attempts WHERE email = $1`, subject.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	n, err := eraseAuditLog(ctx, tx, subject, erased)
+	if err != nil {
+		return nil, fmt.Errorf("auditLog: %w", err)
+	}
+	if n > 0 {
+		counts["auditLog"] = n
+		retained["auditLog"] = "audit entries are kept as security records with the subject's details redacted"
+	}
+
+	receipt, err := insertDSR(ctx, tx, DSRErasure, subject, requestedBy, counts, retained)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// auditErased replaces the subject's details in audit entries.
+const auditErased = "[erased]"
+
+// personalAuditFields are changed-field names that hold someone's details.
+var personalAuditFields = []string{"name", "email", "phone", "address", "city", "zip", "birthday",
+	"contact", "bio", "parent", "emergency", "adoptedby", "photo"}
+
+// eraseAuditLog redacts the subject from the audit log: entries about their
+// own records and any entry whose values mention their email or hold their
+// phone number in a phone field. Only
+// the changes column is rewritten; the audit_log trigger allows that, and
+// nothing else, while audit_log.erasure is set for the transaction.
+func eraseAuditLog(ctx context.Context, tx *sql.Tx, subject Subject, erased map[string][]string) (int, error) {
+	var types, ids []string
+	for resourceType, resourceIDs := range erased {
+		for _, id := range resourceIDs {
+			types = append(types, resourceType)
+			ids = append(ids, id)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, EXISTS (
+				SELECT 1 FROM unnest($3::text[], $4::text[]) AS e(resource_type, resource_id)
+				WHERE e.resource_type = a.resource_type AND e.resource_id = a.resource_id
+			), a.changes
+		FROM audit_log a
+		WHERE EXISTS (
+				SELECT 1 FROM unnest($3::text[], $4::text[]) AS e(resource_type, resource_id)
+				WHERE e.resource_type = a.resource_type AND e.resource_id = a.resource_id
+			)
+		OR ($1 <> '' AND strpos(LOWER(a.changes::text), $1) > 0)
+		OR ($2 <> '' AND EXISTS (
+				SELECT 1 FROM jsonb_each(a.changes) AS c(field, change), jsonb_each(c.change) AS s(side, value)
+				WHERE LOWER(regexp_replace(c.field, '^.*\.', '')) LIKE '%phone%'
+				AND jsonb_typeof(s.value) = 'string' AND `+phoneSQL("s.value #>> '{}'")+` = $2
+			))`,
+		subject.Email, subject.Phone, pq.Array(types), pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+
+	type match struct {
+		id      int64
+		changes map[string]AuditChange
+	}
+	var matches []match
+	for rows.Next() {
+		var (
+			m   match
+			own bool
+			raw []byte
+		)
+		if err := rows.Scan(&m.id, &own, &raw); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if err := json.Unmarshal(raw, &m.changes); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if eraseAuditChanges(m.changes, subject, own) {
+			matches = append(matches, m)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(matches) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('audit_log.erasure', 'on', true)`); err != nil {
+		return 0, err
+	}
+	for _, m := range matches {
+		changes, err := json.Marshal(m.changes)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE audit_log SET changes = $1 WHERE id = $2`, changes, m.id); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(matches), nil
+}
+
+// eraseAuditChanges redacts the subject from one entry's changes in place
+// and reports whether anything was redacted. Values mentioning the subject's
+// email, and phone fields holding exactly their number, always go. Personal
+// fields go too when the entry is about the subject's own record (own) or
+// mentions them; on other records only nested ones, so a pet's top-level name
+// survives its adopter's erasure.
+func eraseAuditChanges(changes map[string]AuditChange, subject Subject, own bool) bool {
+	mentions := func(key string, v any) bool {
+		if v == nil {
+			return false
+		}
+		text, isString := v.(string)
+		if !isString {
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return false
+			}
+			text = string(raw)
+		}
+		if subject.Email != "" && strings.Contains(strings.ToLower(text), subject.Email) {
+			return true
+		}
+		// Only a phone field counts, and only the whole number: a chip or
+		// order number can easily contain ten digits in a row
+		field := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+		return subject.Phone != "" && isString && strings.Contains(field, "phone") && NormalizePhone(text) == subject.Phone
+	}
+
+	sensitive := own
+	for key, change := range changes {
+		if mentions(key, change.Before) || mentions(key, change.After) {
+			sensitive = true
+			break
+		}
+	}
+	if !sensitive {
+		return false
+	}
+
+	redacted := false
+	for key, change := range changes {
+		field := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+		personal := (own || strings.Contains(key, ".")) && slices.ContainsFunc(personalAuditFields, func(p string) bool {
+			return strings.Contains(field, p)
+		})
+
+		erase := func(v any) any {
+			if v != nil && v != "" && (personal || mentions(key, v)) {
+				redacted = true
+				return auditErased
+			}
+			return v
+		}
+		changes[key] = AuditChange{Before: erase(change.Before), After: erase(change.After)}
+	}
+
+	return redacted
+}
+
+// RecordExport stores the receipt for an export.
+func (m PrivacyModel) RecordExport(subject Subject, requestedBy string, counts map[string]int) (*DataSubjectRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return insertDSR(ctx, m.DB, DSRExport, subject, requestedBy, counts, nil)
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func insertDSR(ctx context.Context, db queryRower, kind string, subject Subject, requestedBy string, counts map[string]int, retained map[string]string) (*DataSubjectRequest, error) {
+	if retained == nil {
+		retained = map[string]string{}
+	}
+	countsJSON, err := json.Marshal(counts)
+	if err != nil {
+		return nil, err
+	}
+	retainedJSON, err := json.Marshal(retained)
+	if err != nil {
+		return nil, err
+	}
+
+	dsr := &DataSubjectRequest{Kind: kind, Counts: counts, Retained: retained}
+	if requestedBy != "" {
+		dsr.RequestedBy = &requestedBy
+	}
+
+	emailHash, phoneHash := subject.hashes()
+
+	query := `
+		INSERT INTO data_subject_requests (kind, email_hash, phone_hash, requested_by, counts, retained)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	err = db.QueryRowContext(ctx, query, kind, emailHash, phoneHash, dsr.RequestedBy, countsJSON, retainedJSON).Scan(&dsr.ID, &dsr.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return dsr, nil
+}
+
+// GetAll lists receipts newest first, optionally only those for subject.
+func (m PrivacyModel) GetAll(subject Subject, filters Filters) ([]*DataSubjectRequest, Metadata, error) {
+	emailHash, phoneHash := subject.hashes()
+
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), id, kind, requested_by, counts, retained, created_at
+		FROM data_subject_requests
+		WHERE ($1::bytea IS NULL OR email_hash = $1)
+		AND ($2::bytea IS NULL OR phone_hash = $2)
+		ORDER BY %s %s, id DESC
+		LIMIT $3 OFFSET $4`, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, emailHash, phoneHash, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	requests := []*DataSubjectRequest{}
+	for rows.Next() {
+		var dsr DataSubjectRequest
+		var counts, retained []byte
+		if err := rows.Scan(&totalRecords, &dsr.ID, &dsr.Kind, &dsr.RequestedBy, &counts, &retained, &dsr.CreatedAt); err != nil {
+			return nil, Metadata{}, err
+		}
+		if err := json.Unmarshal(counts, &dsr.Counts); err != nil {
+			return nil, Metadata{}, err
+		}
+		if err := json.Unmarshal(retained, &dsr.Retained); err != nil {
+			return nil, Metadata{}, err
+		}
+		requests = append(requests, &dsr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return requests, calculateMetadata(totalRecords, filters.Page, filters.PageSize), nil
+}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewSubject(t *testing.T) {
+	s := NewSubject("  Jane.Doe@Example.COM ", "+1 (555) 010-0000")
+	if s.Email != "jane.doe@example.com" || s.Phone != "5550100000" {
+		t.Errorf("NewSubject = %+v", s)
+	}
+	if NormalizePhone("555-0100") != "5550100" {
+		t.Error("short numbers should keep all digits")
+	}
+}
+
+func TestRedactApplicationData(t *testing.T) {
+	raw := []byte(`{"firstName":"Jane","lastName":"Doe","email":"jane@example.com","phoneNumber":"555","animalName":"Miso","animalAge":"2"}`)
+
+	archived, err := redactApplicationData("surrender", raw, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept map[string]any
+	json.Unmarshal(archived, &kept)
+	if kept["email"] != "jane@example.com" || kept["animalName"] != "Miso" || kept["phoneNumber"] != nil {
+		t.Errorf("archived = %s", archived)
+	}
+
+	erased, err := redactApplicationData("surrender", raw, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gone map[string]any
+	json.Unmarshal(erased, &gone)
+	if gone["firstName"] != nil || gone["email"] != nil || gone["redacted"] != true || gone["animalName"] != "Miso" {
+		t.Errorf("erased = %s", erased)
+	}
+}
+
+func TestEraseAuditChanges(t *testing.T) {
+	subject := NewSubject("jane@example.com", "555-010-0000")
+
+	// The volunteer's own record: personal fields go, the rest stays
+	own := map[string]AuditChange{
+		"firstName": {Before: "Jane", After: "Janet"},
+		"status":    {Before: "pending", After: "active"},
+	}
+	if !eraseAuditChanges(own, subject, true) {
+		t.Fatal("own record should be redacted")
+	}
+	if own["firstName"].Before != auditErased || own["firstName"].After != auditErased || own["status"].After != "active" {
+		t.Errorf("own = %#v", own)
+	}
+
+	// A pet adopted by the subject: the contact goes, the pet's name stays
+	pet := map[string]AuditChange{
+		"name":                              {Before: "Miso", After: "Miso Soup"},
+		"adoption.adopterContactInfo.email": {Before: nil, After: "Jane@Example.com"},
+		"adoption.adopterContactInfo.name":  {Before: nil, After: "Jane Doe"},
+		"adoption.adopterContactInfo.phone": {Before: "", After: "(555) 010-0000"},
+		"adoption.adoptedBy":                {Before: nil, After: "Jane Doe"},
+	}
+	if !eraseAuditChanges(pet, subject, false) {
+		t.Fatal("pet entry mentioning the subject should be redacted")
+	}
+	if pet["name"].After != "Miso Soup" {
+		t.Errorf("pet name should survive: %#v", pet["name"])
+	}
+	for _, key := range []string{"adoption.adopterContactInfo.email", "adoption.adopterContactInfo.name",
+		"adoption.adopterContactInfo.phone", "adoption.adoptedBy"} {
+		if pet[key].After != auditErased || pet[key].Before == auditErased {
+			t.Errorf("%s = %#v", key, pet[key])
+		}
+	}
+
+	// Someone else's record that never mentions the subject is left alone
+	other := map[string]AuditChange{"email": {Before: "bob@example.com", After: "rob@example.com"}}
+	if eraseAuditChanges(other, subject, false) || other["email"].After != "rob@example.com" {
+		t.Errorf("other = %#v", other)
+	}
+
+	// Digits that merely contain the number, or sit outside a phone field,
+	// aren't the subject's phone
+	lookalike := map[string]AuditChange{
+		"microchip.number": {Before: "", After: "985555010000012"},
+		"notes":            {Before: nil, After: "Order 5550100000 shipped"},
+		"phone":            {Before: "", After: "(555) 010-00001"},
+	}
+	if eraseAuditChanges(lookalike, subject, false) {
+		t.Errorf("lookalike = %#v", lookalike)
+	}
+}
@@ -0,0 +1,512 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+const (
+	ReturnBehavior      = "behavior"
+	ReturnHealth        = "health"
+	ReturnAllergies     = "allergies"
+	ReturnHousing       = "housing"
+	ReturnOtherPets     = "other_pets"
+	ReturnChildren      = "children"
+	ReturnTime          = "time"
+	ReturnCost          = "cost"
+	ReturnAdopterHealth = "adopter_health"
+	ReturnOther         = "other"
+)
+
+var ReturnReasons = []string{
+	ReturnBehavior, ReturnHealth, ReturnAllergies, ReturnHousing, ReturnOtherPets,
+	ReturnChildren, ReturnTime, ReturnCost, ReturnAdopterHealth, ReturnOther,
+}
+
+var ErrNotAdopted = errors.New("pet is not adopted")
+
+// RefundPolicy holds the refund windows from the adoption contract, in days
+// after adoption: everything back inside the trial, everything back for a
+// health problem inside HealthDays, half back inside HalfRefundDays. A zero
+// window doesn't apply. They come from configuration; with none set every
+// refund is left to staff.
+type RefundPolicy struct {
+	TrialDays      int
+	HealthDays     int
+	HalfRefundDays int
+}
+
+// Configured reports whether any refund window is set.
+func (p RefundPolicy) Configured() bool {
+	return p.TrialDays > 0 || p.HealthDays > 0 || p.HalfRefundDays > 0
+}
+
+// RefundFor applies the refund policy to an adoption fee. daysKept is nil
+// when the adoption date isn't known, which gets no refund.
+func (p RefundPolicy) RefundFor(fee int, daysKept *int, reason string) (refund int, policy string) {
+	switch {
+	case fee <= 0:
+		return 0, "No adoption fee was paid"
+	case !p.Configured():
+		return 0, "No refund policy configured; refund at staff discretion"
+	case daysKept == nil:
+		return 0, "Adoption date unknown; refund at staff discretion"
+	case p.TrialDays > 0 && *daysKept <= p.TrialDays:
+		return fee, fmt.Sprintf("Full refund: returned within the %d-day trial", p.TrialDays)
+	case p.HealthDays > 0 && reason == ReturnHealth && *daysKept <= p.HealthDays:
+		return fee, fmt.Sprintf("Full refund: health problem within %d days", p.HealthDays)
+	case p.HalfRefundDays > 0 && *daysKept <= p.HalfRefundDays:
+		return fee / 2, fmt.Sprintf("Half refund: returned within %d days", p.HalfRefundDays)
+	}
+	return 0, fmt.Sprintf("No refund: returned after %d days, outside the refund windows", *daysKept)
+}
+
+// PetReturn is one adoption that came back. The adopter and adoption are
+// copied from the pet's adoption block when the return is processed.
+type PetReturn struct {
+	ID            int64     `json:"id"`
+	PetID         string    `json:"petId"`
+	PetName       string    `json:"petName"`
+	ApplicationID *int64    `json:"applicationId"`
+	AdopterName   string    `json:"adopterName"`
+	AdopterEmail  string    `json:"adopterEmail"`
+	AdopterPhone  string    `json:"adopterPhone"`
+	AdoptionDate  *string   `json:"adoptionDate"`
+	ReturnDate    string    `json:"returnDate"`
+	DaysKept      *int      `json:"daysKept"`
+	Reason        string    `json:"reason"`
+	Details       string    `json:"details"`
+	Fee           int       `json:"fee"`
+	Refund        int       `json:"refund"`
+	RefundPolicy  string    `json:"refundPolicy"`
+	FlagAdopter   bool      `json:"flagAdopter"`
+	ProcessedBy   *string   `json:"processedBy,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	Version       int       `json:"version"`
+}
+
+// FromAdoption fills in the adopter, adoption date, days kept and fee from
+// the pet's adoption block.
+func (ret *PetReturn) FromAdoption(adoption json.RawMessage) {
+	var a Adoption
+	_ = json.Unmarshal(adoption, &a)
+
+	ret.AdopterName = a.AdopterContactInfo.Name
+	if ret.AdopterName == "" {
+		ret.AdopterName = a.AdoptedBy
+	}
+	ret.AdopterEmail = a.AdopterContactInfo.Email
+	ret.AdopterPhone = a.AdopterContactInfo.Phone
+	if a.Fee != nil {
+		ret.Fee = *a.Fee
+	}
+
+	ret.AdoptionDate, ret.DaysKept = nil, nil
+	if a.Date == nil {
+		return
+	}
+	adopted, ok := ParseRecordDate(*a.Date)
+	if !ok {
+		return
+	}
+	date := adopted.Format(time.DateOnly)
+	ret.AdoptionDate = &date
+	if returned, err := time.Parse(time.DateOnly, ret.ReturnDate); err == nil && !returned.Before(adopted) {
+		days := int(returned.Sub(adopted).Hours() / 24)
+		ret.DaysKept = &days
+	}
+}
+
+func ValidatePetReturn(v *validator.Validator, ret *PetReturn) {
+	v.Check(validator.PermittedValue(ret.Reason, ReturnReasons...), "reason", "must be a known return reason")
+	v.Check(ret.Reason != ReturnOther || ret.Details != "", "details", "must be provided when the reason is other")
+	v.Check(len(ret.Details) <= 5000, "details", "must be 5000 characters or fewer")
+
+	returned, err := time.Parse(time.DateOnly, ret.ReturnDate)
+	v.Check(err == nil, "returnDate", "must be a YYYY-MM-DD date")
+	if err == nil {
+		v.Check(!returned.After(time.Now()), "returnDate", "must not be in the future")
+		if ret.AdoptionDate != nil {
+			adopted, err := time.Parse(time.DateOnly, *ret.AdoptionDate)
+			v.Check(err != nil || !returned.Before(adopted), "returnDate", "must not be before the adoption date")
+		}
+	}
+
+	v.Check(ret.Refund >= 0, "refund", "must not be negative")
+	v.Check(ret.Refund <= ret.Fee, "refund", "must not be more than the adoption fee")
+}
+
+// ReturnFlag is what an application reviewer sees about an applicant's
+// earlier returns.
+type ReturnFlag struct {
+	PetName    string `json:"petName"`
+	ReturnDate string `json:"returnDate"`
+	Reason     string `json:"reason"`
+	DaysKept   *int   `json:"daysKept"`
+}
+
+type PetReturnModel struct {
+	DB *sql.DB
+}
+
+const returnColumns = `r.id, r.pet_id, COALESCE(p.name, ''), r.application_id, r.adopter_name, r.adopter_email, r.adopter_phone,
+	to_char(r.adoption_date, 'YYYY-MM-DD'), to_char(r.return_date, 'YYYY-MM-DD'), r.days_kept, r.reason, r.details,
+	r.fee, r.refund, r.refund_policy, r.flag_adopter, r.processed_by, r.created_at, r.updated_at, r.version`
+
+const returnJoins = `
+	FROM pet_returns r
+	LEFT JOIN pets p ON p.id::text = r.pet_id`
+
+func scanReturn(row interface{ Scan(...any) error }, extra ...any) (*PetReturn, error) {
+	var r PetReturn
+	dest := append(extra, &r.ID, &r.PetID, &r.PetName, &r.ApplicationID, &r.AdopterName, &r.AdopterEmail, &r.AdopterPhone,
+		&r.AdoptionDate, &r.ReturnDate, &r.DaysKept, &r.Reason, &r.Details,
+		&r.Fee, &r.Refund, &r.RefundPolicy, &r.FlagAdopter, &r.ProcessedBy, &r.CreatedAt, &r.UpdatedAt, &r.Version)
+
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Process records a return and re-opens the pet in one transaction: the
+// pet goes back to intake as of the return date and its returned block gains
+// this return. The adoption block stays, so the adoption still counts in the
+// forecast and return-rate history until the next one replaces it. The pet
+// must still be adopted, which also stops the same return being processed
+// twice.
+func (m PetReturnModel) Process(ret *PetReturn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	history, err := json.Marshal(map[string]string{"date": ret.ReturnDate, "reason": ret.Reason})
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE pets
+		SET status = 'intake',
+			details = COALESCE(details, '{}'::jsonb) || jsonb_build_object('status', 'intake', 'intakeDate', $2::text),
+			returned = jsonb_build_object(
+				'isReturned', true, 'date', $2::text, 'reason', $3::text,
+				'history', COALESCE(returned->'history', '[]'::jsonb) || jsonb_build_array($4::jsonb)),
+			updated_at = NOW(), version = version + 1
+		WHERE id::text = $1 AND LOWER(status) = 'adopted'`, ret.PetID, ret.ReturnDate, ret.Reason, history)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotAdopted
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO pet_returns (pet_id, application_id, adopter_name, adopter_email, adopter_phone, adoption_date,
+			return_date, days_kept, reason, details, fee, refund, refund_policy, flag_adopter, processed_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id`,
+		ret.PetID, ret.ApplicationID, ret.AdopterName, ret.AdopterEmail, ret.AdopterPhone, ret.AdoptionDate,
+		ret.ReturnDate, ret.DaysKept, ret.Reason, ret.Details, ret.Fee, ret.Refund, ret.RefundPolicy, ret.FlagAdopter, ret.ProcessedBy).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	saved, err := scanReturn(tx.QueryRowContext(ctx, `SELECT `+returnColumns+returnJoins+` WHERE r.id = $1`, id))
+	if err != nil {
+		return err
+	}
+	*ret = *saved
+
+	return tx.Commit()
+}
+
+func (m PetReturnModel) Get(id int64) (*PetReturn, error) {
+	query := `SELECT ` + returnColumns + returnJoins + ` WHERE r.id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanReturn(m.DB.QueryRowContext(ctx, query, id))
+}
+
+// GetAll lists returns, newest first. petID and reason narrow the list when
+// set.
+func (m PetReturnModel) GetAll(petID, reason string, filters Filters) ([]*PetReturn, Metadata, error) {
+	query := `
+		SELECT count(*) OVER(), ` + returnColumns + returnJoins + `
+		WHERE ($1 = '' OR r.pet_id = $1) AND ($2 = '' OR r.reason = $2)
+		ORDER BY r.return_date DESC, r.id DESC
+		LIMIT $3 OFFSET $4`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, petID, reason, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	returns := []*PetReturn{}
+	for rows.Next() {
+		r, err := scanReturn(rows, &totalRecords)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		returns = append(returns, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return returns, calculateMetadata(totalRecords, filters.Page, filters.PageSize), nil
+}
+
+// Update edits the reason, details, refund and flag after the fact.
+func (m PetReturnModel) Update(r *PetReturn) error {
+	query := `
+		UPDATE pet_returns
+		SET reason = $1, details = $2, refund = $3, refund_policy = $4, flag_adopter = $5, application_id = $6,
+			updated_at = NOW(), version = version + 1
+		WHERE id = $7 AND version = $8
+		RETURNING updated_at, version`
+
+	args := []any{r.Reason, r.Details, r.Refund, r.RefundPolicy, r.FlagAdopter, r.ApplicationID, r.ID, r.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&r.UpdatedAt, &r.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// AdoptionApplication finds the approved adoption application most likely
+// behind an adoption: the adopter's latest one from before the adoption date.
+func (m PetReturnModel) AdoptionApplication(email string, adoptionDate *string) (*int64, error) {
+	if email == "" {
+		return nil, nil
+	}
+
+	query := `
+		SELECT a.id FROM applications a
+		WHERE a.type = 'adoption' AND a.status = 'approved'
+			AND LOWER(COALESCE(a.data->>'email', '')) = LOWER($1)
+			AND ($2::date IS NULL OR a.created_at::date <= $2::date)
+		ORDER BY a.created_at DESC
+		LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var id int64
+	err := m.DB.QueryRowContext(ctx, query, email, adoptionDate).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &id, nil
+}
+
+// Flags lists the flagged returns made by the applicant with this email or
+// phone, newest first.
+func (m PetReturnModel) Flags(s Subject) ([]ReturnFlag, error) {
+	flags := []ReturnFlag{}
+	if s.Email == "" && s.Phone == "" {
+		return flags, nil
+	}
+
+	query := `
+		SELECT COALESCE(p.name, ''), to_char(r.return_date, 'YYYY-MM-DD'), r.reason, r.days_kept` + returnJoins + `
+		WHERE r.flag_adopter AND ` + returnAdopterSQL + `
+		ORDER BY r.return_date DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, s.Email, s.Phone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f ReturnFlag
+		if err := rows.Scan(&f.PetName, &f.ReturnDate, &f.Reason, &f.DaysKept); err != nil {
+			return nil, err
+		}
+		flags = append(flags, f)
+	}
+
+	return flags, rows.Err()
+}
+
+// AdoptedSince lists the returns of adoptions made on or after from, for
+// reporting.
+func (m PetReturnModel) AdoptedSince(from time.Time) ([]*PetReturn, error) {
+	query := `SELECT ` + returnColumns + returnJoins + ` WHERE r.adoption_date >= $1 ORDER BY r.adoption_date`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, from.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := []*PetReturn{}
+	for rows.Next() {
+		r, err := scanReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, r)
+	}
+
+	return returns, rows.Err()
+}
+
+// ReturnPeriod is one row of the return-rate report.
+type ReturnPeriod struct {
+	Period    string         `json:"period"`
+	Adoptions int            `json:"adoptions"`
+	Returns   int            `json:"returns"`
+	Rate      float64        `json:"rate"` // percent of adoptions that came back
+	ByReason  map[string]int `json:"byReason"`
+}
+
+// ReturnReasonTotal sums one reason across the report.
+type ReturnReasonTotal struct {
+	Reason      string  `json:"reason"`
+	Returns     int     `json:"returns"`
+	Share       float64 `json:"share"` // percent of all returns
+	AvgDaysKept float64 `json:"avgDaysKept"`
+	Refunded    int     `json:"refunded"`
+}
+
+// ReturnReport groups returns into periods ("month", "quarter" or "year")
+// by the date of the adoption they undid, so a period's rate is the share
+// of its adoptions that came back. adoptions are the adoption dates of pets
+// still adopted; returned adoptions are counted from the returns.
+func ReturnReport(returns []*PetReturn, adoptions []time.Time, from, to time.Time, interval string) ([]ReturnPeriod, []ReturnReasonTotal) {
+	key := func(t time.Time) string {
+		switch interval {
+		case "year":
+			return fmt.Sprintf("%d", t.Year())
+		case "quarter":
+			return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+		}
+		return t.Format("2006-01")
+	}
+	inRange := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+
+	periods := map[string]*ReturnPeriod{}
+	var order []string
+	for d := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); d.Before(to); d = d.AddDate(0, 1, 0) {
+		k := key(d)
+		if periods[k] == nil {
+			periods[k] = &ReturnPeriod{Period: k, ByReason: map[string]int{}}
+			order = append(order, k)
+		}
+	}
+
+	for _, t := range adoptions {
+		if inRange(t) {
+			periods[key(t)].Adoptions++
+		}
+	}
+
+	totals := map[string]*ReturnReasonTotal{}
+	daysKept := map[string][2]int{}
+	all := 0
+	for _, r := range returns {
+		if r.AdoptionDate == nil {
+			continue
+		}
+		adopted, err := time.Parse(time.DateOnly, *r.AdoptionDate)
+		if err != nil || !inRange(adopted) {
+			continue
+		}
+
+		p := periods[key(adopted)]
+		p.Adoptions++
+		p.Returns++
+		p.ByReason[r.Reason]++
+
+		t := totals[r.Reason]
+		if t == nil {
+			t = &ReturnReasonTotal{Reason: r.Reason}
+			totals[r.Reason] = t
+		}
+		t.Returns++
+		t.Refunded += r.Refund
+		if r.DaysKept != nil {
+			d := daysKept[r.Reason]
+			daysKept[r.Reason] = [2]int{d[0] + *r.DaysKept, d[1] + 1}
+		}
+		all++
+	}
+
+	report := make([]ReturnPeriod, 0, len(order))
+	for _, k := range order {
+		p := periods[k]
+		if p.Adoptions > 0 {
+			p.Rate = roundTenth(float64(p.Returns) * 100 / float64(p.Adoptions))
+		}
+		report = append(report, *p)
+	}
+
+	reasons := make([]ReturnReasonTotal, 0, len(totals))
+	for reason, t := range totals {
+		t.Share = roundTenth(float64(t.Returns) * 100 / float64(all))
+		if d := daysKept[reason]; d[1] > 0 {
+			t.AvgDaysKept = roundTenth(float64(d[0]) / float64(d[1]))
+		}
+		reasons = append(reasons, *t)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if reasons[i].Returns != reasons[j].Returns {
+			return reasons[i].Returns > reasons[j].Returns
+		}
+		return reasons[i].Reason < reasons[j].Reason
+	})
+
+	return report, reasons
+}
+
+func roundTenth(f float64) float64 {
+	return math.Round(f*10) / 10
+}
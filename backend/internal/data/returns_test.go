@@ -0,0 +1,126 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRefundFor(t *testing.T) {
+	days := func(n int) *int { return &n }
+	policy := RefundPolicy{TrialDays: 3, HealthDays: 30, HalfRefundDays: 14}
+
+	tests := []struct {
+		name   string
+		fee    int
+		kept   *int
+		reason string
+		want   int
+	}{
+		{"trial", 150, days(2), ReturnBehavior, 150},
+		{"health in first month", 150, days(25), ReturnHealth, 150},
+		{"first two weeks", 150, days(10), ReturnAllergies, 75},
+		{"after two weeks", 150, days(20), ReturnAllergies, 0},
+		{"health after a month", 150, days(45), ReturnHealth, 0},
+		{"unknown adoption date", 150, nil, ReturnHealth, 0},
+		{"no fee", 0, days(1), ReturnBehavior, 0},
+	}
+
+	for _, tt := range tests {
+		got, why := policy.RefundFor(tt.fee, tt.kept, tt.reason)
+		if got != tt.want {
+			t.Errorf("%s: refund = %d (%s); want %d", tt.name, got, why, tt.want)
+		}
+		if why == "" {
+			t.Errorf("%s: missing policy", tt.name)
+		}
+	}
+
+	// Without configured windows nothing is refunded automatically
+	if got, _ := (RefundPolicy{}).RefundFor(150, days(1), ReturnBehavior); got != 0 {
+		t.Errorf("unconfigured policy refunded %d", got)
+	}
+	// A window left at zero doesn't apply
+	if got, _ := (RefundPolicy{HalfRefundDays: 14}).RefundFor(150, days(2), ReturnBehavior); got != 75 {
+		t.Errorf("half-only policy refunded %d; want 75", got)
+	}
+}
+
+func TestPetReturnFromAdoption(t *testing.T) {
+	ret := &PetReturn{ReturnDate: "2025-04-11"}
+	ret.FromAdoption(json.RawMessage(`{"date": "4/1/2025", "fee": 125, "adopterContactInfo": {"name": "Sam Lee", "email": "sam@example.com", "phone": "555-0100"}}`))
+
+	if ret.AdoptionDate == nil || *ret.AdoptionDate != "2025-04-01" {
+		t.Fatalf("AdoptionDate = %v; want 2025-04-01", ret.AdoptionDate)
+	}
+	if ret.DaysKept == nil || *ret.DaysKept != 10 {
+		t.Errorf("DaysKept = %v; want 10", ret.DaysKept)
+	}
+	if ret.Fee != 125 || ret.AdopterName != "Sam Lee" || ret.AdopterEmail != "sam@example.com" {
+		t.Errorf("got fee %d, adopter %q <%s>", ret.Fee, ret.AdopterName, ret.AdopterEmail)
+	}
+}
+
+func TestReturnReport(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return d
+	}
+	date := func(s string) *string { return &s }
+	kept := 5
+
+	adoptions := []time.Time{day("2025-01-05"), day("2025-01-20"), day("2025-01-28"), day("2025-02-14"), day("2024-12-31")}
+	returns := []*PetReturn{
+		{AdoptionDate: date("2025-01-10"), Reason: ReturnBehavior, DaysKept: &kept, Refund: 50},
+		{AdoptionDate: date("2025-02-01"), Reason: ReturnAllergies},
+		{AdoptionDate: date("2025-02-03"), Reason: ReturnBehavior},
+		{AdoptionDate: date("2024-11-01"), Reason: ReturnBehavior},
+	}
+
+	periods, reasons := ReturnReport(returns, adoptions, day("2025-01-01"), day("2025-03-01"), "month")
+
+	if len(periods) != 2 {
+		t.Fatalf("got %d periods; want 2", len(periods))
+	}
+	jan, feb := periods[0], periods[1]
+	if jan.Period != "2025-01" || jan.Adoptions != 4 || jan.Returns != 1 || jan.Rate != 25 {
+		t.Errorf("January = %+v", jan)
+	}
+	if feb.Adoptions != 3 || feb.Returns != 2 || feb.ByReason[ReturnAllergies] != 1 {
+		t.Errorf("February = %+v", feb)
+	}
+
+	if len(reasons) != 2 || reasons[0].Reason != ReturnBehavior || reasons[0].Returns != 2 {
+		t.Fatalf("reasons = %+v", reasons)
+	}
+	if reasons[0].AvgDaysKept != 5 || reasons[0].Refunded != 50 {
+		t.Errorf("behavior totals = %+v", reasons[0])
+	}
+
+	quarters, _ := ReturnReport(returns, adoptions, day("2025-01-01"), day("2025-03-01"), "quarter")
+	if len(quarters) != 1 || quarters[0].Period != "2025-Q1" || quarters[0].Returns != 3 {
+		t.Errorf("quarters = %+v", quarters)
+	}
+}
+
+func TestAdoptedBeforeReturn(t *testing.T) {
+	returned := json.RawMessage(`{"isReturned": true, "date": "2026-03-10"}`)
+
+	tests := []struct {
+		date     string
+		returned json.RawMessage
+		want     bool
+	}{
+		{"1/15/2026", returned, true},
+		{"2026-03-10", returned, false}, // re-adopted the day it came back
+		{"2026-04-01", returned, false},
+		{"1/15/2026", json.RawMessage(`{}`), false},
+		{"1/15/2026", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := adoptedBeforeReturn(tt.date, tt.returned); got != tt.want {
+			t.Errorf("adoptedBeforeReturn(%q, %s) = %v, want %v", tt.date, tt.returned, got, tt.want)
+		}
+	}
+}
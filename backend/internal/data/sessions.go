@@ -9,19 +9,38 @@ import (
 	"time"
 )
 
+// Session lifetime policy. Expiry slides forward while the session is used;
+// the token itself is rotated periodically, and no session outlives MaxAge.
+const (
+	SessionIdleTTL     = 24 * time.Hour
+	SessionMaxAge      = 30 * 24 * time.Hour
+	SessionRotateAfter = 1 * time.Hour
+	// SessionRotationGrace keeps the old token working briefly so requests
+	// already in flight when it rotated don't fail.
+	SessionRotationGrace = 2 * time.Minute
+	// SessionTouchInterval throttles last-seen/expiry writes.
+	SessionTouchInterval = 5 * time.Minute
+)
+
 type Session struct {
-	Token     string
-	UserID    string
-	Expiry    time.Time
-	IP        string
-	UserAgent string
+	ID        int64     `json:"id"`
+	Token     string    `json:"-"`
+	UserID    string    `json:"-"`
+	Expiry    time.Time `json:"expiry"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	// MFAVerified is set when the login completed a second factor
+	MFAVerified bool      `json:"mfaVerified"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastSeenAt  time.Time `json:"lastSeenAt"`
+	RotatedAt   time.Time `json:"-"`
 }
 
 type SessionModel struct {
 	DB *sql.DB
 }
 
-func (m SessionModel) Insert(userID string, ttl time.Duration, ip, userAgent string) (string, error) {
+func (m SessionModel) Insert(userID string, ttl time.Duration, ip, userAgent string, mfaVerified bool) (string, error) {
 	token, err := generateToken()
 	if err != nil {
 		return "", err
@@ -30,13 +49,13 @@ func (m SessionModel) Insert(userID string, ttl time.Duration, ip, userAgent str
 	expiry := time.Now().Add(ttl)
 
 	query := `
-		INSERT INTO sessions (token, user_id, expiry, ip, user_agent)
-		VALUES ($1, $2, $3, $4, $5)`
+		INSERT INTO sessions (token, user_id, expiry, ip, user_agent, mfa_verified)
+		VALUES ($1, $2, $3, $4, $5, $6)`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err = m.DB.ExecContext(ctx, query, token, userID, expiry, ip, userAgent)
+	_, err = m.DB.ExecContext(ctx, query, token, userID, expiry, ip, userAgent, mfaVerified)
 	if err != nil {
 		return "", err
 	}
@@ -44,25 +63,37 @@ func (m SessionModel) Insert(userID string, ttl time.Duration, ip, userAgent str
 	return token, nil
 }
 
-func (m SessionModel) Get(token string) (*Session, error) {
-	query := `
-		SELECT token, user_id, expiry, ip, user_agent
-		FROM sessions
-		WHERE token = $1`
-
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+const sessionColumns = `id, token, user_id, expiry, host(ip), user_agent, mfa_verified, created_at, last_seen_at, rotated_at`
 
+func scanSession(row interface{ Scan(...any) error }) (*Session, error) {
 	var s Session
-
-	err := m.DB.QueryRowContext(ctx, query, token).Scan(
+	err := row.Scan(
+		&s.ID,
 		&s.Token,
 		&s.UserID,
 		&s.Expiry,
 		&s.IP,
 		&s.UserAgent,
+		&s.MFAVerified,
+		&s.CreatedAt,
+		&s.LastSeenAt,
+		&s.RotatedAt,
 	)
+	return &s, err
+}
+
+// Get looks a session up by its current token, or by the token it replaced
+// while that is still inside the rotation grace period.
+func (m SessionModel) Get(token string) (*Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE token = $1 OR (previous_token = $1 AND previous_valid_until > NOW())`
 
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s, err := scanSession(m.DB.QueryRowContext(ctx, query, token))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Return nil if no valid session found
@@ -71,11 +102,172 @@ func (m SessionModel) Get(token string) (*Session, error) {
 	}
 
 	// Check expiry in Go to avoid DB clock skew issues
-	if s.Expiry.Before(time.Now()) {
+	if s.Expiry.Before(time.Now()) || time.Since(s.CreatedAt) > SessionMaxAge {
 		return nil, nil // Expired
 	}
 
-	return &s, nil
+	return s, nil
+}
+
+func (m SessionModel) GetByID(id int64) (*Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s, err := scanSession(m.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if s.Expiry.Before(time.Now()) || time.Since(s.CreatedAt) > SessionMaxAge {
+		return nil, nil
+	}
+
+	return s, nil
+}
+
+// GetAllForUser lists the user's live sessions, most recently used first.
+func (m SessionModel) GetAllForUser(userID string) ([]*Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = $1 AND expiry > NOW() AND created_at > $2
+		ORDER BY last_seen_at DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, time.Now().Add(-SessionMaxAge))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Touch records activity and slides the expiry forward, never past MaxAge.
+func (m SessionModel) Touch(s *Session) error {
+	query := `UPDATE sessions SET last_seen_at = NOW(), expiry = $2 WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, s.ID, slidingExpiry(s.CreatedAt))
+	return err
+}
+
+// Rotate swaps in a new token and slides the expiry. The old token keeps
+// working for SessionRotationGrace. It returns "" if another request already
+// rotated the session.
+func (m SessionModel) Rotate(s *Session) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		UPDATE sessions
+		SET token = $1, previous_token = token, previous_valid_until = $2,
+			rotated_at = NOW(), last_seen_at = NOW(), expiry = $3
+		WHERE id = $4 AND token = $5`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, token, time.Now().Add(SessionRotationGrace), slidingExpiry(s.CreatedAt), s.ID, s.Token)
+	if err != nil {
+		return "", err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func slidingExpiry(createdAt time.Time) time.Time {
+	expiry := time.Now().Add(SessionIdleTTL)
+	if limit := createdAt.Add(SessionMaxAge); expiry.After(limit) {
+		return limit
+	}
+	return expiry
+}
+
+// MarkMFAVerified upgrades a session after the user enrolls in MFA mid-session.
+func (m SessionModel) MarkMFAVerified(token string) error {
+	query := `UPDATE sessions SET mfa_verified = true WHERE token = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, token)
+	return err
+}
+
+// Delete revokes one of the user's sessions. It returns false if no such
+// session belongs to them.
+func (m SessionModel) Delete(userID string, id int64) (bool, error) {
+	query := `DELETE FROM sessions WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// DeleteByToken ends the session presented at logout.
+func (m SessionModel) DeleteByToken(token string) error {
+	query := `DELETE FROM sessions WHERE token = $1 OR previous_token = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, token)
+	return err
+}
+
+// DeleteAllForUser logs the user out everywhere except exceptToken (pass ""
+// to include every session).
+func (m SessionModel) DeleteAllForUser(userID, exceptToken string) error {
+	query := `DELETE FROM sessions WHERE user_id = $1 AND token <> $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID, exceptToken)
+	return err
+}
+
+func (m SessionModel) DeleteExpired(ctx context.Context) error {
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM sessions WHERE expiry < NOW() OR created_at < $1`, time.Now().Add(-SessionMaxAge))
+	return err
 }
 
 func generateToken() (string, error) {
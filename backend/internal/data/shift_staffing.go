@@ -0,0 +1,257 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+type StaffingRule struct {
+	ID            int64     `json:"id"`
+	Role          string    `json:"role"`
+	DayPart       string    `json:"dayPart"`
+	MinVolunteers int       `json:"minVolunteers"`
+	Capacity      int       `json:"capacity"` // 0 = unlimited
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type StaffingGap struct {
+	Date      string `json:"date"`
+	DayPart   string `json:"dayPart"`
+	Role      string `json:"role"`
+	Required  int    `json:"required"`
+	Scheduled int    `json:"scheduled"`
+	Shortfall int    `json:"shortfall"`
+}
+
+type StaffingRuleModel struct {
+	DB *sql.DB
+}
+
+func (m StaffingRuleModel) GetAll() ([]*StaffingRule, error) {
+	query := `
+		SELECT id, role, day_part, min_volunteers, capacity, updated_at
+		FROM shift_staffing_rules
+		ORDER BY role ASC, day_part ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*StaffingRule{}
+	for rows.Next() {
+		var rule StaffingRule
+		err := rows.Scan(&rule.ID, &rule.Role, &rule.DayPart, &rule.MinVolunteers, &rule.Capacity, &rule.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, &rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Upsert creates or replaces the rule for a role/day-part pair.
+func (m StaffingRuleModel) Upsert(rule *StaffingRule) error {
+	query := `
+		INSERT INTO shift_staffing_rules (role, day_part, min_volunteers, capacity)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (role, day_part) DO UPDATE
+		SET min_volunteers = EXCLUDED.min_volunteers,
+			capacity = EXCLUDED.capacity,
+			updated_at = NOW()
+		RETURNING id, updated_at`
+
+	args := []any{rule.Role, rule.DayPart, rule.MinVolunteers, rule.Capacity}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&rule.ID, &rule.UpdatedAt)
+}
+
+func (m StaffingRuleModel) Delete(id int64) error {
+	query := `
+		DELETE FROM shift_staffing_rules
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ParseShiftTime accepts both the 24h ("13:00") and 12h ("1:00 PM") formats
+// that have been stored in shifts.start_time/end_time over time.
+func ParseShiftTime(s string) (time.Time, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		t, err = time.Parse("3:04 PM", s)
+	}
+	return t, err
+}
+
+// ShiftDayPart buckets a shift into morning (before noon), afternoon (noon to
+// 5pm) or evening based on its start time.
+func ShiftDayPart(startTime string) string {
+	t, err := ParseShiftTime(startTime)
+	if err != nil {
+		return "morning"
+	}
+
+	switch {
+	case t.Hour() < 12:
+		return "morning"
+	case t.Hour() < 17:
+		return "afternoon"
+	default:
+		return "evening"
+	}
+}
+
+// CountsTowardStaffing reports whether a shift in this status still occupies
+// a slot on the schedule.
+func CountsTowardStaffing(status string) bool {
+	return !IsPermittedValue(status, NonStaffingShiftStatuses...)
+}
+
+// shiftWindow returns the start/end of a shift as minutes since midnight.
+// Shifts that end at or before they start are treated as running past midnight.
+func shiftWindow(s *Shift) (int, int, bool) {
+	start, err := ParseShiftTime(s.StartTime)
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err := ParseShiftTime(s.EndTime)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+	if endMin <= startMin {
+		endMin += 24 * 60
+	}
+
+	return startMin, endMin, true
+}
+
+// ShiftsOverlap reports whether two shifts on the same date overlap in time.
+// Back-to-back shifts (one ends at 12:00, the next starts at 12:00) do not overlap.
+func ShiftsOverlap(a, b *Shift) bool {
+	if a.Date != b.Date {
+		return false
+	}
+
+	aStart, aEnd, ok := shiftWindow(a)
+	if !ok {
+		return false
+	}
+	bStart, bEnd, ok := shiftWindow(b)
+	if !ok {
+		return false
+	}
+
+	return aStart < bEnd && bStart < aEnd
+}
+
+// FindShiftConflicts returns the shifts in existing that double-book the
+// volunteer assigned to candidate. The candidate itself is ignored by ID.
+func FindShiftConflicts(candidate *Shift, existing []*Shift) []*Shift {
+	conflicts := []*Shift{}
+	// An open slot has no volunteer to double-book.
+	if candidate.VolunteerID == 0 {
+		return conflicts
+	}
+	for _, s := range existing {
+		if s.ID == candidate.ID || s.VolunteerID != candidate.VolunteerID {
+			continue
+		}
+		if !CountsTowardStaffing(s.Status) {
+			continue
+		}
+		if ShiftsOverlap(candidate, s) {
+			conflicts = append(conflicts, s)
+		}
+	}
+	return conflicts
+}
+
+// CountRoleSlot counts the active, filled shifts (excluding excludeID) for a
+// role in the given date and day-part. Open slots staff nobody.
+func CountRoleSlot(shifts []*Shift, date, role, dayPart string, excludeID int64) int {
+	count := 0
+	for _, s := range shifts {
+		if s.ID == excludeID || s.VolunteerID == 0 || s.Date != date || s.Role != role {
+			continue
+		}
+		if !CountsTowardStaffing(s.Status) {
+			continue
+		}
+		if ShiftDayPart(s.StartTime) == dayPart {
+			count++
+		}
+	}
+	return count
+}
+
+// FindStaffingGaps compares the scheduled shifts between from and to
+// (inclusive) against each rule's minimum and returns every understaffed slot.
+func FindStaffingGaps(rules []*StaffingRule, shifts []*Shift, from, to time.Time) []StaffingGap {
+	gaps := []StaffingGap{}
+
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		for _, rule := range rules {
+			if rule.MinVolunteers <= 0 {
+				continue
+			}
+
+			scheduled := CountRoleSlot(shifts, date, rule.Role, rule.DayPart, 0)
+			if scheduled < rule.MinVolunteers {
+				gaps = append(gaps, StaffingGap{
+					Date:      date,
+					DayPart:   rule.DayPart,
+					Role:      rule.Role,
+					Required:  rule.MinVolunteers,
+					Scheduled: scheduled,
+					Shortfall: rule.MinVolunteers - scheduled,
+				})
+			}
+		}
+	}
+
+	// Biggest holes first within each day so coordinators see them at a glance
+	sort.SliceStable(gaps, func(i, j int) bool {
+		if gaps[i].Date != gaps[j].Date {
+			return gaps[i].Date < gaps[j].Date
+		}
+		return gaps[i].Shortfall > gaps[j].Shortfall
+	})
+
+	return gaps
+}
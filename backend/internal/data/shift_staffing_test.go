@@ -0,0 +1,114 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShiftsOverlap(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Shift
+		b    Shift
+		want bool
+	}{
+		{"same window", Shift{Date: "2026-03-01", StartTime: "09:00", EndTime: "11:00"}, Shift{Date: "2026-03-01", StartTime: "09:00", EndTime: "11:00"}, true},
+		{"partial overlap", Shift{Date: "2026-03-01", StartTime: "09:00", EndTime: "11:00"}, Shift{Date: "2026-03-01", StartTime: "10:30", EndTime: "12:00"}, true},
+		{"back to back", Shift{Date: "2026-03-01", StartTime: "09:00", EndTime: "11:00"}, Shift{Date: "2026-03-01", StartTime: "11:00", EndTime: "13:00"}, false},
+		{"different days", Shift{Date: "2026-03-01", StartTime: "09:00", EndTime: "11:00"}, Shift{Date: "2026-03-02", StartTime: "09:00", EndTime: "11:00"}, false},
+		{"12h format", Shift{Date: "2026-03-01", StartTime: "1:00 PM", EndTime: "3:00 PM"}, Shift{Date: "2026-03-01", StartTime: "14:00", EndTime: "16:00"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShiftsOverlap(&tt.a, &tt.b); got != tt.want {
+				t.Errorf("want overlap %v; got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestShiftDayPart(t *testing.T) {
+	tests := []struct {
+		start string
+		want  string
+	}{
+		{"08:00", "morning"},
+		{"11:59", "morning"},
+		{"12:00", "afternoon"},
+		{"4:30 PM", "afternoon"},
+		{"17:00", "evening"},
+	}
+
+	for _, tt := range tests {
+		if got := ShiftDayPart(tt.start); got != tt.want {
+			t.Errorf("ShiftDayPart(%q): want %s; got %s", tt.start, tt.want, got)
+		}
+	}
+}
+
+func TestFindShiftConflicts(t *testing.T) {
+	candidate := &Shift{ID: 0, VolunteerID: 1, Date: "2026-03-01", StartTime: "09:00", EndTime: "11:00", Status: "scheduled"}
+	existing := []*Shift{
+		{ID: 10, VolunteerID: 1, Date: "2026-03-01", StartTime: "10:00", EndTime: "12:00", Status: "scheduled"},
+		{ID: 11, VolunteerID: 2, Date: "2026-03-01", StartTime: "09:00", EndTime: "11:00", Status: "scheduled"},
+		{ID: 12, VolunteerID: 1, Date: "2026-03-01", StartTime: "08:00", EndTime: "10:00", Status: "cancelled"},
+	}
+
+	conflicts := FindShiftConflicts(candidate, existing)
+	if len(conflicts) != 1 || conflicts[0].ID != 10 {
+		t.Fatalf("want only shift 10 to conflict; got %+v", conflicts)
+	}
+
+	// Updating shift 10 itself must not conflict with its stored copy
+	self := *existing[0]
+	if got := FindShiftConflicts(&self, existing[:1]); len(got) != 0 {
+		t.Errorf("want no self-conflict; got %d", len(got))
+	}
+
+	// Open slots have no volunteer, so they never conflict with each other
+	open := &Shift{ID: 0, Date: "2026-03-01", StartTime: "09:00", EndTime: "11:00", Status: "scheduled"}
+	openExisting := []*Shift{{ID: 20, Date: "2026-03-01", StartTime: "09:00", EndTime: "11:00", Status: "scheduled"}}
+	if got := FindShiftConflicts(open, openExisting); len(got) != 0 {
+		t.Errorf("want open slots not to conflict; got %d", len(got))
+	}
+}
+
+func TestCountRoleSlotSkipsOpenSlots(t *testing.T) {
+	shifts := []*Shift{
+		{ID: 1, VolunteerID: 1, Date: "2026-03-01", StartTime: "08:00", EndTime: "10:00", Role: "Feeding/Cleaning", Status: "scheduled"},
+		{ID: 2, Date: "2026-03-01", StartTime: "08:00", EndTime: "10:00", Role: "Feeding/Cleaning", Status: "scheduled"},
+	}
+
+	if got := CountRoleSlot(shifts, "2026-03-01", "Feeding/Cleaning", "morning", 0); got != 1 {
+		t.Errorf("want 1 filled slot; got %d", got)
+	}
+}
+
+func TestFindStaffingGaps(t *testing.T) {
+	rules := []*StaffingRule{
+		{Role: "Feeding/Cleaning", DayPart: "morning", MinVolunteers: 2},
+		{Role: "Cat Socializing", DayPart: "afternoon", MinVolunteers: 0},
+	}
+	shifts := []*Shift{
+		{ID: 1, VolunteerID: 1, Date: "2026-03-01", StartTime: "08:00", EndTime: "10:00", Role: "Feeding/Cleaning", Status: "scheduled"},
+		{ID: 2, VolunteerID: 2, Date: "2026-03-01", StartTime: "08:00", EndTime: "10:00", Role: "Feeding/Cleaning", Status: "scheduled"},
+		{ID: 3, VolunteerID: 3, Date: "2026-03-02", StartTime: "08:00", EndTime: "10:00", Role: "Feeding/Cleaning", Status: "scheduled"},
+		{ID: 4, VolunteerID: 4, Date: "2026-03-02", StartTime: "08:00", EndTime: "10:00", Role: "Feeding/Cleaning", Status: "no_show"},
+	}
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	gaps := FindStaffingGaps(rules, shifts, from, to)
+	if len(gaps) != 2 {
+		t.Fatalf("want 2 gaps; got %d (%+v)", len(gaps), gaps)
+	}
+
+	if gaps[0].Date != "2026-03-02" || gaps[0].Shortfall != 1 {
+		t.Errorf("want 2026-03-02 short by 1; got %+v", gaps[0])
+	}
+	if gaps[1].Date != "2026-03-03" || gaps[1].Shortfall != 2 {
+		t.Errorf("want 2026-03-03 short by 2; got %+v", gaps[1])
+	}
+}
@@ -16,7 +16,7 @@ type Shift struct {
 	Status        string `json:"status"`
 	Notes         string `json:"notes"`
 	VolunteerName string `json:"volunteerName,omitempty"` // Added for dashboard
-	Version       int    `json:"-"`                       // Not using version yet but good practice
+	Version       int    `json:"version"`
 }
 
 type ShiftModel struct {
@@ -47,7 +47,7 @@ func (m ShiftModel) Insert(shift *Shift) error {
 
 func (m ShiftModel) GetForVolunteer(volunteerID int64) ([]*Shift, error) {
 	query := `
-		SELECT id, volunteer_id, TO_CHAR(date, 'YYYY-MM-DD'), start_time, end_time, role, status, notes
+		SELECT id, volunteer_id, TO_CHAR(date, 'YYYY-MM-DD'), start_time, end_time, role, status, notes, version
 		FROM shifts
 		WHERE volunteer_id = $1
 		ORDER BY date DESC, start_time ASC`
@@ -73,6 +73,7 @@ func (m ShiftModel) GetForVolunteer(volunteerID int64) ([]*Shift, error) {
 			&s.Role,
 			&s.Status,
 			&s.Notes,
+			&s.Version,
 		)
 		if err != nil {
 			return nil, err
@@ -91,7 +92,7 @@ func (m ShiftModel) GetAll(start, end string) ([]*Shift, error) {
 	// Query to fetch shifts including volunteer name by joining volunteers table
 	query := `
 		SELECT s.id, s.volunteer_id, TO_CHAR(s.date, 'YYYY-MM-DD'), s.start_time, s.end_time, s.role, s.status, s.notes,
-		COALESCE(v.first_name, ''), COALESCE(v.last_name, '')
+		COALESCE(v.first_name, ''), COALESCE(v.last_name, ''), s.version
 		FROM shifts s
 		JOIN volunteers v ON s.volunteer_id = v.id
 		WHERE s.date >= $1 AND s.date <= $2
@@ -134,6 +135,7 @@ func (m ShiftModel) GetAll(start, end string) ([]*Shift, error) {
 			&s.Notes,
 			&firstName,
 			&lastName,
+			&s.Version,
 		)
 		if err != nil {
 			return nil, err
@@ -209,6 +211,14 @@ func (m ShiftModel) Update(shift *Shift) error {
 	if err != nil {
 		switch {
 		case err == sql.ErrNoRows:
+			// No row matched: either it was deleted or the version moved on
+			var exists bool
+			if err := m.DB.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM shifts WHERE id = $1)`, shift.ID).Scan(&exists); err != nil {
+				return err
+			}
+			if !exists {
+				return ErrRecordNotFound
+			}
 			return ErrEditConflict
 		default:
 			return err
@@ -0,0 +1,345 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+const (
+	StrayHolding   = "holding"
+	StrayReclaimed = "reclaimed"
+	StrayReleased  = "released"
+)
+
+var StrayStatuses = []string{StrayHolding, StrayReclaimed, StrayReleased}
+
+// ErrStrayHold is returned when a pet still held for its owner is moved to a
+// status that lists it or lets it leave the shelter.
+var ErrStrayHold = errors.New("pet is on stray hold")
+
+// StrayHoldStatuses are the pet statuses allowed while a stray is held: it
+// stays in the shelter, off the listings, until the hold ends or it's
+// reclaimed.
+var StrayHoldStatuses = []string{"hold", "intake", "archived"}
+
+const (
+	MicrochipNotScanned = "not_scanned"
+	MicrochipNone       = "none"
+	MicrochipFound      = "found"
+)
+
+var MicrochipScanResults = []string{MicrochipNotScanned, MicrochipNone, MicrochipFound}
+
+// DefaultStrayHoldDays is how long a stray is held for its owner when intake
+// doesn't say otherwise; MinStrayHoldDays is the shortest hold allowed.
+const (
+	DefaultStrayHoldDays = 5
+	MinStrayHoldDays     = 3
+)
+
+// StrayIntake is a found animal and the hold we keep it on. The pet record
+// is created alongside it with status "hold".
+type StrayIntake struct {
+	ID              int64      `json:"id"`
+	PetID           string     `json:"petId"`
+	PetName         string     `json:"petName"`
+	FinderName      string     `json:"finderName"`
+	FinderEmail     string     `json:"finderEmail"`
+	FinderPhone     string     `json:"finderPhone"`
+	FoundDate       string     `json:"foundDate"`
+	FoundLocation   string     `json:"foundLocation"`
+	Latitude        *float64   `json:"latitude"`
+	Longitude       *float64   `json:"longitude"`
+	MicrochipScan   string     `json:"microchipScan"`
+	MicrochipNumber string     `json:"microchipNumber"`
+	IntakeDate      string     `json:"intakeDate"`
+	HoldDays        int        `json:"holdDays"`
+	HoldUntil       string     `json:"holdUntil"`
+	Status          string     `json:"status"`
+	OwnerName       string     `json:"ownerName"`
+	OwnerContact    string     `json:"ownerContact"`
+	ReclaimedAt     *time.Time `json:"reclaimedAt"`
+	Notes           string     `json:"notes"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	Version         int        `json:"version"`
+}
+
+// HoldEnded reports whether the hold period is over, whatever the status.
+func (s *StrayIntake) HoldEnded(today time.Time) bool {
+	return s.HoldUntil <= today.Format(time.DateOnly)
+}
+
+// OnHold reports whether the stray still blocks its pet from being listed.
+func (s *StrayIntake) OnHold(today time.Time) bool {
+	return s.Status == StrayHolding && !s.HoldEnded(today)
+}
+
+// SetHold derives HoldUntil from the intake date and hold length.
+func (s *StrayIntake) SetHold() {
+	if d, err := time.Parse(time.DateOnly, s.IntakeDate); err == nil {
+		s.HoldUntil = d.AddDate(0, 0, s.HoldDays).Format(time.DateOnly)
+	}
+}
+
+// FoundPet is the public view of a held stray: enough for an owner to
+// recognise their cat, nothing about who found it.
+type FoundPet struct {
+	StrayID   int64    `json:"strayId"`
+	PetID     string   `json:"petId"`
+	Name      string   `json:"name"`
+	Species   string   `json:"species"`
+	Sex       string   `json:"sex"`
+	Color     string   `json:"color"`
+	PhotoURL  string   `json:"photoUrl"`
+	FoundDate string   `json:"foundDate"`
+	Latitude  *float64 `json:"latitude"` // rounded to about 1km
+	Longitude *float64 `json:"longitude"`
+	HoldUntil string   `json:"holdUntil"`
+}
+
+type StrayFilter struct {
+	Search string
+	Status string
+}
+
+func ValidateStrayIntake(v *validator.Validator, s *StrayIntake) {
+	v.Check(s.FinderName != "" || s.FinderEmail != "" || s.FinderPhone != "", "finderName", "finder contact must be provided")
+	if s.FinderEmail != "" {
+		v.Check(validator.Matches(s.FinderEmail, validator.EmailRX), "finderEmail", "must be a valid email address")
+	}
+
+	found, err := time.Parse(time.DateOnly, s.FoundDate)
+	v.Check(err == nil, "foundDate", "must be a YYYY-MM-DD date")
+	intake, err2 := time.Parse(time.DateOnly, s.IntakeDate)
+	v.Check(err2 == nil, "intakeDate", "must be a YYYY-MM-DD date")
+	if err == nil && err2 == nil {
+		v.Check(!found.After(intake), "foundDate", "must not be after the intake date")
+	}
+
+	v.Check(s.FoundLocation != "" || s.Latitude != nil, "foundLocation", "a location or coordinates must be provided")
+	v.Check((s.Latitude == nil) == (s.Longitude == nil), "latitude", "latitude and longitude must be given together")
+	if s.Latitude != nil && s.Longitude != nil {
+		v.Check(*s.Latitude >= -90 && *s.Latitude <= 90, "latitude", "must be between -90 and 90")
+		v.Check(*s.Longitude >= -180 && *s.Longitude <= 180, "longitude", "must be between -180 and 180")
+	}
+
+	v.Check(validator.PermittedValue(s.MicrochipScan, MicrochipScanResults...), "microchipScan", "must be not_scanned, none or found")
+	v.Check(s.MicrochipScan != MicrochipFound || s.MicrochipNumber != "", "microchipNumber", "must be provided when a chip was found")
+
+	v.Check(s.HoldDays >= MinStrayHoldDays, "holdDays", fmt.Sprintf("must be at least %d days", MinStrayHoldDays))
+	v.Check(s.HoldDays <= 60, "holdDays", "must be 60 days or fewer")
+
+	v.Check(validator.PermittedValue(s.Status, StrayStatuses...), "status", "invalid status")
+	if s.Status == StrayReclaimed {
+		v.Check(s.OwnerName != "", "ownerName", "must be provided when reclaimed")
+	}
+	if s.Status == StrayReleased {
+		v.Check(s.HoldEnded(time.Now()), "status", "cannot release before the hold ends on "+s.HoldUntil)
+	}
+}
+
+type StrayModel struct {
+	DB *sql.DB
+}
+
+const strayColumns = `s.id, s.pet_id, COALESCE(p.name, ''), s.finder_name, s.finder_email, s.finder_phone,
+	to_char(s.found_date, 'YYYY-MM-DD'), s.found_location, s.latitude, s.longitude, s.microchip_scan, s.microchip_number,
+	to_char(s.intake_date, 'YYYY-MM-DD'), s.hold_days, to_char(s.hold_until, 'YYYY-MM-DD'), s.status,
+	s.owner_name, s.owner_contact, s.reclaimed_at, s.notes, s.created_at, s.updated_at, s.version`
+
+const strayJoins = `
+	FROM stray_intakes s
+	LEFT JOIN pets p ON p.id::text = s.pet_id`
+
+func scanStray(row interface{ Scan(...any) error }, extra ...any) (*StrayIntake, error) {
+	var s StrayIntake
+	dest := append(extra, &s.ID, &s.PetID, &s.PetName, &s.FinderName, &s.FinderEmail, &s.FinderPhone,
+		&s.FoundDate, &s.FoundLocation, &s.Latitude, &s.Longitude, &s.MicrochipScan, &s.MicrochipNumber,
+		&s.IntakeDate, &s.HoldDays, &s.HoldUntil, &s.Status,
+		&s.OwnerName, &s.OwnerContact, &s.ReclaimedAt, &s.Notes, &s.CreatedAt, &s.UpdatedAt, &s.Version)
+
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// InsertWithPet inserts the stray's pet record and its intake in one
+// transaction, so a held pet never exists without its hold.
+func (m StrayModel) InsertWithPet(s *StrayIntake, p *Pet) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertPet(ctx, tx, p); err != nil {
+		return err
+	}
+
+	s.PetID = p.ID
+	s.PetName = p.Name
+
+	query := `
+		INSERT INTO stray_intakes (pet_id, finder_name, finder_email, finder_phone, found_date, found_location,
+			latitude, longitude, microchip_scan, microchip_number, intake_date, hold_days, hold_until, status, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, created_at, updated_at, version`
+
+	args := []any{s.PetID, s.FinderName, s.FinderEmail, s.FinderPhone, s.FoundDate, s.FoundLocation,
+		s.Latitude, s.Longitude, s.MicrochipScan, s.MicrochipNumber, s.IntakeDate, s.HoldDays, s.HoldUntil, s.Status, s.Notes}
+
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt, &s.Version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m StrayModel) Get(id int64) (*StrayIntake, error) {
+	query := `SELECT ` + strayColumns + strayJoins + ` WHERE s.id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanStray(m.DB.QueryRowContext(ctx, query, id))
+}
+
+// GetAll lists stray intakes, newest found first by default.
+func (m StrayModel) GetAll(f StrayFilter, filters Filters) ([]*StrayIntake, Metadata, error) {
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), `+strayColumns+strayJoins+`
+		WHERE ($1 = '' OR s.status = $1)
+		AND ($2 = '' OR p.name ILIKE '%%' || $2 || '%%' OR s.found_location ILIKE '%%' || $2 || '%%'
+			OR s.finder_name ILIKE '%%' || $2 || '%%' OR s.microchip_number = $2)
+		ORDER BY s.%s %s, s.id DESC
+		LIMIT $3 OFFSET $4`, filters.sortColumn(), filters.sortDirection())
+
+	args := []any{f.Status, f.Search, filters.limit(), filters.offset()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	strays := []*StrayIntake{}
+	for rows.Next() {
+		s, err := scanStray(rows, &totalRecords)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		strays = append(strays, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return strays, calculateMetadata(totalRecords, filters.Page, filters.PageSize), nil
+}
+
+func (m StrayModel) Update(s *StrayIntake) error {
+	query := `
+		UPDATE stray_intakes SET
+			finder_name = $1, finder_email = $2, finder_phone = $3, found_date = $4, found_location = $5,
+			latitude = $6, longitude = $7, microchip_scan = $8, microchip_number = $9, intake_date = $10,
+			hold_days = $11, hold_until = $12, status = $13, owner_name = $14, owner_contact = $15, notes = $16,
+			reclaimed_at = CASE WHEN $13 = 'reclaimed' THEN COALESCE(reclaimed_at, NOW()) END,
+			updated_at = NOW(), version = version + 1
+		WHERE id = $17 AND version = $18
+		RETURNING reclaimed_at, updated_at, version`
+
+	args := []any{s.FinderName, s.FinderEmail, s.FinderPhone, s.FoundDate, s.FoundLocation,
+		s.Latitude, s.Longitude, s.MicrochipScan, s.MicrochipNumber, s.IntakeDate,
+		s.HoldDays, s.HoldUntil, s.Status, s.OwnerName, s.OwnerContact, s.Notes, s.ID, s.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&s.ReclaimedAt, &s.UpdatedAt, &s.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// ActiveHold returns the stray hold still blocking petID, or
+// ErrRecordNotFound if there isn't one.
+func (m StrayModel) ActiveHold(petID string) (*StrayIntake, error) {
+	query := `SELECT ` + strayColumns + strayJoins + `
+		WHERE s.pet_id = $1 AND s.status = 'holding' AND s.hold_until > CURRENT_DATE
+		ORDER BY s.hold_until DESC
+		LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanStray(m.DB.QueryRowContext(ctx, query, petID))
+}
+
+// Found lists strays still held for their owners, most recently found
+// first. The free-text found location is left out and coordinates are
+// rounded, so the listing doesn't pinpoint a finder's home.
+func (m StrayModel) Found() ([]*FoundPet, error) {
+	query := `
+		SELECT s.id, s.pet_id, COALESCE(p.name, ''), COALESCE(p.species, ''), COALESCE(p.sex, 'unknown'),
+			COALESCE(p.physical->>'color', ''), COALESCE(p.photos->0->>'url', ''),
+			to_char(s.found_date, 'YYYY-MM-DD'), s.latitude, s.longitude,
+			to_char(s.hold_until, 'YYYY-MM-DD')
+		FROM stray_intakes s
+		JOIN pets p ON p.id::text = s.pet_id
+		WHERE s.status = 'holding'
+		ORDER BY s.found_date DESC, s.id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	round := func(f *float64) *float64 {
+		if f == nil {
+			return nil
+		}
+		r := math.Round(*f*100) / 100
+		return &r
+	}
+
+	found := []*FoundPet{}
+	for rows.Next() {
+		var f FoundPet
+		err := rows.Scan(&f.StrayID, &f.PetID, &f.Name, &f.Species, &f.Sex, &f.Color, &f.PhotoURL,
+			&f.FoundDate, &f.Latitude, &f.Longitude, &f.HoldUntil)
+		if err != nil {
+			return nil, err
+		}
+		f.Latitude, f.Longitude = round(f.Latitude), round(f.Longitude)
+		found = append(found, &f)
+	}
+
+	return found, rows.Err()
+}
@@ -0,0 +1,111 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+)
+
+func TestStrayHold(t *testing.T) {
+	s := &StrayIntake{IntakeDate: "2025-03-10", HoldDays: 5, Status: StrayHolding}
+	s.SetHold()
+
+	if s.HoldUntil != "2025-03-15" {
+		t.Fatalf("HoldUntil = %q; want 2025-03-15", s.HoldUntil)
+	}
+
+	day := func(d string) time.Time {
+		tm, _ := time.Parse(time.DateOnly, d)
+		return tm
+	}
+	if !s.OnHold(day("2025-03-14")) {
+		t.Error("should be on hold the day before it ends")
+	}
+	if s.OnHold(day("2025-03-15")) {
+		t.Error("should be off hold on HoldUntil")
+	}
+
+	s.Status = StrayReclaimed
+	if s.OnHold(day("2025-03-11")) {
+		t.Error("a reclaimed stray should not be on hold")
+	}
+}
+
+func TestValidateStrayIntake(t *testing.T) {
+	lat := 33.7
+	valid := func() *StrayIntake {
+		s := &StrayIntake{
+			FinderPhone:   "555-0100",
+			FoundDate:     "2025-03-09",
+			FoundLocation: "Oak St & 5th",
+			MicrochipScan: MicrochipNone,
+			IntakeDate:    "2025-03-10",
+			HoldDays:      DefaultStrayHoldDays,
+			Status:        StrayHolding,
+		}
+		s.SetHold()
+		return s
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(*StrayIntake)
+		field string
+	}{
+		{"valid", func(*StrayIntake) {}, ""},
+		{"no finder", func(s *StrayIntake) { s.FinderPhone = "" }, "finderName"},
+		{"found after intake", func(s *StrayIntake) { s.FoundDate = "2025-03-11" }, "foundDate"},
+		{"half coordinates", func(s *StrayIntake) { s.Latitude = &lat }, "latitude"},
+		{"chip without number", func(s *StrayIntake) { s.MicrochipScan = MicrochipFound }, "microchipNumber"},
+		{"hold too short", func(s *StrayIntake) { s.HoldDays = 1 }, "holdDays"},
+		{"reclaimed without owner", func(s *StrayIntake) { s.Status = StrayReclaimed }, "ownerName"},
+		{"released during hold", func(s *StrayIntake) {
+			s.IntakeDate = time.Now().Format(time.DateOnly)
+			s.FoundDate = s.IntakeDate
+			s.SetHold()
+			s.Status = StrayReleased
+		}, "status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.edit(s)
+			v := validator.New()
+			ValidateStrayIntake(v, s)
+
+			if tt.field == "" {
+				if !v.Valid() {
+					t.Errorf("unexpected errors: %v", v.Errors)
+				}
+				return
+			}
+			if _, ok := v.Errors[tt.field]; !ok {
+				t.Errorf("expected an error on %q, got %v", tt.field, v.Errors)
+			}
+		})
+	}
+}
+
+func TestLeavesStrayHold(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"hold", "available", true},
+		{"hold", "adoption-pending", true},
+		{"hold", "Adopted", true},
+		{"hold", "foster", true},
+		{"hold", "intake", false},
+		{"hold", "archived", false},
+		// Editing other fields of a pet that's already listed isn't a move
+		{"available", "available", false},
+	}
+
+	for _, tt := range tests {
+		if got := leavesStrayHold(tt.from, tt.to); got != tt.want {
+			t.Errorf("leavesStrayHold(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
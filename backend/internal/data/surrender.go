@@ -1,6 +1,12 @@
 package data
 
 import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
 	"github.com/cconner57/adoption-os/backend/internal/validator"
 )
 
@@ -122,3 +128,153 @@ func ValidateSurrenderApplication(v *validator.Validator, app *SurrenderApplicat
 		v.Check(member.Count > 0, "householdMembers", "count must be positive")
 	}
 }
+
+// IsYes reads the forms' Yes/No answers, which sometimes carry a trailing
+// explanation ("Yes, once when...").
+func IsYes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "true" || strings.HasPrefix(answer, "yes")
+}
+
+var animalAgeRX = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(week|wk|month|mo|year|yr)?`)
+
+// AgeGroupFromAge maps a free-text age ("8 weeks", "2 yrs", "10") to one of
+// AgeGroups. A bare number is taken as years; ok is false if there's none.
+func AgeGroupFromAge(age string) (group string, ok bool) {
+	m := animalAgeRX.FindStringSubmatch(strings.ToLower(age))
+	if m == nil {
+		return "", false
+	}
+
+	years, _ := strconv.ParseFloat(m[1], 64)
+	switch {
+	case strings.HasPrefix(m[2], "w"):
+		years /= 52
+	case strings.HasPrefix(m[2], "m"):
+		years /= 12
+	}
+
+	switch {
+	case years < 1:
+		return "baby", true
+	case years < 3:
+		return "young", true
+	case years < 10:
+		return "adult", true
+	default:
+		return "senior", true
+	}
+}
+
+// IntakePet drafts the pet record for an accepted surrender: status
+// "intake", dated intakeDate and pointing back at application id. Medical
+// answers are only copied when includeMedical is set, so callers without
+// medical access don't write them.
+func (a *SurrenderApplication) IntakePet(id int64, species, intakeDate string, includeMedical bool) (*Pet, error) {
+	sex := strings.ToLower(strings.TrimSpace(a.AnimalSex))
+	if !IsPermittedValue(sex, Sexes...) {
+		sex = "unknown"
+	}
+
+	physical := map[string]any{}
+	if group, ok := AgeGroupFromAge(a.AnimalAge); ok {
+		physical["ageGroup"] = group
+	}
+
+	behavior := map[string]any{
+		"isHouseTrained": IsYes(a.AnimalHouseTrained),
+	}
+	if IsYes(a.AnimalEverAttackedOtherCats) {
+		behavior["isGoodWithCats"] = false
+	}
+	if IsYes(a.AnimalEverAttackedOtherDogs) {
+		behavior["isGoodWithDogs"] = false
+	}
+
+	// The surrenderer's behavior answers, kept as written for the team to
+	// turn into a proper description
+	var notes []string
+	answer := func(label, value, explanation string) {
+		value = strings.TrimSpace(value)
+		if explanation = strings.TrimSpace(explanation); explanation != "" {
+			value = strings.TrimSpace(value + " (" + explanation + ")")
+		}
+		if value != "" {
+			notes = append(notes, label+": "+value)
+		}
+	}
+	answer("With known people", a.AnimalsBehaviorTowardsKnownPeople, "")
+	answer("With strangers", a.AnimalsBehaviorTowardsStrangers, "")
+	answer("With known animals", a.AnimalsBehaviorTowardsKnownAnimals, "")
+	answer("House trained", a.AnimalHouseTrained, "")
+	answer("Other pets in home", a.OtherPetsInHousehold, "")
+	answer("Attacked people", a.AnimalEverAttackedPeople, a.AnimalEverAttackedPeopleExplanation)
+	answer("Attacked other cats", a.AnimalEverAttackedOtherCats, a.AnimalEverAttackedOtherCatsExplanation)
+	answer("Attacked dogs", a.AnimalEverAttackedOtherDogs, a.AnimalEverAttackedOtherDogsExplanation)
+	answer("Escaped before", a.AnimalEscapedBefore, a.AnimalEscapedBeforeExplanation)
+	answer("Scared of", a.AnimalScaredOfAnything, a.AnimalScaredOfAnythingExplanation)
+	answer("Bad habits", a.AnimalBadHabits, "")
+	answer("Comments", a.CommentsOnBehavior, "")
+
+	origin := fmt.Sprintf("Owner surrender (application #%d), taken in %s.", id, intakeDate)
+	if a.AnimalOwnershipDuration != "" {
+		origin += " Owned for " + a.AnimalOwnershipDuration + "."
+	}
+	if a.AnimalLocationFound != "" {
+		origin += " Originally from " + a.AnimalLocationFound + "."
+	}
+	if a.AnimalWhySurrendered != "" {
+		origin += " Reason: " + a.AnimalWhySurrendered
+	}
+
+	descriptions := map[string]any{
+		"origin":     origin,
+		"behavioral": strings.Join(notes, "\n"),
+	}
+
+	details := map[string]any{
+		"status":              "intake",
+		"intakeDate":          intakeDate,
+		"sourceApplicationId": id,
+	}
+
+	medical := map[string]any{}
+	if includeMedical {
+		medical["spayedOrNeutered"] = IsYes(a.AnimalSpayedNeutered)
+		medical["vaccinationsUpToDate"] = IsYes(a.AnimalVaccinationsCurrent)
+		medical["microchip"] = map[string]any{"microchipped": IsYes(a.AnimalMicrochipped)}
+		if IsYes(a.AnimalCurrentMedications) {
+			medical["currentMedications"] = a.AnimalCurrentMedicationsExplanation
+		}
+		if IsYes(a.AnimalPastOrPresentHealthProblems) {
+			medical["healthConcerns"] = a.AnimalPastOrPresentHealthProblemsExplanation
+		}
+	}
+
+	pet := &Pet{
+		Name:            strings.TrimSpace(a.AnimalName),
+		Species:         species,
+		Sex:             sex,
+		Adoption:        json.RawMessage("{}"),
+		Foster:          json.RawMessage("{}"),
+		Returned:        json.RawMessage("{}"),
+		Sponsored:       json.RawMessage("{}"),
+		Photos:          json.RawMessage("[]"),
+		ProfileSettings: json.RawMessage("{}"),
+	}
+
+	var err error
+	for dst, src := range map[*json.RawMessage]any{
+		&pet.Physical:     physical,
+		&pet.Behavior:     behavior,
+		&pet.Medical:      medical,
+		&pet.Descriptions: descriptions,
+		&pet.Details:      details,
+	} {
+		if *dst, err = json.Marshal(src); err != nil {
+			return nil, err
+		}
+	}
+
+	return pet, nil
+}
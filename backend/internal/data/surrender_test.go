@@ -0,0 +1,73 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAgeGroupFromAge(t *testing.T) {
+	tests := map[string]string{
+		"8 weeks":     "baby",
+		"6 months":    "baby",
+		"2 yrs":       "young",
+		"about 5":     "adult",
+		"12 years":    "senior",
+		"1.5 years":   "young",
+		"18 mo":       "young",
+		"unknown age": "",
+	}
+
+	for age, want := range tests {
+		got, ok := AgeGroupFromAge(age)
+		if got != want || ok != (want != "") {
+			t.Errorf("AgeGroupFromAge(%q) = %q, %v; want %q", age, got, ok, want)
+		}
+	}
+}
+
+func TestIntakePet(t *testing.T) {
+	a := &SurrenderApplication{
+		AnimalName:                  " Mittens ",
+		AnimalSex:                   "Female",
+		AnimalAge:                   "3 months",
+		AnimalHouseTrained:          "Yes",
+		AnimalEverAttackedOtherDogs: "Yes",
+		AnimalWhySurrendered:        "Moving",
+		AnimalSpayedNeutered:        "Yes",
+	}
+
+	pet, err := a.IntakePet(42, "cat", "2025-03-10", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pet.Name != "Mittens" || pet.Sex != "female" || pet.Species != "cat" {
+		t.Errorf("pet = %q %q %q; want Mittens female cat", pet.Name, pet.Sex, pet.Species)
+	}
+
+	var details map[string]any
+	_ = json.Unmarshal(pet.Details, &details)
+	if details["status"] != "intake" || details["intakeDate"] != "2025-03-10" || details["sourceApplicationId"] != float64(42) {
+		t.Errorf("details = %v", details)
+	}
+
+	var behavior map[string]any
+	_ = json.Unmarshal(pet.Behavior, &behavior)
+	if behavior["isHouseTrained"] != true || behavior["isGoodWithDogs"] != false {
+		t.Errorf("behavior = %v", behavior)
+	}
+	if _, ok := behavior["isGoodWithCats"]; ok {
+		t.Errorf("isGoodWithCats should be left unset, got %v", behavior["isGoodWithCats"])
+	}
+
+	if string(pet.Medical) != "{}" {
+		t.Errorf("medical = %s; want {} without medical access", pet.Medical)
+	}
+
+	pet, _ = a.IntakePet(42, "cat", "2025-03-10", true)
+	var medical map[string]any
+	_ = json.Unmarshal(pet.Medical, &medical)
+	if medical["spayedOrNeutered"] != true {
+		t.Errorf("medical = %v; want spayedOrNeutered", medical)
+	}
+}
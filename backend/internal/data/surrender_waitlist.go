@@ -0,0 +1,215 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/validator"
+	"github.com/lib/pq"
+)
+
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistScheduled = "scheduled"
+	WaitlistAccepted  = "accepted"
+	WaitlistDeclined  = "declined"
+	WaitlistWithdrawn = "withdrawn"
+)
+
+var WaitlistStatuses = []string{WaitlistWaiting, WaitlistScheduled, WaitlistAccepted, WaitlistDeclined, WaitlistWithdrawn}
+
+const (
+	TriageHigh   = "high"
+	TriageMedium = "medium"
+	TriageLow    = "low"
+)
+
+// SurrenderTriage scores how soon an animal has to leave its home (Urgency)
+// and how hard it may be to place (Risk). Priority orders the waitlist.
+type SurrenderTriage struct {
+	Urgency  int      `json:"urgency"`
+	Risk     int      `json:"risk"`
+	Priority int      `json:"priority"`
+	Level    string   `json:"level"`
+	NeededBy *string  `json:"neededBy"` // nil when the timeframe couldn't be read
+	Reasons  []string `json:"reasons"`
+}
+
+// WaitlistEntry is a surrender application's place in the intake queue.
+// Position and FitsCapacity are computed per listing.
+type WaitlistEntry struct {
+	ApplicationID int64 `json:"applicationId"`
+	SurrenderTriage
+	Status     string    `json:"status"`
+	IntakeDate *string   `json:"intakeDate"`
+	Notes      string    `json:"notes"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	Version    int       `json:"version"`
+
+	// From the application
+	AnimalName      string `json:"animalName"`
+	AnimalAge       string `json:"animalAge"`
+	SurrendererName string `json:"surrendererName"`
+	Email           string `json:"email"`
+	Phone           string `json:"phone"`
+
+	Position     int  `json:"position,omitempty"`
+	FitsCapacity bool `json:"fitsCapacity"`
+}
+
+// WaitlistCapacity is room to take surrenders in: open foster spots less
+// intakes already scheduled.
+type WaitlistCapacity struct {
+	OpenSpots int `json:"openSpots"`
+	Scheduled int `json:"scheduled"`
+	Available int `json:"available"`
+}
+
+func ValidateWaitlistEntry(v *validator.Validator, e *WaitlistEntry) {
+	v.Check(validator.PermittedValue(e.Status, WaitlistStatuses...), "status", "invalid status")
+	if e.IntakeDate != nil {
+		_, err := time.Parse(time.DateOnly, *e.IntakeDate)
+		v.Check(err == nil, "intakeDate", "must be a YYYY-MM-DD date")
+	}
+	v.Check(e.Status != WaitlistScheduled || e.IntakeDate != nil, "intakeDate", "must be provided when scheduling")
+	v.Check(len(e.Notes) <= 2000, "notes", "must be 2000 characters or fewer")
+}
+
+type SurrenderWaitlistModel struct {
+	DB *sql.DB
+}
+
+const waitlistColumns = `w.application_id, w.urgency, w.risk, w.priority, w.level, to_char(w.needed_by, 'YYYY-MM-DD'), w.reasons,
+	w.status, to_char(w.intake_date, 'YYYY-MM-DD'), w.notes, w.created_at, w.updated_at, w.version,
+	COALESCE(a.data->>'animalName', ''), COALESCE(a.data->>'animalAge', ''),
+	TRIM(COALESCE(a.data->>'firstName', '') || ' ' || COALESCE(a.data->>'lastName', '')),
+	COALESCE(a.data->>'email', ''), COALESCE(a.data->>'phoneNumber', '')`
+
+func scanWaitlistEntry(row interface{ Scan(...any) error }, extra ...any) (*WaitlistEntry, error) {
+	var e WaitlistEntry
+	dest := append(extra, &e.ApplicationID, &e.Urgency, &e.Risk, &e.Priority, &e.Level, &e.NeededBy, pq.Array(&e.Reasons),
+		&e.Status, &e.IntakeDate, &e.Notes, &e.CreatedAt, &e.UpdatedAt, &e.Version,
+		&e.AnimalName, &e.AnimalAge, &e.SurrendererName, &e.Email, &e.Phone)
+
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Triage stores the scores for an application, adding it to the waitlist
+// if it isn't there yet. Re-triage leaves the status and notes alone.
+func (m SurrenderWaitlistModel) Triage(applicationID int64, t SurrenderTriage) error {
+	query := `
+		INSERT INTO surrender_waitlist (application_id, urgency, risk, priority, level, needed_by, reasons)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (application_id) DO UPDATE
+		SET urgency = EXCLUDED.urgency, risk = EXCLUDED.risk, priority = EXCLUDED.priority, level = EXCLUDED.level,
+			needed_by = EXCLUDED.needed_by, reasons = EXCLUDED.reasons, updated_at = NOW(),
+			version = surrender_waitlist.version + 1`
+
+	args := []any{applicationID, t.Urgency, t.Risk, t.Priority, t.Level, t.NeededBy, pq.Array(t.Reasons)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (m SurrenderWaitlistModel) Get(applicationID int64) (*WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM surrender_waitlist w
+		JOIN applications a ON a.id = w.application_id
+		WHERE w.application_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanWaitlistEntry(m.DB.QueryRowContext(ctx, query, applicationID))
+}
+
+// GetAll lists the queue highest priority first, oldest first within a
+// priority. An empty status lists every entry.
+func (m SurrenderWaitlistModel) GetAll(status string, filters Filters) ([]*WaitlistEntry, Metadata, error) {
+	query := `
+		SELECT count(*) OVER(), ` + waitlistColumns + `
+		FROM surrender_waitlist w
+		JOIN applications a ON a.id = w.application_id
+		WHERE ($1 = '' OR w.status = $1)
+		ORDER BY w.priority DESC, w.created_at, w.application_id
+		LIMIT $2 OFFSET $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, status, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	entries := []*WaitlistEntry{}
+	for rows.Next() {
+		e, err := scanWaitlistEntry(rows, &totalRecords)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return entries, calculateMetadata(totalRecords, filters.Page, filters.PageSize), nil
+}
+
+func (m SurrenderWaitlistModel) Update(e *WaitlistEntry) error {
+	query := `
+		UPDATE surrender_waitlist
+		SET status = $1, intake_date = $2, notes = $3, updated_at = NOW(), version = version + 1
+		WHERE application_id = $4 AND version = $5
+		RETURNING updated_at, version`
+
+	args := []any{e.Status, e.IntakeDate, e.Notes, e.ApplicationID, e.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&e.UpdatedAt, &e.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (m SurrenderWaitlistModel) Capacity() (WaitlistCapacity, error) {
+	query := `
+		SELECT
+			(SELECT COALESCE(SUM(GREATEST(f.capacity - (
+				SELECT COUNT(*) FROM foster_placements pl WHERE pl.foster_id = f.id AND pl.end_date IS NULL), 0)), 0)
+			 FROM foster_homes f WHERE f.status = 'approved'),
+			(SELECT COUNT(*) FROM surrender_waitlist WHERE status = 'scheduled')`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var c WaitlistCapacity
+	if err := m.DB.QueryRowContext(ctx, query).Scan(&c.OpenSpots, &c.Scheduled); err != nil {
+		return WaitlistCapacity{}, err
+	}
+	c.Available = max(c.OpenSpots-c.Scheduled, 0)
+	return c, nil
+}
@@ -0,0 +1,139 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	ScopeVerification  = "verification"
+	ScopePasswordReset = "password-reset"
+	// ScopeSignIn hands a single sign-on session to the frontend
+	ScopeSignIn = "sign-in"
+	// ScopeDownloadPrefix + the URL path a download token is good for
+	ScopeDownloadPrefix = "download:"
+)
+
+type TokenModel struct {
+	DB *sql.DB
+}
+
+// New issues a token for scope, returning the plaintext to put in the email.
+func (m TokenModel) New(userID, scope string, ttl time.Duration) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO user_tokens (hash, user_id, scope, expiry)
+		VALUES ($1, $2, $3, $4)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err = m.DB.ExecContext(ctx, query, hashToken(token), userID, scope, time.Now().Add(ttl))
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Consume deletes a live token and returns its user, so each token works once.
+// Unknown, expired and wrong-scope tokens return ErrRecordNotFound.
+func (m TokenModel) Consume(scope, token string) (string, error) {
+	query := `
+		DELETE FROM user_tokens
+		WHERE hash = $1 AND scope = $2 AND expiry > NOW()
+		RETURNING user_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var userID string
+	err := m.DB.QueryRowContext(ctx, query, hashToken(token), scope).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrRecordNotFound
+		}
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// NewForSession issues a token bound to a session; revoking the session
+// revokes the token.
+func (m TokenModel) NewForSession(userID string, sessionID int64, scope string, ttl time.Duration) (string, time.Time, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiry := time.Now().Add(ttl)
+
+	query := `
+		INSERT INTO user_tokens (hash, user_id, scope, expiry, session_id)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err = m.DB.ExecContext(ctx, query, hashToken(token), userID, scope, expiry, sessionID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiry, nil
+}
+
+// ConsumeForSession is Consume for session-bound tokens.
+func (m TokenModel) ConsumeForSession(scope, token string) (int64, error) {
+	query := `
+		DELETE FROM user_tokens
+		WHERE hash = $1 AND scope = $2 AND expiry > NOW() AND session_id IS NOT NULL
+		RETURNING session_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var sessionID int64
+	err := m.DB.QueryRowContext(ctx, query, hashToken(token), scope).Scan(&sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrRecordNotFound
+		}
+		return 0, err
+	}
+
+	return sessionID, nil
+}
+
+func (m TokenModel) DeleteAllForUser(scope, userID string) error {
+	query := `DELETE FROM user_tokens WHERE scope = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, scope, userID)
+	return err
+}
+
+// DeleteEveryScopeForUser drops all of the user's outstanding tokens.
+func (m TokenModel) DeleteEveryScopeForUser(userID string) error {
+	query := `DELETE FROM user_tokens WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID)
+	return err
+}
+
+func (m TokenModel) DeleteExpired(ctx context.Context) error {
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM user_tokens WHERE expiry < NOW()`)
+	return err
+}
@@ -4,14 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 var (
 	ErrRecordNotFound = errors.New("record not found")
 	ErrEditConflict   = errors.New("edit conflict")
+	ErrDuplicateEmail = errors.New("duplicate email")
 )
 
 type User struct {
@@ -23,6 +26,11 @@ type User struct {
 	Activated    bool
 	Role         string
 	Version      int
+	Permissions  []string // Derived from Role, not stored
+	// EmailVerifiedAt is nil until the verification link is followed
+	EmailVerifiedAt *time.Time
+	// LockedUntil is set after too many failed logins
+	LockedUntil *time.Time
 }
 
 type UserModel struct {
@@ -31,8 +39,8 @@ type UserModel struct {
 
 func (m UserModel) Insert(user *User) error {
 	query := `
-		INSERT INTO users (id, name, email, password_hash, activated, role)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (id, name, email, password_hash, activated, role, email_verified_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at, version`
 
 	// Generate UUID if not present? Or always?
@@ -40,7 +48,7 @@ func (m UserModel) Insert(user *User) error {
 		user.ID = uuid.New().String()
 	}
 
-	args := []any{user.ID, user.Name, user.Email, user.PasswordHash, user.Activated, user.Role}
+	args := []any{user.ID, user.Name, user.Email, user.PasswordHash, user.Activated, user.Role, user.EmailVerifiedAt}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -60,7 +68,7 @@ func (m UserModel) Insert(user *User) error {
 
 func (m UserModel) GetByEmail(email string) (*User, error) {
 	query := `
-		SELECT id, created_at, name, email, password_hash, activated, role, version
+		SELECT id, created_at, name, email, password_hash, activated, role, version, email_verified_at, locked_until
 		FROM users
 		WHERE email = $1`
 
@@ -78,6 +86,8 @@ func (m UserModel) GetByEmail(email string) (*User, error) {
 		&user.Activated,
 		&user.Role,
 		&user.Version,
+		&user.EmailVerifiedAt,
+		&user.LockedUntil,
 	)
 
 	if err != nil {
@@ -92,7 +102,7 @@ func (m UserModel) GetByEmail(email string) (*User, error) {
 
 func (m UserModel) Get(id string) (*User, error) {
 	query := `
-		SELECT id, created_at, name, email, password_hash, activated, role, version
+		SELECT id, created_at, name, email, password_hash, activated, role, version, email_verified_at, locked_until
 		FROM users
 		WHERE id = $1`
 
@@ -110,6 +120,8 @@ func (m UserModel) Get(id string) (*User, error) {
 		&user.Activated,
 		&user.Role,
 		&user.Version,
+		&user.EmailVerifiedAt,
+		&user.LockedUntil,
 	)
 
 	if err != nil {
@@ -125,7 +137,7 @@ func (m UserModel) Get(id string) (*User, error) {
 func (m UserModel) Update(user *User) error {
 	query := `
 		UPDATE users 
-		SET name = $1, email = $2, password_hash = $3, activated = $4, role = $5, version = version + 1
+		SET name = $1, email = $2, password_hash = $3, activated = $4, role = $5, email_verified_at = $8, version = version + 1
 		WHERE id = $6 AND version = $7
 		RETURNING version`
 
@@ -137,6 +149,7 @@ func (m UserModel) Update(user *User) error {
 		user.Role,
 		user.ID,
 		user.Version,
+		user.EmailVerifiedAt,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -147,6 +160,8 @@ func (m UserModel) Update(user *User) error {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			return ErrEditConflict
+		case isUniqueViolation(err):
+			return ErrDuplicateEmail
 		default:
 			return err
 		}
@@ -154,3 +169,175 @@ func (m UserModel) Update(user *User) error {
 
 	return nil
 }
+
+// GetByRoles returns every activated user holding one of the given roles (case-insensitive).
+func (m UserModel) GetByRoles(roles ...string) ([]*User, error) {
+	query := `
+		SELECT id, created_at, name, email, password_hash, activated, role, version, email_verified_at, locked_until
+		FROM users
+		WHERE UPPER(role) = ANY($1) AND activated = true
+		ORDER BY created_at ASC`
+
+	upper := make([]string, len(roles))
+	for i, role := range roles {
+		upper[i] = strings.ToUpper(role)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(upper))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*User{}
+	for rows.Next() {
+		var user User
+		err := rows.Scan(
+			&user.ID,
+			&user.CreatedAt,
+			&user.Name,
+			&user.Email,
+			&user.PasswordHash,
+			&user.Activated,
+			&user.Role,
+			&user.Version,
+			&user.EmailVerifiedAt,
+			&user.LockedUntil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// GetAll returns every user, newest first, for the admin user list.
+func (m UserModel) GetAll() ([]*User, error) {
+	query := `
+		SELECT id, created_at, name, email, password_hash, activated, role, version, email_verified_at, locked_until
+		FROM users
+		ORDER BY created_at DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*User{}
+	for rows.Next() {
+		var user User
+		err := rows.Scan(
+			&user.ID,
+			&user.CreatedAt,
+			&user.Name,
+			&user.Email,
+			&user.PasswordHash,
+			&user.Activated,
+			&user.Role,
+			&user.Version,
+			&user.EmailVerifiedAt,
+			&user.LockedUntil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// CountByRole counts activated users holding role.
+func (m UserModel) CountByRole(role string) (int, error) {
+	query := `SELECT COUNT(*) FROM users WHERE UPPER(role) = UPPER($1) AND activated = true`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var count int
+	err := m.DB.QueryRowContext(ctx, query, role).Scan(&count)
+	return count, err
+}
+
+// MarkEmailVerified records that the user confirmed their current address.
+// It returns false if it was already verified.
+func (m UserModel) MarkEmailVerified(id string) (bool, error) {
+	user, err := m.Get(id)
+	if err != nil {
+		return false, err
+	}
+	if !user.VerifyEmail(time.Now()) {
+		return false, nil
+	}
+	return true, m.Update(user)
+}
+
+// ChangeEmail sets a new address, which has to be verified again.
+func (u *User) ChangeEmail(email string) {
+	u.Email = email
+	u.EmailVerifiedAt = nil
+}
+
+// VerifyEmail marks the current address as confirmed, reporting false if it
+// already was. It never touches Activated: only an admin can reactivate an
+// account, so a verification link can't undo a deactivation.
+func (u *User) VerifyEmail(now time.Time) bool {
+	if u.EmailVerifiedAt != nil {
+		return false
+	}
+	u.EmailVerifiedAt = &now
+	return true
+}
+
+// SignInBlocked returns why the account can't sign in, or "" if it can.
+func (u *User) SignInBlocked() string {
+	switch {
+	case !u.Activated:
+		return "This account has been deactivated"
+	case u.EmailVerifiedAt == nil:
+		return "Please verify your email address before signing in"
+	}
+	return ""
+}
+
+// Locked reports whether failed logins have locked the account.
+func (u *User) Locked(now time.Time) bool {
+	return u.LockedUntil != nil && u.LockedUntil.After(now)
+}
+
+func (m UserModel) Lock(id string, until time.Time) error {
+	query := `UPDATE users SET locked_until = $2 WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, id, until)
+	return err
+}
+
+func (m UserModel) Unlock(id string) error {
+	query := `UPDATE users SET locked_until = NULL WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, id)
+	return err
+}
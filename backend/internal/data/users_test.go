@@ -0,0 +1,52 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyEmailKeepsDeactivatedAccountInactive(t *testing.T) {
+	verified := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	u := &User{Email: "old@example.com", Activated: true, EmailVerifiedAt: &verified}
+
+	// An admin deactivates the account, then its owner changes the address
+	// and follows the new verification link
+	u.Activated = false
+	u.ChangeEmail("new@example.com")
+	if got := u.SignInBlocked(); got != "This account has been deactivated" {
+		t.Fatalf("want deactivated before verifying; got %q", got)
+	}
+
+	if !u.VerifyEmail(time.Now()) {
+		t.Fatal("want the new address to be verified")
+	}
+	if u.Activated {
+		t.Error("verifying an address must not reactivate the account")
+	}
+	if got := u.SignInBlocked(); got != "This account has been deactivated" {
+		t.Errorf("want sign-in still blocked as deactivated; got %q", got)
+	}
+
+	if u.VerifyEmail(time.Now()) {
+		t.Error("want a second verification to report false")
+	}
+}
+
+func TestSignInBlocked(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		user User
+		want string
+	}{
+		{"active and verified", User{Activated: true, EmailVerifiedAt: &now}, ""},
+		{"awaiting verification", User{Activated: true}, "Please verify your email address before signing in"},
+		{"deactivated", User{EmailVerifiedAt: &now}, "This account has been deactivated"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.user.SignInBlocked(); got != tt.want {
+			t.Errorf("%s: want %q; got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// AdultVolunteerAge matches the parent-signature cutoff on the volunteer
+// application. Younger volunteers are treated as minors once approved.
+const AdultVolunteerAge = 21
+
+// MinorWeeklyHourLimit caps scheduled hours per Monday-Sunday week for minors.
+const MinorWeeklyHourLimit = 8.0
+
+// Roles minors may be scheduled for. Feeding/Cleaning involves disinfectants
+// and isolation rooms, so it's adults only.
+var MinorVolunteerRoles = []string{
+	"Cat Socializing",
+	"Customer Support",
+}
+
+type VolunteerAgePolicy struct {
+	Age                int      `json:"age"`
+	IsMinor            bool     `json:"isMinor"`
+	RequiresSupervisor bool     `json:"requiresSupervisor"`
+	AllowedRoles       []string `json:"allowedRoles,omitempty"` // empty = any role
+	MaxWeeklyHours     float64  `json:"maxWeeklyHours,omitempty"`
+}
+
+// ageOn returns the age in whole years on the given day. It compares month
+// and day rather than day of year, which shifts by one after February in leap
+// years. A February 29 birthday counts from March 1 in other years.
+func ageOn(birthday, now time.Time) int {
+	age := now.Year() - birthday.Year()
+	if now.Month() < birthday.Month() || (now.Month() == birthday.Month() && now.Day() < birthday.Day()) {
+		age--
+	}
+	return age
+}
+
+func parseBirthday(birthday string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02", birthday)
+	if err != nil {
+		t, err = time.Parse("01/02/2006", birthday)
+	}
+	return t, err
+}
+
+// PolicyFor derives the scheduling policy from the volunteer's birthday on the
+// given date. A missing birthday is treated as an adult, matching records
+// created before the application captured it.
+func (v *Volunteer) PolicyFor(on time.Time) VolunteerAgePolicy {
+	birthday, err := parseBirthday(v.Birthday)
+	if v.Birthday == "" || err != nil {
+		return VolunteerAgePolicy{}
+	}
+
+	age := ageOn(birthday, on)
+	if age >= AdultVolunteerAge {
+		return VolunteerAgePolicy{Age: age}
+	}
+
+	return VolunteerAgePolicy{
+		Age:                age,
+		IsMinor:            true,
+		RequiresSupervisor: true,
+		AllowedRoles:       MinorVolunteerRoles,
+		MaxWeeklyHours:     MinorWeeklyHourLimit,
+	}
+}
+
+// WeekOf returns the Monday and Sunday (YYYY-MM-DD) around date.
+func WeekOf(date time.Time) (string, string) {
+	offset := (int(date.Weekday()) + 6) % 7
+	monday := date.AddDate(0, 0, -offset)
+	return monday.Format("2006-01-02"), monday.AddDate(0, 0, 6).Format("2006-01-02")
+}
+
+// ScheduledHoursInWeek sums the hours a volunteer holds in the Monday-Sunday
+// week containing date, ignoring excludeID and shifts that no longer hold a slot.
+func ScheduledHoursInWeek(shifts []*Shift, volunteerID int64, date time.Time, excludeID int64) float64 {
+	monday, sunday := WeekOf(date)
+
+	total := 0.0
+	for _, s := range shifts {
+		if s.ID == excludeID || s.VolunteerID != volunteerID {
+			continue
+		}
+		if s.Date < monday || s.Date > sunday || !CountsTowardStaffing(s.Status) {
+			continue
+		}
+		total += ShiftHours(s)
+	}
+	return total
+}
+
+// CheckMinorShift returns the policy violations for scheduling shift. weekShifts
+// are the volunteer's shifts in the same week; supervisors are the adult
+// volunteers' shifts on the same day.
+func CheckMinorShift(policy VolunteerAgePolicy, shift *Shift, weekShifts []*Shift, supervisors []*Shift) map[string]string {
+	problems := map[string]string{}
+	if !policy.IsMinor {
+		return problems
+	}
+
+	if len(policy.AllowedRoles) > 0 && !IsPermittedValue(shift.Role, policy.AllowedRoles...) {
+		problems["role"] = fmt.Sprintf("is not available to volunteers under %d", AdultVolunteerAge)
+	}
+
+	if policy.MaxWeeklyHours > 0 {
+		date, err := time.Parse("2006-01-02", shift.Date)
+		if err == nil {
+			hours := ScheduledHoursInWeek(weekShifts, shift.VolunteerID, date, shift.ID) + ShiftHours(shift)
+			if hours > policy.MaxWeeklyHours {
+				problems["endTime"] = fmt.Sprintf("would bring this week to %s hours; volunteers under %d are limited to %s",
+					formatFloat(hours), AdultVolunteerAge, formatFloat(policy.MaxWeeklyHours))
+			}
+		}
+	}
+
+	if policy.RequiresSupervisor && !shiftSupervised(shift, supervisors) {
+		problems["volunteerId"] = fmt.Sprintf("volunteers under %d need an adult volunteer on site for the whole shift", AdultVolunteerAge)
+	}
+
+	return problems
+}
+
+// StrandedMinorShifts returns the minors' shifts in after that had adult cover
+// in before but have lost it, e.g. because an adult's shift was cancelled.
+// minors marks the volunteers who need a supervisor on the shift's date;
+// everyone else counts as an adult.
+func StrandedMinorShifts(before, after []*Shift, minors map[int64]bool) []*Shift {
+	adults := func(shifts []*Shift) []*Shift {
+		out := []*Shift{}
+		for _, s := range shifts {
+			if !minors[s.VolunteerID] {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	adultsBefore, adultsAfter := adults(before), adults(after)
+
+	stranded := []*Shift{}
+	for _, s := range after {
+		if !minors[s.VolunteerID] || !CountsTowardStaffing(s.Status) {
+			continue
+		}
+		if shiftSupervised(s, adultsBefore) && !shiftSupervised(s, adultsAfter) {
+			stranded = append(stranded, s)
+		}
+	}
+	return stranded
+}
+
+// shiftSupervised reports whether the adult shifts together cover every minute
+// of shift. Hand-offs between adults are fine as long as there's no gap.
+func shiftSupervised(shift *Shift, adults []*Shift) bool {
+	start, end, ok := shiftWindow(shift)
+	if !ok {
+		return false
+	}
+
+	covered := start
+	for progress := true; progress && covered < end; {
+		progress = false
+		for _, a := range adults {
+			if a.Date != shift.Date || a.VolunteerID == shift.VolunteerID || !CountsTowardStaffing(a.Status) {
+				continue
+			}
+			aStart, aEnd, ok := shiftWindow(a)
+			if ok && aStart <= covered && aEnd > covered {
+				covered = aEnd
+				progress = true
+			}
+		}
+	}
+
+	return covered >= end
+}
+
+func formatFloat(f float64) string {
+	return fmt.Sprintf("%g", f)
+}
@@ -0,0 +1,114 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVolunteerPolicyFor(t *testing.T) {
+	on := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		birthday  string
+		wantMinor bool
+	}{
+		{"2010-03-01", true},
+		{"2005-06-16", true}, // turns 21 tomorrow
+		{"2005-06-15", false},
+		{"06/15/1990", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		v := &Volunteer{Birthday: tt.birthday}
+		if got := v.PolicyFor(on).IsMinor; got != tt.wantMinor {
+			t.Errorf("birthday %q: want minor %v; got %v", tt.birthday, tt.wantMinor, got)
+		}
+	}
+}
+
+func TestAgeOn(t *testing.T) {
+	tests := []struct {
+		birthday string
+		on       string
+		want     int
+	}{
+		{"2004-03-01", "2025-03-01", 21}, // born in a leap year, birthday in a common year
+		{"2004-03-01", "2025-02-28", 20},
+		{"2005-03-01", "2026-03-01", 21},
+		{"2005-03-01", "2028-02-29", 22}, // leap day before a common-year birthday
+		{"2004-02-29", "2025-02-28", 20},
+		{"2004-02-29", "2025-03-01", 21},
+		{"2004-02-29", "2028-02-29", 24},
+		{"2004-12-31", "2025-12-30", 20},
+	}
+
+	for _, tt := range tests {
+		birthday, _ := time.Parse("2006-01-02", tt.birthday)
+		on, _ := time.Parse("2006-01-02", tt.on)
+		if got := ageOn(birthday, on); got != tt.want {
+			t.Errorf("born %s on %s: want %d; got %d", tt.birthday, tt.on, tt.want, got)
+		}
+	}
+}
+
+func TestCheckMinorShift(t *testing.T) {
+	policy := (&Volunteer{Birthday: "2010-03-01"}).PolicyFor(time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC))
+
+	// Monday 2026-06-15
+	shift := &Shift{VolunteerID: 1, Date: "2026-06-17", StartTime: "10:00", EndTime: "12:00", Role: "Cat Socializing", Status: "scheduled"}
+	week := []*Shift{
+		{ID: 1, VolunteerID: 1, Date: "2026-06-15", StartTime: "10:00", EndTime: "13:00", Role: "Cat Socializing", Status: "scheduled"},
+		{ID: 2, VolunteerID: 1, Date: "2026-06-16", StartTime: "10:00", EndTime: "12:00", Role: "Cat Socializing", Status: "cancelled"},
+		{ID: 3, VolunteerID: 1, Date: "2026-06-14", StartTime: "10:00", EndTime: "16:00", Role: "Cat Socializing", Status: "scheduled"}, // previous week
+	}
+	handOff := []*Shift{
+		{VolunteerID: 2, Date: "2026-06-17", StartTime: "09:00", EndTime: "11:00", Status: "scheduled"},
+		{VolunteerID: 3, Date: "2026-06-17", StartTime: "11:00", EndTime: "14:00", Status: "scheduled"},
+	}
+
+	if problems := CheckMinorShift(policy, shift, week, handOff); len(problems) != 0 {
+		t.Fatalf("want no problems; got %v", problems)
+	}
+
+	gap := []*Shift{
+		{VolunteerID: 2, Date: "2026-06-17", StartTime: "09:00", EndTime: "11:00", Status: "scheduled"},
+		{VolunteerID: 3, Date: "2026-06-17", StartTime: "11:30", EndTime: "14:00", Status: "scheduled"},
+	}
+	if problems := CheckMinorShift(policy, shift, week, gap); problems["volunteerId"] == "" {
+		t.Errorf("want supervision problem for a gap in adult coverage; got %v", problems)
+	}
+
+	long := *shift
+	long.Role = "Feeding/Cleaning"
+	long.EndTime = "16:00"
+	problems := CheckMinorShift(policy, &long, week, []*Shift{{VolunteerID: 2, Date: "2026-06-17", StartTime: "08:00", EndTime: "17:00", Status: "scheduled"}})
+	if problems["role"] == "" {
+		t.Errorf("want role problem; got %v", problems)
+	}
+	if problems["endTime"] == "" {
+		t.Errorf("want weekly hours problem (3 + 6 > 8); got %v", problems)
+	}
+}
+
+func TestStrandedMinorShifts(t *testing.T) {
+	minor := &Shift{ID: 1, VolunteerID: 1, Date: "2026-06-17", StartTime: "10:00", EndTime: "12:00", Status: "scheduled"}
+	adult := &Shift{ID: 2, VolunteerID: 2, Date: "2026-06-17", StartTime: "09:00", EndTime: "13:00", Status: "scheduled"}
+	backup := &Shift{ID: 3, VolunteerID: 3, Date: "2026-06-17", StartTime: "10:00", EndTime: "12:00", Status: "scheduled"}
+	minors := map[int64]bool{1: true}
+
+	cancelled := *adult
+	cancelled.Status = "cancelled"
+
+	if got := StrandedMinorShifts([]*Shift{minor, adult}, []*Shift{minor, &cancelled}, minors); len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("want the minor's shift stranded when the only adult cancels; got %v", got)
+	}
+	if got := StrandedMinorShifts([]*Shift{minor, adult, backup}, []*Shift{minor, backup}, minors); len(got) != 0 {
+		t.Errorf("want no stranded shifts while another adult covers; got %v", got)
+	}
+
+	// Already unsupervised before the change isn't this change's fault
+	if got := StrandedMinorShifts([]*Shift{minor}, []*Shift{minor}, minors); len(got) != 0 {
+		t.Errorf("want no stranded shifts when there was no cover to lose; got %v", got)
+	}
+}
@@ -0,0 +1,380 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+)
+
+type VolunteerYearStats struct {
+	VolunteerID      int64     `json:"volunteerId"`
+	Year             int       `json:"year"`
+	TotalHours       float64   `json:"totalHours"`
+	ShiftsCompleted  int       `json:"shiftsCompleted"`
+	ReliabilityScore int       `json:"reliabilityScore"`
+	Streak           int       `json:"streak"`
+	LongestStreak    int       `json:"longestStreak"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+type LeaderboardEntry struct {
+	Rank            int      `json:"rank"`
+	VolunteerID     int64    `json:"volunteerId"`
+	DisplayName     string   `json:"displayName"`
+	PhotoURL        string   `json:"photoUrl"`
+	TotalHours      float64  `json:"totalHours"`
+	ShiftsCompleted int      `json:"shiftsCompleted"`
+	Streak          int      `json:"streak"`
+	Badges          []string `json:"badges"`
+}
+
+type VolunteerStatsModel struct {
+	DB *sql.DB
+}
+
+// Upsert writes one year's snapshot for a volunteer.
+func (m VolunteerStatsModel) Upsert(s *VolunteerYearStats) error {
+	query := `
+		INSERT INTO volunteer_yearly_stats (volunteer_id, year, total_hours, shifts_completed, reliability_score, streak, longest_streak)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (volunteer_id, year) DO UPDATE
+		SET total_hours = EXCLUDED.total_hours,
+			shifts_completed = EXCLUDED.shifts_completed,
+			reliability_score = EXCLUDED.reliability_score,
+			streak = EXCLUDED.streak,
+			longest_streak = EXCLUDED.longest_streak,
+			updated_at = NOW()
+		RETURNING updated_at`
+
+	args := []any{s.VolunteerID, s.Year, s.TotalHours, s.ShiftsCompleted, s.ReliabilityScore, s.Streak, s.LongestStreak}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&s.UpdatedAt)
+}
+
+func (m VolunteerStatsModel) GetForVolunteer(volunteerID int64) ([]*VolunteerYearStats, error) {
+	query := `
+		SELECT volunteer_id, year, total_hours, shifts_completed, reliability_score, streak, longest_streak, updated_at
+		FROM volunteer_yearly_stats
+		WHERE volunteer_id = $1
+		ORDER BY year DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, volunteerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*VolunteerYearStats{}
+	for rows.Next() {
+		var s VolunteerYearStats
+		err := rows.Scan(&s.VolunteerID, &s.Year, &s.TotalHours, &s.ShiftsCompleted, &s.ReliabilityScore, &s.Streak, &s.LongestStreak, &s.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Leaderboard ranks the active, opted-in volunteers by hours for a year.
+// Only first name and last initial are exposed since the endpoint is public.
+func (m VolunteerStatsModel) Leaderboard(year int, limit int) ([]*LeaderboardEntry, error) {
+	query := `
+		SELECT v.id, v.first_name, COALESCE(v.last_name, ''), COALESCE(v.photo_url, ''), v.badges,
+		s.total_hours, s.shifts_completed, s.streak
+		FROM volunteer_yearly_stats s
+		JOIN volunteers v ON v.id = s.volunteer_id
+		WHERE s.year = $1 AND v.leaderboard_opt_in = true AND LOWER(v.status) = 'active' AND s.total_hours > 0
+		ORDER BY s.total_hours DESC, s.shifts_completed DESC, v.first_name ASC
+		LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, year, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*LeaderboardEntry{}
+	for rows.Next() {
+		var e LeaderboardEntry
+		var firstName, lastName string
+		err := rows.Scan(&e.VolunteerID, &firstName, &lastName, &e.PhotoURL, pq.Array(&e.Badges), &e.TotalHours, &e.ShiftsCompleted, &e.Streak)
+		if err != nil {
+			return nil, err
+		}
+		e.DisplayName = leaderboardName(firstName, lastName)
+		e.Rank = len(entries) + 1
+		entries = append(entries, &e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func leaderboardName(firstName, lastName string) string {
+	lastName = strings.TrimSpace(lastName)
+	if lastName == "" {
+		return firstName
+	}
+	initial, _ := utf8.DecodeRuneInString(lastName)
+	return firstName + " " + strings.ToUpper(string(initial)) + "."
+}
+
+// -------------------------------------------------------------------------
+//  STAT CALCULATION
+// -------------------------------------------------------------------------
+
+// ShiftWorked reports whether the volunteer actually showed up for the shift.
+func ShiftWorked(status string) bool {
+	return status == "completed" || status == "all_good" || status == "late"
+}
+
+// ShiftHours returns the length of a shift in hours, wrapping shifts that
+// run past midnight. Unparseable times count as zero.
+func ShiftHours(s *Shift) float64 {
+	start, err := ParseShiftTime(s.StartTime)
+	if err != nil {
+		return 0
+	}
+	end, err := ParseShiftTime(s.EndTime)
+	if err != nil {
+		return 0
+	}
+
+	duration := end.Sub(start).Hours()
+	if duration < 0 {
+		duration += 24
+	}
+	return duration
+}
+
+// shiftReliabilityPoints mirrors src/utils/reliability.ts on the frontend.
+func shiftReliabilityPoints(s *Shift) float64 {
+	score := 0.0
+	switch s.Status {
+	case "completed", "all_good":
+		score += 20
+	case "late":
+		score += 10
+	case "missed", "no_show":
+		score -= 50
+	case "covered", "covered_24h", "covered 24h":
+		score -= 5
+	case "covered_less_24h", "covered_late", "covered <24h notice", "covered late":
+		score -= 10
+	case "covered_less_1h", "covered <1h notice":
+		score -= 20
+	}
+
+	// Bonus for picking up someone else's shift
+	if strings.Contains(s.Notes, "Covering for") {
+		if strings.Contains(s.Notes, ">24h notice") {
+			score += 10
+		} else if strings.Contains(s.Notes, "<24h notice") {
+			score += 20
+		}
+	}
+
+	return score
+}
+
+// pastShiftsChronological drops scheduled shifts and unparseable dates and
+// returns the rest oldest first.
+func pastShiftsChronological(shifts []*Shift) []*Shift {
+	past := []*Shift{}
+	for _, s := range shifts {
+		if s.Status == "scheduled" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", s.Date); err != nil {
+			continue
+		}
+		past = append(past, s)
+	}
+
+	sort.SliceStable(past, func(i, j int) bool {
+		if past[i].Date != past[j].Date {
+			return past[i].Date < past[j].Date
+		}
+		a, _ := ParseShiftTime(past[i].StartTime)
+		b, _ := ParseShiftTime(past[j].StartTime)
+		return a.Before(b)
+	})
+
+	return past
+}
+
+// ComputeYearlyStats buckets a volunteer's shift history by calendar year.
+// Streak is the run of worked shifts ending at the most recent shift of that
+// year; LongestStreak is the best run within the year.
+func ComputeYearlyStats(volunteerID int64, shifts []*Shift) map[int]*VolunteerYearStats {
+	years := map[int]*VolunteerYearStats{}
+	scores := map[int]float64{}
+
+	for _, s := range pastShiftsChronological(shifts) {
+		date, _ := time.Parse("2006-01-02", s.Date)
+		stats, ok := years[date.Year()]
+		if !ok {
+			stats = &VolunteerYearStats{VolunteerID: volunteerID, Year: date.Year()}
+			years[date.Year()] = stats
+		}
+
+		scores[stats.Year] += shiftReliabilityPoints(s)
+
+		if ShiftWorked(s.Status) {
+			stats.TotalHours += ShiftHours(s)
+			stats.ShiftsCompleted++
+			stats.Streak++
+			if stats.Streak > stats.LongestStreak {
+				stats.LongestStreak = stats.Streak
+			}
+		} else {
+			stats.Streak = 0
+		}
+	}
+
+	for year, stats := range years {
+		score := scores[year]
+		if score > 100 {
+			score = 100
+		}
+		stats.ReliabilityScore = int(score)
+	}
+
+	return years
+}
+
+// -------------------------------------------------------------------------
+//  BADGES
+// -------------------------------------------------------------------------
+
+// VolunteerActivity is the lifetime summary the badge rules are evaluated against.
+type VolunteerActivity struct {
+	ShiftsCompleted    int
+	TotalHours         float64
+	LongestStreak      int
+	KittenSeasonShifts map[int]int // completed shifts per year during kitten season
+}
+
+type BadgeRule struct {
+	Name        string
+	Description string
+	Earned      func(a VolunteerActivity) bool
+}
+
+// Kitten season runs April through October, when intake peaks.
+const (
+	KittenSeasonStart      = time.April
+	KittenSeasonEnd        = time.October
+	KittenSeasonHeroShifts = 10
+)
+
+// BadgeRules are awarded automatically. Names match the frontend badge catalogue.
+var BadgeRules = []BadgeRule{
+	{
+		Name:        "New Recruit",
+		Description: "Completed your first shift!",
+		Earned:      func(a VolunteerActivity) bool { return a.ShiftsCompleted >= 1 },
+	},
+	{
+		Name:        "50 Hours",
+		Description: "Contributed 50 hours of service.",
+		Earned:      func(a VolunteerActivity) bool { return a.TotalHours >= 50 },
+	},
+	{
+		Name:        "10 Shift Streak",
+		Description: "Completed 10 shifts in a row without a miss or late cover.",
+		Earned:      func(a VolunteerActivity) bool { return a.LongestStreak >= 10 },
+	},
+	{
+		Name:        "Kitten Season Hero",
+		Description: "Completed 10 shifts during a single kitten season.",
+		Earned: func(a VolunteerActivity) bool {
+			for _, count := range a.KittenSeasonShifts {
+				if count >= KittenSeasonHeroShifts {
+					return true
+				}
+			}
+			return false
+		},
+	},
+}
+
+func SummarizeActivity(shifts []*Shift) VolunteerActivity {
+	activity := VolunteerActivity{KittenSeasonShifts: map[int]int{}}
+
+	streak := 0
+	for _, s := range pastShiftsChronological(shifts) {
+		if !ShiftWorked(s.Status) {
+			streak = 0
+			continue
+		}
+
+		activity.ShiftsCompleted++
+		activity.TotalHours += ShiftHours(s)
+
+		streak++
+		if streak > activity.LongestStreak {
+			activity.LongestStreak = streak
+		}
+
+		date, _ := time.Parse("2006-01-02", s.Date)
+		if date.Month() >= KittenSeasonStart && date.Month() <= KittenSeasonEnd {
+			activity.KittenSeasonShifts[date.Year()]++
+		}
+	}
+
+	return activity
+}
+
+// NewBadges returns the rule badges the volunteer has earned but doesn't hold
+// yet. Badges are never revoked, and manually granted ones are left alone.
+func NewBadges(activity VolunteerActivity, existing []string) []string {
+	awarded := []string{}
+	for _, rule := range BadgeRules {
+		if IsPermittedValue(rule.Name, existing...) {
+			continue
+		}
+		if rule.Earned(activity) {
+			awarded = append(awarded, rule.Name)
+		}
+	}
+	return awarded
+}
+
+// WorkedShiftsBetween returns the worked shifts dated from..to (inclusive,
+// YYYY-MM-DD) oldest first, with their total hours.
+func WorkedShiftsBetween(shifts []*Shift, from, to string) ([]*Shift, float64) {
+	worked := []*Shift{}
+	total := 0.0
+	for _, s := range pastShiftsChronological(shifts) {
+		if !ShiftWorked(s.Status) || s.Date < from || s.Date > to {
+			continue
+		}
+		worked = append(worked, s)
+		total += ShiftHours(s)
+	}
+	return worked, total
+}
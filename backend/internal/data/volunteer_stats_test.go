@@ -0,0 +1,86 @@
+package data
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestComputeYearlyStats(t *testing.T) {
+	shifts := []*Shift{
+		{Date: "2025-12-30", StartTime: "09:00", EndTime: "12:00", Status: "completed"},
+		{Date: "2026-01-05", StartTime: "09:00", EndTime: "11:00", Status: "completed"},
+		{Date: "2026-01-06", StartTime: "09:00", EndTime: "11:00", Status: "no_show"},
+		{Date: "2026-01-07", StartTime: "1:00 PM", EndTime: "3:30 PM", Status: "late"},
+		{Date: "2026-01-08", StartTime: "09:00", EndTime: "11:00", Status: "scheduled"},
+	}
+
+	years := ComputeYearlyStats(7, shifts)
+	if len(years) != 2 {
+		t.Fatalf("want 2 years; got %d", len(years))
+	}
+
+	prior := years[2025]
+	if prior.TotalHours != 3 || prior.ShiftsCompleted != 1 || prior.Streak != 1 {
+		t.Errorf("2025: unexpected stats %+v", prior)
+	}
+
+	current := years[2026]
+	if current.TotalHours != 4.5 {
+		t.Errorf("2026: want 4.5 hours; got %v", current.TotalHours)
+	}
+	if current.Streak != 1 || current.LongestStreak != 1 {
+		t.Errorf("2026: want streak 1/1; got %d/%d", current.Streak, current.LongestStreak)
+	}
+	// 20 (completed) - 50 (no show) + 10 (late)
+	if current.ReliabilityScore != -20 {
+		t.Errorf("2026: want reliability -20; got %d", current.ReliabilityScore)
+	}
+}
+
+func TestNewBadges(t *testing.T) {
+	var shifts []*Shift
+	for day := 1; day <= 12; day++ {
+		shifts = append(shifts, &Shift{
+			Date:      fmt.Sprintf("2026-05-%02d", day),
+			StartTime: "08:00",
+			EndTime:   "13:00",
+			Status:    "completed",
+		})
+	}
+
+	activity := SummarizeActivity(shifts)
+	awarded := NewBadges(activity, []string{"New Recruit", "Cat Whisperer"})
+
+	want := []string{"50 Hours", "10 Shift Streak", "Kitten Season Hero"}
+	if len(awarded) != len(want) {
+		t.Fatalf("want %v; got %v", want, awarded)
+	}
+	for i := range want {
+		if awarded[i] != want[i] {
+			t.Errorf("want %v; got %v", want, awarded)
+		}
+	}
+
+	// A single winter shift only earns the first-shift badge
+	winter := SummarizeActivity([]*Shift{{Date: "2026-01-10", StartTime: "09:00", EndTime: "10:00", Status: "all_good"}})
+	if got := NewBadges(winter, nil); len(got) != 1 || got[0] != "New Recruit" {
+		t.Errorf("want [New Recruit]; got %v", got)
+	}
+}
+
+func TestLeaderboardName(t *testing.T) {
+	tests := []struct {
+		first, last, want string
+	}{
+		{"Maya", "Lopez", "Maya L."},
+		{"Maya", "  lopez ", "Maya L."},
+		{"Zoë", "Ångström", "Zoë Å."},
+		{"Sam", "", "Sam"},
+	}
+
+	for _, tt := range tests {
+		if got := leaderboardName(tt.first, tt.last); got != tt.want {
+			t.Errorf("leaderboardName(%q, %q) = %q, want %q", tt.first, tt.last, got, tt.want)
+		}
+	}
+}
@@ -32,6 +32,8 @@ type VolunteerApplication struct {
 	SignatureData         *string   `json:"signatureData"`
 	SignatureDate         string    `json:"signatureDate"`
 	ParentName            string    `json:"parentName"`
+	ParentEmail           string    `json:"parentEmail"`
+	ParentPhone           string    `json:"parentPhone"`
 	ParentSignatureData   *string   `json:"parentSignatureData"`
 	ParentSignatureDate   string    `json:"parentSignatureDate"`
 	Status                string    `json:"status"`
@@ -84,19 +86,10 @@ func ValidateVolunteerApplication(v *validator.Validator, application *Volunteer
 
 	var isUnder21 bool
 	if application.Birthday != "" {
-		t, err := time.Parse("2006-01-02", application.Birthday)
-		if err != nil {
-			// Try parsing MM/DD/YYYY
-			t, err = time.Parse("01/02/2006", application.Birthday)
-		}
-
+		t, err := parseBirthday(application.Birthday)
 		if err == nil {
-			now := time.Now()
-			age := now.Year() - t.Year()
-			if now.YearDay() < t.YearDay() {
-				age--
-			}
-			if age < 21 {
+			age := ageOn(t, time.Now())
+			if age < AdultVolunteerAge {
 				isUnder21 = true
 			}
 
@@ -124,6 +117,11 @@ func ValidateVolunteerApplication(v *validator.Validator, application *Volunteer
 	v.Check(application.SignatureData != nil && *application.SignatureData != "", "signatureData", "must be provided")
 	if isUnder21 {
 		v.Check(application.ParentName != "", "parentName", "must be provided for applicants under 21")
+		v.Check(application.ParentEmail != "", "parentEmail", "must be provided for applicants under 21")
+		if application.ParentEmail != "" {
+			v.Check(validator.Matches(application.ParentEmail, validator.EmailRX), "parentEmail", "must be a valid email address")
+		}
+		v.Check(application.ParentPhone != "", "parentPhone", "must be provided for applicants under 21")
 		v.Check(application.ParentSignatureDate != "", "parentSignatureDate", "must be provided for applicants under 21")
 		v.Check(application.ParentSignatureData != nil && *application.ParentSignatureData != "", "parentSignatureData", "must be provided for applicants under 21")
 	}
@@ -160,6 +158,10 @@ type Volunteer struct {
 	EmergencyContactPhone string    `json:"emergencyContactPhone"`
 	InterestReason        string    `json:"interestReason"`
 	VolunteerExperience   string    `json:"volunteerExperience"`
+	ParentName            string    `json:"parentName"`
+	ParentEmail           string    `json:"parentEmail"`
+	ParentPhone           string    `json:"parentPhone"`
+	LeaderboardOptIn      bool      `json:"leaderboardOptIn"`
 	Version               int       `json:"version"`
 }
 
@@ -169,9 +171,10 @@ func (m VolunteerModel) InsertGetId(v *Volunteer) error {
 			first_name, last_name, email, phone, address, city, zip, role, status,
 			bio, photo_url, reliability_score, total_hours, streak, join_date, allergies,
 			skills, position_preferences, availability, badges,
-			birthday, emergency_contact_name, emergency_contact_phone, interest_reason, volunteer_experience
+			birthday, emergency_contact_name, emergency_contact_phone, interest_reason, volunteer_experience,
+			parent_name, parent_email, parent_phone
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28)
 		RETURNING id, created_at, updated_at, version`
 
 	// Helper to handle empty strings for dates
@@ -189,6 +192,7 @@ func (m VolunteerModel) InsertGetId(v *Volunteer) error {
 		v.Bio, v.PhotoURL, v.ReliabilityScore, v.TotalHours, v.Streak, joinDate, v.Allergies,
 		pq.Array(v.Skills), pq.Array(v.PositionPreferences), pq.Array(v.Availability), pq.Array(v.Badges),
 		birthday, v.EmergencyContactName, v.EmergencyContactPhone, v.InterestReason, v.VolunteerExperience,
+		v.ParentName, v.ParentEmail, v.ParentPhone,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -207,7 +211,7 @@ func (m VolunteerModel) GetAll(firstName string, lastName string, role string, s
 		COALESCE(emergency_contact_phone, '') as emergency_contact_phone,
 		COALESCE(interest_reason, '') as interest_reason,
 		COALESCE(volunteer_experience, '') as volunteer_experience,
-		version
+		parent_name, parent_email, parent_phone, leaderboard_opt_in, version
 		FROM volunteers
 		WHERE (to_tsvector('simple', first_name) @@ plainto_tsquery('simple', $1) OR $1 = '')
 		AND (LOWER(role) = LOWER($2) OR $2 = '')
@@ -262,6 +266,10 @@ func (m VolunteerModel) GetAll(firstName string, lastName string, role string, s
 			&v.EmergencyContactPhone,
 			&v.InterestReason,
 			&v.VolunteerExperience,
+			&v.ParentName,
+			&v.ParentEmail,
+			&v.ParentPhone,
+			&v.LeaderboardOptIn,
 			&v.Version,
 		)
 		if err != nil {
@@ -284,7 +292,8 @@ func (m VolunteerModel) Get(id int64) (*Volunteer, error) {
 	query := `
 		SELECT id, created_at, updated_at, first_name, last_name, email, phone, address, city, zip, role, status,
 		bio, photo_url, reliability_score, total_hours, streak, join_date, allergies, skills, position_preferences, availability, badges,
-		COALESCE(TO_CHAR(birthday, 'YYYY-MM-DD'), '') as birthday, emergency_contact_name, emergency_contact_phone, interest_reason, volunteer_experience, version
+		COALESCE(TO_CHAR(birthday, 'YYYY-MM-DD'), '') as birthday, emergency_contact_name, emergency_contact_phone, interest_reason, volunteer_experience,
+		parent_name, parent_email, parent_phone, leaderboard_opt_in, version
 		FROM volunteers
 		WHERE id = $1`
 
@@ -300,7 +309,7 @@ func (m VolunteerModel) Get(id int64) (*Volunteer, error) {
 		&v.Bio, &v.PhotoURL, &v.ReliabilityScore, &v.TotalHours, &v.Streak,
 		&joinDate, &v.Allergies, pq.Array(&v.Skills), pq.Array(&v.PositionPreferences), pq.Array(&v.Availability), pq.Array(&v.Badges),
 		&v.Birthday, &v.EmergencyContactName, &v.EmergencyContactPhone, &v.InterestReason, &v.VolunteerExperience,
-		&v.Version,
+		&v.ParentName, &v.ParentEmail, &v.ParentPhone, &v.LeaderboardOptIn, &v.Version,
 	)
 
 	if err != nil {
@@ -323,7 +332,7 @@ func (m VolunteerModel) Update(v *Volunteer) error {
 		    streak = $14, join_date = $15, allergies = $16, skills = $17, position_preferences = $18,
 		    availability = $19, badges = $20, birthday = $21, emergency_contact_name = $22,
 			emergency_contact_phone = $23, interest_reason = $24, volunteer_experience = $25,
-			updated_at = NOW(), version = version + 1
+			leaderboard_opt_in = $28, parent_name = $29, parent_email = $30, parent_phone = $31, updated_at = NOW(), version = version + 1
 		WHERE id = $26 AND version = $27
 		RETURNING updated_at, version`
 
@@ -343,7 +352,7 @@ func (m VolunteerModel) Update(v *Volunteer) error {
 		v.Streak, joinDate, v.Allergies, pq.Array(v.Skills), pq.Array(v.PositionPreferences),
 		pq.Array(v.Availability), pq.Array(v.Badges),
 		birthday, v.EmergencyContactName, v.EmergencyContactPhone, v.InterestReason, v.VolunteerExperience,
-		v.ID, v.Version,
+		v.ID, v.Version, v.LeaderboardOptIn, v.ParentName, v.ParentEmail, v.ParentPhone,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -361,10 +370,13 @@ func (m VolunteerModel) Update(v *Volunteer) error {
 	return nil
 }
 
+// UpdateStats stores the shift-derived stats. Like AwardBadges it leaves the
+// version alone, since a recalculation isn't an edit a coordinator could have
+// raced; a stale edit that overwrites the stats is corrected next time round.
 func (m VolunteerModel) UpdateStats(id int64, reliability int, hours int, streak int) error {
 	query := `
 		UPDATE volunteers
-		SET reliability_score = $1, total_hours = $2, streak = $3, updated_at = NOW(), version = version + 1
+		SET reliability_score = $1, total_hours = $2, streak = $3, updated_at = NOW()
 		WHERE id = $4`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -373,3 +385,45 @@ func (m VolunteerModel) UpdateStats(id int64, reliability int, hours int, streak
 	_, err := m.DB.ExecContext(ctx, query, reliability, hours, streak, id)
 	return err
 }
+
+// AwardBadges appends badges the volunteer doesn't already hold. It neither
+// checks nor bumps the version, so background recalculation never turns a
+// coordinator's open edit into a conflict. If that edit then saves a stale
+// badge list, the next recalculation awards the missing badges again.
+func (m VolunteerModel) AwardBadges(id int64, badges []string) error {
+	query := `
+		UPDATE volunteers
+		SET badges = badges || ARRAY(SELECT b FROM unnest($1::text[]) AS b WHERE NOT b = ANY(badges))
+		WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, pq.Array(badges), id)
+	return err
+}
+
+// GetAllIDs returns every volunteer ID, used by the yearly stats rollover.
+func (m VolunteerModel) GetAllIDs() ([]int64, error) {
+	query := `SELECT id FROM volunteers ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,179 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockProvider is a minimal OIDC provider: discovery, JWKS and a token
+// endpoint that returns whatever ID token claims the test sets.
+type mockProvider struct {
+	*httptest.Server
+	key      *rsa.PrivateKey
+	kid      string
+	claims   map[string]any
+	verifier string // code_verifier seen by the token endpoint
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockProvider{key: key, kid: "test-key"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": m.kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "good-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		m.verifier = r.Form.Get("code_verifier")
+		json.NewEncoder(w).Encode(map[string]string{"id_token": m.sign(t, "RS256", m.claims)})
+	})
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *mockProvider) sign(t *testing.T, alg string, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": m.kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (m *mockProvider) validClaims(nonce string) map[string]any {
+	return map[string]any{
+		"iss":            m.URL,
+		"sub":            "1234567890",
+		"aud":            "client-123",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          "board@idohr.org",
+		"email_verified": true,
+		"name":           "Board Member",
+		"hd":             "idohr.org",
+	}
+}
+
+func TestProviderFlow(t *testing.T) {
+	mock := newMockProvider(t)
+	p := New(Config{Issuer: mock.URL, ClientID: "client-123", ClientSecret: "secret", RedirectURL: "https://api.example/callback"})
+	ctx := context.Background()
+
+	verifier, _ := RandomString()
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if !strings.HasPrefix(authURL, mock.URL+"/authorize?") || q.Get("state") != "state-1" || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected auth URL %s", authURL)
+	}
+	if q.Get("code_challenge") != PKCEChallenge(verifier) {
+		t.Error("code challenge does not match verifier")
+	}
+
+	mock.claims = mock.validClaims("nonce-1")
+	raw, err := p.Exchange(ctx, "good-code", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mock.verifier != verifier {
+		t.Error("code_verifier was not sent to the token endpoint")
+	}
+
+	claims, err := p.Verify(ctx, raw, "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Email != "board@idohr.org" || !claims.EmailVerified || claims.HostedDomain != "idohr.org" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	if _, err := p.Exchange(ctx, "bad-code", verifier); err == nil {
+		t.Error("want error for rejected code")
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	mock := newMockProvider(t)
+	p := New(Config{Issuer: mock.URL, ClientID: "client-123"})
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		edit  func(map[string]any)
+		alg   string
+		nonce string
+	}{
+		{"wrong nonce", func(c map[string]any) {}, "RS256", "other"},
+		{"wrong audience", func(c map[string]any) { c["aud"] = "someone-else" }, "RS256", "n"},
+		{"wrong issuer", func(c map[string]any) { c["iss"] = "https://evil.example" }, "RS256", "n"},
+		{"expired", func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, "RS256", "n"},
+		{"multi aud without azp", func(c map[string]any) { c["aud"] = []string{"client-123", "other"} }, "RS256", "n"},
+		{"alg swap", func(c map[string]any) {}, "HS256", "n"},
+	}
+
+	for _, tt := range tests {
+		claims := mock.validClaims("n")
+		tt.edit(claims)
+		if _, err := p.Verify(ctx, mock.sign(t, tt.alg, claims), tt.nonce); err == nil {
+			t.Errorf("%s: want error", tt.name)
+		}
+	}
+
+	// Tampered payload
+	token := mock.sign(t, "RS256", mock.validClaims("n"))
+	parts := strings.Split(token, ".")
+	forged, _ := json.Marshal(map[string]any{"iss": mock.URL, "aud": "client-123", "email": "attacker@evil.example", "nonce": "n", "exp": time.Now().Add(time.Hour).Unix()})
+	parts[1] = base64.RawURLEncoding.EncodeToString(forged)
+	if _, err := p.Verify(ctx, strings.Join(parts, "."), "n"); err == nil {
+		t.Error("tampered payload: want error")
+	}
+}
@@ -0,0 +1,264 @@
+// Package forecast projects the rescue's population a few weeks ahead from
+// its own intake and adoption history.
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+)
+
+const (
+	MinWeeks = 4
+	MaxWeeks = 12
+
+	// historyYears of records are used for seasonality; recentDays set the
+	// current pace.
+	historyYears = 3
+	recentDays   = 84
+
+	// priorDays is how many days of history a month needs before its own
+	// seasonality counts as much as the kitten-season prior.
+	priorDays = 30
+
+	// z80 gives an 80% interval around the projection.
+	z80 = 1.28
+)
+
+// kittenSeason is the usual shape of cat intake by month (January first),
+// averaging 1. Adoptions follow about two months behind, once kittens are
+// old enough to go home.
+var kittenSeason = [12]float64{0.6, 0.6, 0.8, 1.1, 1.5, 1.6, 1.4, 1.2, 1.0, 0.9, 0.7, 0.6}
+
+// Week is one projected week.
+type Week struct {
+	Start        string  `json:"start"`
+	Intakes      float64 `json:"intakes"`
+	Adoptions    float64 `json:"adoptions"`
+	Population   int     `json:"population"`
+	Low          int     `json:"low"`
+	High         int     `json:"high"`
+	Seasonality  float64 `json:"seasonality"`
+	OverCapacity bool    `json:"overCapacity"`
+}
+
+type Forecast struct {
+	Species          string      `json:"species"`
+	Census           data.Census `json:"census"`
+	Capacity         int         `json:"capacity"`
+	IntakesPerWeek   float64     `json:"intakesPerWeek"`
+	AdoptionsPerWeek float64     `json:"adoptionsPerWeek"`
+	Weeks            []Week      `json:"weeks"`
+	FullBy           *string     `json:"fullBy"`
+	Recommendations  []string    `json:"recommendations"`
+}
+
+// series is a daily event history reduced to what the projection needs:
+// a seasonal index per month and the recent deseasonalized pace.
+type series struct {
+	index [12]float64
+	rate  float64 // events per day at an index of 1
+}
+
+// Project forecasts weeks weeks from now. species limits the history to one
+// species; empty means all. Only cats get the kitten-season prior.
+func Project(flows []data.PetFlow, census data.Census, species string, now time.Time, weeks int) Forecast {
+	weeks = min(max(weeks, MinWeeks), MaxWeeks)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var intakes, adoptions []time.Time
+	for _, f := range flows {
+		if species != "" && f.Species != species {
+			continue
+		}
+		if f.Intake != nil {
+			intakes = append(intakes, *f.Intake)
+		}
+		if f.Adopted != nil {
+			adoptions = append(adoptions, *f.Adopted)
+		}
+	}
+
+	intakePrior := flat()
+	adoptionPrior := flat()
+	if species == "" || species == "cat" {
+		intakePrior = kittenSeason
+		for m := range adoptionPrior {
+			adoptionPrior[m] = kittenSeason[(m+10)%12]
+		}
+	}
+
+	in := newSeries(intakes, intakePrior, today)
+	out := newSeries(adoptions, adoptionPrior, today)
+
+	f := Forecast{
+		Species:          species,
+		Census:           census,
+		Capacity:         census.Capacity(),
+		IntakesPerWeek:   round1(in.rate * 7),
+		AdoptionsPerWeek: round1(out.rate * 7),
+		Weeks:            make([]Week, 0, weeks),
+		Recommendations:  []string{},
+	}
+	if f.Species == "" {
+		f.Species = "all"
+	}
+
+	population := float64(census.InCare)
+	variance := 0.0
+	peak := Week{}
+	for i := 0; i < weeks; i++ {
+		start := today.AddDate(0, 0, 7*i)
+		var wIn, wOut, season float64
+		for d := 0; d < 7; d++ {
+			month := start.AddDate(0, 0, d).Month() - 1
+			wIn += in.rate * in.index[month]
+			wOut += out.rate * out.index[month]
+			season += in.index[month] / 7
+		}
+
+		// Adoptions can't take out pets we don't have
+		wOut = min(wOut, population+wIn)
+		population += wIn - wOut
+		// Counts are roughly Poisson, so the spread grows with the flow
+		variance += wIn + wOut
+		spread := z80 * math.Sqrt(variance)
+
+		w := Week{
+			Start:       start.Format(time.DateOnly),
+			Intakes:     round1(wIn),
+			Adoptions:   round1(wOut),
+			Population:  int(math.Round(population)),
+			Low:         int(math.Round(max(population-spread, 0))),
+			High:        int(math.Round(population + spread)),
+			Seasonality: round1(season),
+		}
+		if f.Capacity > 0 && w.Population > f.Capacity {
+			w.OverCapacity = true
+			if f.FullBy == nil {
+				fullBy := w.Start
+				f.FullBy = &fullBy
+			}
+		}
+		if w.Intakes > peak.Intakes {
+			peak = w
+		}
+		f.Weeks = append(f.Weeks, w)
+	}
+
+	f.Recommendations = recommend(f, peak)
+	return f
+}
+
+func recommend(f Forecast, peak Week) []string {
+	recs := []string{}
+	if len(f.Weeks) == 0 {
+		return recs
+	}
+	last := f.Weeks[len(f.Weeks)-1]
+
+	if f.Capacity == 0 {
+		recs = append(recs, "Set up shelter locations and foster capacities to compare the forecast against capacity.")
+	} else if f.FullBy != nil {
+		recs = append(recs, fmt.Sprintf("Population is expected to pass capacity (%d) the week of %s; pause non-urgent surrenders before then.", f.Capacity, *f.FullBy))
+		if over := last.Population - f.Capacity; over > 0 {
+			perWeek := int(math.Ceil(float64(over) / float64(len(f.Weeks))))
+			recs = append(recs, fmt.Sprintf("About %d more adoptions a week would keep population within capacity; consider an adoption event.", perWeek))
+		}
+	} else {
+		for _, w := range f.Weeks {
+			if w.High > f.Capacity {
+				recs = append(recs, fmt.Sprintf("A busy stretch could reach capacity (%d) by the week of %s; keep an eye on surrender scheduling.", f.Capacity, w.Start))
+				break
+			}
+		}
+	}
+
+	if peak.Seasonality >= 1.3 {
+		recs = append(recs, fmt.Sprintf("Kitten season: intakes are expected to peak around the week of %s.", peak.Start))
+	}
+
+	return recs
+}
+
+func newSeries(events []time.Time, prior [12]float64, today time.Time) series {
+	s := series{index: prior}
+
+	from := today.AddDate(-historyYears, 0, 0)
+	earliest := today
+	for _, e := range events {
+		if !e.Before(from) && e.Before(earliest) {
+			earliest = e
+		}
+	}
+	if !earliest.Before(today) {
+		return s
+	}
+
+	// Count events and days per calendar month, from the first record on,
+	// so months before there was any data don't read as quiet ones
+	var counts, days [12]float64
+	for d := earliest; d.Before(today); d = d.AddDate(0, 0, 1) {
+		days[d.Month()-1]++
+	}
+	total := 0.0
+	for _, e := range events {
+		if !e.Before(earliest) && e.Before(today) {
+			counts[e.Month()-1]++
+			total++
+		}
+	}
+
+	allDays := 0.0
+	for _, n := range days {
+		allDays += n
+	}
+	if total == 0 {
+		return s
+	}
+	overall := total / allDays
+
+	// Blend each month's observed index with the prior, trusting the
+	// observation more as that month accumulates history
+	for m := range s.index {
+		if days[m] == 0 {
+			continue
+		}
+		observed := counts[m] / days[m] / overall
+		s.index[m] = (observed*days[m] + prior[m]*priorDays) / (days[m] + priorDays)
+	}
+
+	// The current pace, taken over the last few weeks and divided by how busy
+	// those weeks usually are
+	recentFrom := today.AddDate(0, 0, -recentDays)
+	if recentFrom.Before(earliest) {
+		recentFrom = earliest
+	}
+	recent, season := 0.0, 0.0
+	for _, e := range events {
+		if !e.Before(recentFrom) && e.Before(today) {
+			recent++
+		}
+	}
+	for d := recentFrom; d.Before(today); d = d.AddDate(0, 0, 1) {
+		season += s.index[d.Month()-1]
+	}
+	if season > 0 {
+		s.rate = recent / season
+	}
+	return s
+}
+
+func flat() [12]float64 {
+	var f [12]float64
+	for m := range f {
+		f[m] = 1
+	}
+	return f
+}
+
+func round1(f float64) float64 {
+	return math.Round(f*10) / 10
+}
@@ -0,0 +1,82 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+)
+
+func day(s string) *time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return &t
+}
+
+// steady builds two years of dog history: perDay intakes each day, and
+// adoptions at the same pace.
+func steady(now time.Time, perDay int) []data.PetFlow {
+	var flows []data.PetFlow
+	for d := now.AddDate(-2, 0, 0); d.Before(now); d = d.AddDate(0, 0, 1) {
+		for i := 0; i < perDay; i++ {
+			in, out := d, d
+			flows = append(flows, data.PetFlow{Species: "dog", Intake: &in, Adopted: &out})
+		}
+	}
+	return flows
+}
+
+func TestProjectSteady(t *testing.T) {
+	now := *day("2025-03-01")
+	f := Project(steady(now, 1), data.Census{InCare: 40, ShelterCapacity: 50}, "dog", now, 8)
+
+	if len(f.Weeks) != 8 {
+		t.Fatalf("got %d weeks; want 8", len(f.Weeks))
+	}
+	// Dogs have no seasonal prior, so a flat history stays flat
+	if f.IntakesPerWeek < 6.5 || f.IntakesPerWeek > 7.5 {
+		t.Errorf("IntakesPerWeek = %v; want about 7", f.IntakesPerWeek)
+	}
+	for _, w := range f.Weeks {
+		if w.Population < 35 || w.Population > 45 {
+			t.Errorf("week %s population = %d; want about 40", w.Start, w.Population)
+		}
+		if w.Low > w.Population || w.High < w.Population {
+			t.Errorf("week %s interval [%d, %d] doesn't contain %d", w.Start, w.Low, w.High, w.Population)
+		}
+	}
+	if f.FullBy != nil {
+		t.Errorf("FullBy = %s; want nil", *f.FullBy)
+	}
+}
+
+func TestProjectKittenSeason(t *testing.T) {
+	now := *day("2025-04-15")
+	// Intakes only, with no history: the prior alone drives the shape
+	flows := []data.PetFlow{}
+	for d := now.AddDate(0, 0, -28); d.Before(now); d = d.AddDate(0, 0, 1) {
+		in := d
+		flows = append(flows, data.PetFlow{Species: "cat", Intake: &in})
+	}
+
+	f := Project(flows, data.Census{InCare: 10, ShelterCapacity: 20, FosterCapacity: 10}, "", now, 12)
+
+	if f.Weeks[len(f.Weeks)-1].Intakes <= f.Weeks[0].Intakes {
+		t.Errorf("intakes should rise into summer: first %v, last %v", f.Weeks[0].Intakes, f.Weeks[len(f.Weeks)-1].Intakes)
+	}
+	if f.FullBy == nil {
+		t.Fatal("with no adoptions, population should pass capacity")
+	}
+	if len(f.Recommendations) == 0 {
+		t.Error("expected recommendations")
+	}
+}
+
+func TestProjectClampsWeeks(t *testing.T) {
+	now := *day("2025-01-10")
+	if got := len(Project(nil, data.Census{}, "", now, 1).Weeks); got != MinWeeks {
+		t.Errorf("got %d weeks; want %d", got, MinWeeks)
+	}
+	if got := len(Project(nil, data.Census{}, "", now, 52).Weeks); got != MaxWeeks {
+		t.Errorf("got %d weeks; want %d", got, MaxWeeks)
+	}
+}
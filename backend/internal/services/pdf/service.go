@@ -1,12 +1,193 @@
 package pdf
 
-// TODO: Phase 18 - Document Generator
-// Goal: Generate PDF contracts for adoptions.
-// Dependencies: Likely "github.com/jung-kurt/gofpdf" or similar.
-
-// GenerateAdoptionContract creates a PDF populated with Pet and Adopter data.
-// func GenerateAdoptionContract(pet models.Pet, adopter models.Adopter) ([]byte, error) {
-// 	   // 1. Load Template
-//     // 2. Replace placeholders ({{Name}}, {{Date}})
-//     // 3. Return bytes
-// }
+// Document Generator
+// A deliberately small PDF writer (standard Helvetica fonts, lines and JPEG
+// images) so letters and reports can be produced without a third-party library.
+// Coordinates are in points from the top-left corner of a US Letter page.
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"strings"
+)
+
+const (
+	PageWidth  = 612.0
+	PageHeight = 792.0
+)
+
+type jpegImage struct {
+	data          []byte
+	width, height int
+}
+
+type Document struct {
+	pages  []*bytes.Buffer
+	images []jpegImage
+}
+
+func New() *Document {
+	d := &Document{}
+	d.AddPage()
+	return d
+}
+
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) current() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws a single line of text with its baseline at y.
+func (d *Document) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.current(), "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PageHeight-y, escape(s))
+}
+
+// TextWidth approximates the width of s in Helvetica, good enough for wrapping and right-aligning.
+func TextWidth(s string, size float64) float64 {
+	return float64(len(s)) * size * 0.5
+}
+
+// Paragraph wraps s to the given width and returns the y position after the last line.
+func (d *Document) Paragraph(x, y, width, size float64, s string) float64 {
+	lineHeight := size * 1.4
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && TextWidth(candidate, size) > width {
+			d.Text(x, y, size, false, line)
+			y += lineHeight
+			line = word
+			continue
+		}
+		line = candidate
+	}
+	if line != "" {
+		d.Text(x, y, size, false, line)
+		y += lineHeight
+	}
+	return y
+}
+
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.current(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// JPEG places a JPEG image with its top-left corner at x, y scaled to width.
+// Returns the rendered height.
+func (d *Document) JPEG(data []byte, x, y, width float64) (float64, error) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	if cfg.ColorModel == color.CMYKModel {
+		// CMYK JPEGs need a different colour space; skip rather than emit a broken file
+		return 0, fmt.Errorf("unsupported jpeg colour model")
+	}
+
+	d.images = append(d.images, jpegImage{data: data, width: cfg.Width, height: cfg.Height})
+	height := width * float64(cfg.Height) / float64(cfg.Width)
+
+	fmt.Fprintf(d.current(), "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", width, height, x, PageHeight-y-height, len(d.images))
+	return height, nil
+}
+
+// Bytes serialises the document.
+func (d *Document) Bytes() []byte {
+	var out bytes.Buffer
+	offsets := []int{}
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+
+	// Object layout: 1 catalog, 2 pages, 3-4 fonts, then images, then page/content pairs
+	firstImage := 5
+	firstPage := firstImage + len(d.images)
+
+	kids := []string{}
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPage+i*2))
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	xobjects := []string{}
+	for i, img := range d.images {
+		colorSpace := "/DeviceRGB"
+		if isGrayJPEG(img.data) {
+			colorSpace = "/DeviceGray"
+		}
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n",
+			firstImage+i, img.width, img.height, colorSpace, len(img.data))
+		out.Write(img.data)
+		out.WriteString("\nendstream\nendobj\n")
+		xobjects = append(xobjects, fmt.Sprintf("/Im%d %d 0 R", i+1, firstImage+i))
+	}
+
+	resources := fmt.Sprintf("<< /Font << /F1 3 0 R /F2 4 0 R >> /XObject << %s >> >>", strings.Join(xobjects, " "))
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources %s /Contents %d 0 R >>",
+			PageWidth, PageHeight, resources, firstPage+i*2+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// escape converts s to a WinAnsi PDF string literal body.
+func escape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '–' || r == '—':
+			sb.WriteByte('-')
+		case r == '‘' || r == '’':
+			sb.WriteByte('\'')
+		case r == '“' || r == '”':
+			sb.WriteByte('"')
+		case r < 32:
+			sb.WriteByte(' ')
+		case r < 128:
+			sb.WriteRune(r)
+		case r < 256:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+func isGrayJPEG(data []byte) bool {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	return err == nil && cfg.ColorModel == color.GrayModel
+}
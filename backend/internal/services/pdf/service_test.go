@@ -0,0 +1,59 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestDocumentBytes(t *testing.T) {
+	var logo bytes.Buffer
+	if err := jpeg.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 40, 20)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := New()
+	height, err := doc.JPEG(logo.Bytes(), 60, 50, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 40 {
+		t.Errorf("want logo scaled to 40pt high; got %v", height)
+	}
+	doc.Text(60, 120, 12, true, "Certificate (draft) – Zoë")
+	doc.AddPage()
+	doc.Paragraph(60, 60, 200, 11, "A long enough sentence that it has to wrap onto more than one line of output.")
+
+	out := doc.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) {
+		t.Fatalf("missing PDF header")
+	}
+	if !bytes.Contains(out, []byte(`(Certificate \(draft\) - Zo\353)`)) {
+		t.Errorf("text was not escaped to WinAnsi")
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Errorf("want 2 pages")
+	}
+
+	// Every xref entry must point at the start of its object
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	if len(entries) == 0 {
+		t.Fatal("empty xref table")
+	}
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		want := fmt.Sprintf("%d 0 obj", i+1)
+		if !bytes.HasPrefix(out[offset:], []byte(want)) {
+			t.Errorf("xref entry %d does not point at %q", i+1, want)
+		}
+	}
+}
@@ -1,3 +1,74 @@
 package scoring
 
+import (
+	"github.com/cconner57/adoption-os/backend/internal/data"
+)
+
 // TODO: Phase 14 & 15 - Define algorithms for scoring adopters and volunteers
+
+// Foster scores a foster application out of 100. Each factor is something
+// the foster coordinator would otherwise check by hand; flags are answers
+// that need a conversation before approval rather than an automatic no.
+func Foster(a *data.FosterApplication) data.ApplicationScore {
+	score := data.ApplicationScore{Factors: []data.ScoreFactor{}, Flags: []string{}}
+
+	add := func(name string, points, outOf int) {
+		score.Factors = append(score.Factors, data.ScoreFactor{Name: name, Points: points, Max: outOf})
+		score.Total += points
+		score.Max += outOf
+	}
+	pick := func(ok bool, points int) int {
+		if ok {
+			return points
+		}
+		return 0
+	}
+
+	add("Fostered before", pick(a.FosteredBefore, 15), 15)
+	add("Bottle baby experience", pick(a.BottleBabyExperience, 15), 15)
+	add("Isolation room", pick(a.HasIsolationRoom, 15), 15)
+
+	landlordOK := a.HomeOwnership == "own" || (a.LandlordApproval != nil && *a.LandlordApproval)
+	add("Housing secure", pick(landlordOK, 15), 15)
+
+	petsOK := a.OtherPets == "" || (a.ResidentPetsVaccinated != nil && *a.ResidentPetsVaccinated)
+	add("Resident pets vaccinated", pick(petsOK, 10), 10)
+
+	alone := 0
+	switch {
+	case a.HoursHomeAlone <= 4:
+		alone = 10
+	case a.HoursHomeAlone <= 8:
+		alone = 5
+	}
+	add("Time at home", alone, 10)
+
+	add("Can transport to vet", pick(a.CanTransportToVet, 10), 10)
+
+	capacity := 0
+	switch {
+	case a.Capacity >= 2:
+		capacity = 10
+	case a.Capacity == 1:
+		capacity = 5
+	}
+	add("Capacity", capacity, 10)
+
+	if !a.HasIsolationRoom {
+		score.Flags = append(score.Flags, "No isolation room for new intakes")
+	}
+	for _, age := range a.AgePreferences {
+		if age == "bottle_baby" && !a.BottleBabyExperience {
+			score.Flags = append(score.Flags, "Wants bottle babies without prior experience")
+			break
+		}
+	}
+	if a.HomeOwnership == "rent" && !landlordOK {
+		score.Flags = append(score.Flags, "Renting without landlord approval")
+	}
+	if !petsOK {
+		score.Flags = append(score.Flags, "Resident pets are not vaccinated")
+	}
+
+	return score
+}
@@ -0,0 +1,62 @@
+package scoring
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+)
+
+func TestFoster(t *testing.T) {
+	yes, no := true, false
+
+	experienced := &data.FosterApplication{
+		HomeOwnership:          "rent",
+		LandlordApproval:       &yes,
+		HasIsolationRoom:       true,
+		FosteredBefore:         true,
+		BottleBabyExperience:   true,
+		OtherPets:              "One cat",
+		ResidentPetsVaccinated: &yes,
+		HoursHomeAlone:         2,
+		CanTransportToVet:      true,
+		Capacity:               3,
+		AgePreferences:         []string{"bottle_baby"},
+	}
+
+	score := Foster(experienced)
+	if score.Max != 100 {
+		t.Errorf("Max = %d, want 100", score.Max)
+	}
+	if score.Total != 100 {
+		t.Errorf("Total = %d, want 100", score.Total)
+	}
+	if len(score.Flags) != 0 {
+		t.Errorf("Flags = %v, want none", score.Flags)
+	}
+
+	first := &data.FosterApplication{
+		HomeOwnership:          "rent",
+		LandlordApproval:       &no,
+		OtherPets:              "A dog",
+		ResidentPetsVaccinated: &no,
+		HoursHomeAlone:         9,
+		Capacity:               1,
+		AgePreferences:         []string{"kitten", "bottle_baby"},
+	}
+
+	score = Foster(first)
+	if score.Total != 5 {
+		t.Errorf("Total = %d, want 5 (capacity only)", score.Total)
+	}
+	for _, want := range []string{
+		"No isolation room for new intakes",
+		"Wants bottle babies without prior experience",
+		"Renting without landlord approval",
+		"Resident pets are not vaccinated",
+	} {
+		if !slices.Contains(score.Flags, want) {
+			t.Errorf("Flags = %v, missing %q", score.Flags, want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package scoring
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+)
+
+// Surrender triages a surrender request. Urgency comes from how soon the
+// animal has to go; risk from its bite and escape history, since those are
+// the animals least likely to be placed anywhere else. Priority weights
+// urgency twice as heavily as risk.
+func Surrender(a *data.SurrenderApplication, now time.Time) data.SurrenderTriage {
+	t := data.SurrenderTriage{Reasons: []string{}}
+
+	days, ok := neededWithin(a.WhenToSurrenderAnimal, now)
+	switch {
+	case !ok:
+		t.Urgency = 50
+		t.Reasons = append(t.Reasons, "Timeframe unclear")
+	case days <= 1:
+		t.Urgency = 100
+	case days <= 7:
+		t.Urgency = 80
+	case days <= 14:
+		t.Urgency = 65
+	case days <= 30:
+		t.Urgency = 50
+	case days <= 60:
+		t.Urgency = 30
+	default:
+		t.Urgency = 15
+	}
+	if ok {
+		neededBy := now.AddDate(0, 0, days).Format(time.DateOnly)
+		t.NeededBy = &neededBy
+		if days <= 1 {
+			t.Reasons = append(t.Reasons, "Needed immediately")
+		} else {
+			t.Reasons = append(t.Reasons, fmt.Sprintf("Needed within %d days", days))
+		}
+	}
+
+	if data.IsYes(a.AnimalEverAttackedPeople) {
+		t.Risk += 50
+		t.Reasons = append(t.Reasons, "Has attacked people")
+	}
+	if data.IsYes(a.AnimalEscapedBefore) {
+		t.Risk += 20
+		t.Reasons = append(t.Reasons, "Has escaped before")
+	}
+	if data.IsYes(a.AnimalEverAttackedOtherCats) {
+		t.Risk += 15
+		t.Reasons = append(t.Reasons, "Has attacked other cats")
+	}
+	if data.IsYes(a.AnimalEverAttackedOtherDogs) {
+		t.Risk += 15
+		t.Reasons = append(t.Reasons, "Has attacked dogs")
+	}
+	t.Risk = min(t.Risk, 100)
+
+	t.Priority = (2*t.Urgency + t.Risk) / 3
+	switch {
+	case t.Priority >= 70:
+		t.Level = data.TriageHigh
+	case t.Priority >= 40:
+		t.Level = data.TriageMedium
+	default:
+		t.Level = data.TriageLow
+	}
+
+	return t
+}
+
+var (
+	timeframeWordRX   = regexp.MustCompile(`[a-z']+`)
+	timeframeAmountRX = regexp.MustCompile(`\b(\d+)\s*(day|week|month)s?\b`)
+)
+
+var timeframeDateLayouts = []string{"2006-01-02", "1/2/2006", "1/2/06", "January 2, 2006", "Jan 2, 2006", "January 2 2006", "Jan 2 2006"}
+
+// timeframePhrases map whole words or phrases to days from now, soonest first.
+var timeframePhrases = []struct {
+	words []string
+	days  int
+}{
+	{[]string{"asap"}, 0},
+	{[]string{"immediately"}, 0},
+	{[]string{"urgent"}, 0},
+	{[]string{"urgently"}, 0},
+	{[]string{"emergency"}, 0},
+	{[]string{"today"}, 0},
+	{[]string{"now"}, 0},
+	{[]string{"right", "away"}, 0},
+	{[]string{"tomorrow"}, 1},
+	{[]string{"this", "week"}, 7},
+	{[]string{"this", "weekend"}, 7},
+	{[]string{"next", "week"}, 14},
+	{[]string{"this", "month"}, 30},
+	{[]string{"next", "month"}, 60},
+}
+
+// timeframeNegations cancel a phrase when they come just before it.
+var timeframeNegations = map[string]bool{"not": true, "no": true, "never": true, "isn't": true, "isnt": true, "don't": true, "dont": true}
+
+// neededWithin reads the free-text "when do you need to surrender" answer
+// as a number of days from now. ok is false when it can't be read. An
+// explicit amount or date wins over words, so "2 months from now" is 60;
+// otherwise the soonest phrase that isn't negated counts.
+func neededWithin(text string, now time.Time) (days int, ok bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return 0, false
+	}
+
+	if m := timeframeAmountRX.FindStringSubmatch(text); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "week":
+			n *= 7
+		case "month":
+			n *= 30
+		}
+		return n, true
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for _, layout := range timeframeDateLayouts {
+		if d, err := time.Parse(layout, text); err == nil {
+			return max(int(d.Sub(today).Hours()/24), 0), true
+		}
+	}
+
+	words := timeframeWordRX.FindAllString(text, -1)
+	for _, phrase := range timeframePhrases {
+		for i := 0; i+len(phrase.words) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(phrase.words)], phrase.words) && !negated(words, i) {
+				return phrase.days, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// negated reports whether the word at i follows a negation, allowing one word
+// in between ("not that urgent").
+func negated(words []string, i int) bool {
+	for j := max(i-2, 0); j < i; j++ {
+		if timeframeNegations[words[j]] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package scoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cconner57/adoption-os/backend/internal/data"
+)
+
+func TestNeededWithin(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text string
+		days int
+		ok   bool
+	}{
+		{"ASAP", 0, true},
+		{"Need to rehome today!", 0, true},
+		{"tomorrow", 1, true},
+		{"Sometime this week", 7, true},
+		{"within 3 weeks", 21, true},
+		{"2 months", 60, true},
+		{"10 days", 10, true},
+		{"2025-03-20", 10, true},
+		{"3/1/2025", 0, true}, // already passed
+		{"2 months from now", 60, true},
+		{"not urgent", 0, false},
+		{"not urgent, maybe next month", 60, true},
+		{"I don't know", 0, false},
+		{"know", 0, false},
+		{"tomorrow or next month", 1, true},
+		{"this weekend", 7, true},
+		{"not sure", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		days, ok := neededWithin(tt.text, now)
+		if days != tt.days || ok != tt.ok {
+			t.Errorf("neededWithin(%q) = %d, %v; want %d, %v", tt.text, days, ok, tt.days, tt.ok)
+		}
+	}
+}
+
+func TestSurrender(t *testing.T) {
+	now := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	urgent := Surrender(&data.SurrenderApplication{
+		WhenToSurrenderAnimal:    "immediately, we are moving",
+		AnimalEverAttackedPeople: "Yes - bit a child",
+		AnimalEscapedBefore:      "No",
+	}, now)
+	if urgent.Urgency != 100 || urgent.Risk != 50 || urgent.Level != data.TriageHigh {
+		t.Errorf("urgent = %+v; want urgency 100, risk 50, high", urgent)
+	}
+	if urgent.NeededBy == nil || *urgent.NeededBy != "2025-03-10" {
+		t.Errorf("NeededBy = %v; want 2025-03-10", urgent.NeededBy)
+	}
+
+	relaxed := Surrender(&data.SurrenderApplication{
+		WhenToSurrenderAnimal:    "in about 3 months",
+		AnimalEverAttackedPeople: "No",
+		AnimalEscapedBefore:      "No",
+	}, now)
+	if relaxed.Level != data.TriageLow || relaxed.Risk != 0 {
+		t.Errorf("relaxed = %+v; want low with no risk", relaxed)
+	}
+	if urgent.Priority <= relaxed.Priority {
+		t.Errorf("urgent priority %d should be above relaxed %d", urgent.Priority, relaxed.Priority)
+	}
+}
@@ -0,0 +1,54 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+func TestCodeRFC6238(t *testing.T) {
+	// SHA1 vectors from RFC 6238 appendix B, truncated to 6 digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(secret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("at %d: want %s; got %s", tt.unix, tt.want, got)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	previous, _ := Code(secret, Step(now)-1)
+	if step, ok := Validate(secret, previous, now); !ok || step != Step(now)-1 {
+		t.Errorf("want previous step accepted; got %v %v", step, ok)
+	}
+
+	stale, _ := Code(secret, Step(now)-2)
+	if _, ok := Validate(secret, stale, now); ok {
+		t.Error("want code two steps old rejected")
+	}
+
+	if _, ok := Validate(secret, "12345", now); ok {
+		t.Error("want short code rejected")
+	}
+}
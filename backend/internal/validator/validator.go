@@ -49,3 +49,15 @@ func PermittedValue[T comparable](value T, permittedValues ...T) bool {
 	}
 	return false
 }
+
+// Unique returns true if all values in a slice are unique.
+func Unique[T comparable](values []T) bool {
+	seen := make(map[T]bool, len(values))
+	for _, value := range values {
+		if seen[value] {
+			return false
+		}
+		seen[value] = true
+	}
+	return true
+}
@@ -66,3 +66,12 @@ func TestPermittedValue(t *testing.T) {
 		t.Error("want permitted int")
 	}
 }
+
+func TestUnique(t *testing.T) {
+	if !Unique([]string{"a", "b"}) {
+		t.Error("want unique values to pass")
+	}
+	if Unique([]string{"a", "b", "a"}) {
+		t.Error("want duplicate values to fail")
+	}
+}
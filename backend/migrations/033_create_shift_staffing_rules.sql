@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS shift_staffing_rules (
+    id bigserial PRIMARY KEY,
+    role text NOT NULL,
+    day_part text NOT NULL, -- 'morning', 'afternoon', 'evening'
+    min_volunteers integer NOT NULL DEFAULT 0,
+    capacity integer NOT NULL DEFAULT 0, -- 0 means unlimited
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (role, day_part)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shifts_date_role ON shifts(date, role);
+
+-- Seed sensible defaults for the daily cleaning rotation
+INSERT INTO shift_staffing_rules (role, day_part, min_volunteers, capacity)
+VALUES
+    ('Feeding/Cleaning', 'morning', 2, 4),
+    ('Feeding/Cleaning', 'evening', 1, 3),
+    ('Cat Socializing', 'afternoon', 0, 4),
+    ('Customer Support', 'afternoon', 0, 2)
+ON CONFLICT (role, day_part) DO NOTHING;
+
+GRANT ALL PRIVILEGES ON TABLE shift_staffing_rules TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE shift_staffing_rules_id_seq TO PUBLIC;
@@ -0,0 +1,3 @@
+-- Optimistic locking for records edited from the dashboard (see If-Match / ETag handling)
+ALTER TABLE pets ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+ALTER TABLE marketing_campaigns ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
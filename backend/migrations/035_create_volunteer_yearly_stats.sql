@@ -0,0 +1,19 @@
+-- Per-year snapshot so prior years survive the January reset of volunteers.total_hours/streak
+CREATE TABLE IF NOT EXISTS volunteer_yearly_stats (
+    volunteer_id bigint NOT NULL REFERENCES volunteers(id) ON DELETE CASCADE,
+    year integer NOT NULL,
+    total_hours numeric(8, 2) NOT NULL DEFAULT 0,
+    shifts_completed integer NOT NULL DEFAULT 0,
+    reliability_score integer NOT NULL DEFAULT 0,
+    streak integer NOT NULL DEFAULT 0,
+    longest_streak integer NOT NULL DEFAULT 0,
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (volunteer_id, year)
+);
+
+CREATE INDEX IF NOT EXISTS idx_volunteer_yearly_stats_year ON volunteer_yearly_stats(year, total_hours DESC);
+
+-- Volunteers must opt in before appearing on the public leaderboard
+ALTER TABLE volunteers ADD COLUMN IF NOT EXISTS leaderboard_opt_in boolean NOT NULL DEFAULT false;
+
+GRANT ALL PRIVILEGES ON TABLE volunteer_yearly_stats TO PUBLIC;
@@ -0,0 +1,5 @@
+-- Carried over from the volunteer application so minors' guardians can be
+-- notified about schedule changes
+ALTER TABLE volunteers ADD COLUMN IF NOT EXISTS parent_name text NOT NULL DEFAULT '';
+ALTER TABLE volunteers ADD COLUMN IF NOT EXISTS parent_email text NOT NULL DEFAULT '';
+ALTER TABLE volunteers ADD COLUMN IF NOT EXISTS parent_phone text NOT NULL DEFAULT '';
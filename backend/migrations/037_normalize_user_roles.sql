@@ -0,0 +1,22 @@
+-- Roles are now checked against the permission map in internal/data/permissions.go.
+-- Normalise the legacy lowercase values the API never matched against.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+
+UPDATE users SET role = CASE LOWER(role)
+    WHEN 'tier_1' THEN 'VOLUNTEER_1'
+    WHEN 'tier_2' THEN 'VOLUNTEER_2'
+    ELSE UPPER(role)
+END;
+
+UPDATE invitations SET role = CASE LOWER(role)
+    WHEN 'tier_1' THEN 'VOLUNTEER_1'
+    WHEN 'tier_2' THEN 'VOLUNTEER_2'
+    ELSE UPPER(role)
+END;
+
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'VOLUNTEER_1';
+
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN (
+    'SUPER_ADMIN', 'ADMIN', 'FOSTER_COORDINATOR', 'MEDICAL_LEAD', 'VOLUNTEER_COORDINATOR',
+    'MARKETING', 'BOARD_MEMBER', 'VOLUNTEER_2', 'VOLUNTEER_1', 'TEEN'
+)) NOT VALID;
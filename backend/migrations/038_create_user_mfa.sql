@@ -0,0 +1,34 @@
+-- TOTP enrollment. enabled stays false until the first code is confirmed.
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id text PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    totp_secret text NOT NULL,
+    enabled boolean NOT NULL DEFAULT false,
+    last_used_step bigint NOT NULL DEFAULT 0, -- rejects replayed codes
+    confirmed_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+-- One-time recovery codes, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS user_mfa_recovery_codes (
+    id bigserial PRIMARY KEY,
+    user_id text NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash bytea NOT NULL,
+    used_at timestamp(0) with time zone
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_mfa_recovery_codes_user ON user_mfa_recovery_codes(user_id);
+
+-- Issued after a correct password when a second factor is still needed
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    token_hash bytea PRIMARY KEY,
+    user_id text NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempts integer NOT NULL DEFAULT 0,
+    expiry timestamp(0) with time zone NOT NULL
+);
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS mfa_verified boolean NOT NULL DEFAULT false;
+
+GRANT ALL PRIVILEGES ON TABLE user_mfa TO PUBLIC;
+GRANT ALL PRIVILEGES ON TABLE user_mfa_recovery_codes TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE user_mfa_recovery_codes_id_seq TO PUBLIC;
+GRANT ALL PRIVILEGES ON TABLE mfa_challenges TO PUBLIC;
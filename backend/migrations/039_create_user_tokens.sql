@@ -0,0 +1,18 @@
+-- Single-use tokens for email verification and password reset. Only the
+-- SHA-256 hash is stored; the plaintext goes out in the email link.
+CREATE TABLE IF NOT EXISTS user_tokens (
+    hash bytea PRIMARY KEY,
+    user_id text NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope text NOT NULL,
+    expiry timestamp(0) with time zone NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_scope ON user_tokens(user_id, scope);
+
+-- Set once the address is confirmed. Existing accounts were created by invite
+-- or by hand, so treat them as verified.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at timestamp(0) with time zone;
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL AND activated = true;
+
+GRANT ALL PRIVILEGES ON TABLE user_tokens TO PUBLIC;
@@ -0,0 +1,19 @@
+-- Stable ID so sessions can be listed and revoked without exposing tokens
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS id bigserial;
+ALTER TABLE sessions ADD CONSTRAINT sessions_id_key UNIQUE (id);
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT NOW();
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS last_seen_at timestamptz NOT NULL DEFAULT NOW();
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS rotated_at timestamptz NOT NULL DEFAULT NOW();
+
+-- The token replaced by the last rotation stays valid until previous_valid_until
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS previous_token text;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS previous_valid_until timestamptz;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_previous_token ON sessions(previous_token) WHERE previous_token IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions(user_id);
+
+-- Download tokens are tied to the session that issued them
+ALTER TABLE user_tokens ADD COLUMN IF NOT EXISTS session_id bigint REFERENCES sessions(id) ON DELETE CASCADE;
+
+GRANT ALL PRIVILEGES ON SEQUENCE sessions_id_seq TO PUBLIC;
@@ -0,0 +1,18 @@
+-- Machine credentials for kennel cards and partner scripts. Only the SHA-256
+-- of the key is stored; prefix is kept so admins can tell keys apart.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    prefix text NOT NULL,
+    key_hash bytea NOT NULL UNIQUE,
+    scopes text[] NOT NULL DEFAULT '{}',
+    created_by text REFERENCES users(id) ON DELETE SET NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    expires_at timestamp(0) with time zone,
+    last_used_at timestamp(0) with time zone,
+    last_used_ip text,
+    revoked_at timestamp(0) with time zone
+);
+
+GRANT ALL PRIVILEGES ON TABLE api_keys TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE api_keys_id_seq TO PUBLIC;
@@ -0,0 +1,20 @@
+-- Every password/MFA login attempt, for throttling and the admin audit view.
+-- email is stored as typed (lowercased) so attempts on unknown accounts count too.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id bigserial PRIMARY KEY,
+    email text NOT NULL,
+    user_id text REFERENCES users(id) ON DELETE SET NULL,
+    ip text NOT NULL,
+    user_agent text NOT NULL DEFAULT '',
+    success boolean NOT NULL,
+    reason text NOT NULL DEFAULT '', -- 'password', 'mfa', 'locked', 'throttled', 'unlocked'
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email ON login_attempts(email, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts(ip, created_at DESC);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until timestamp(0) with time zone;
+
+GRANT ALL PRIVILEGES ON TABLE login_attempts TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE login_attempts_id_seq TO PUBLIC;
@@ -0,0 +1,14 @@
+-- In-flight single sign-on logins. Rows live for a few minutes between the
+-- redirect to the identity provider and the callback; the state is stored
+-- hashed and the PKCE verifier and nonce never leave the server.
+CREATE TABLE IF NOT EXISTS oidc_states (
+    state_hash bytea PRIMARY KEY,
+    code_verifier text NOT NULL,
+    nonce text NOT NULL,
+    redirect_path text NOT NULL DEFAULT '/',
+    expiry timestamp(0) with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_states_expiry ON oidc_states(expiry);
+
+GRANT ALL PRIVILEGES ON TABLE oidc_states TO PUBLIC;
@@ -0,0 +1,36 @@
+-- Append-only record of every administrative write: who (user or API key),
+-- which request, what resource, the field-level before/after diff and from where.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    actor_id text,               -- users.id; no FK so entries outlive the account
+    api_key_id bigint,
+    request_id text NOT NULL DEFAULT '',
+    action text NOT NULL,        -- 'create', 'update', 'delete' or a verb like 'revoke'
+    resource_type text NOT NULL,
+    resource_id text NOT NULL DEFAULT '',
+    changes jsonb NOT NULL DEFAULT '{}',
+    ip text NOT NULL DEFAULT '',
+    method text NOT NULL DEFAULT '',
+    path text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log(resource_type, resource_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_request ON audit_log(request_id);
+
+-- Enforce append-only in the database, not just in the API
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+GRANT ALL PRIVILEGES ON TABLE audit_log TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE audit_log_id_seq TO PUBLIC;
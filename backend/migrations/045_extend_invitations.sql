@@ -0,0 +1,11 @@
+-- Invitations get a stable id so admins can list, resend and revoke them
+-- without handling the token, plus who sent them and how often.
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS id bigserial;
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS invited_by text REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS sent_count integer NOT NULL DEFAULT 1;
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS last_sent_at timestamp(0) with time zone NOT NULL DEFAULT NOW();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_id ON invitations(id);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations(LOWER(email));
+
+GRANT ALL PRIVILEGES ON SEQUENCE invitations_id_seq TO PUBLIC;
@@ -0,0 +1,19 @@
+-- Receipts for privacy requests (exports and erasures). The subject is kept
+-- only as SHA-256 hashes of the normalised email/phone, so an erased person
+-- isn't re-identified by the receipt but a later request can be matched to it.
+CREATE TABLE IF NOT EXISTS data_subject_requests (
+    id bigserial PRIMARY KEY,
+    kind text NOT NULL, -- 'export', 'erasure'
+    email_hash bytea,
+    phone_hash bytea,
+    requested_by text REFERENCES users(id) ON DELETE SET NULL,
+    counts jsonb NOT NULL DEFAULT '{}',
+    retained jsonb NOT NULL DEFAULT '{}',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dsr_email_hash ON data_subject_requests(email_hash);
+CREATE INDEX IF NOT EXISTS idx_dsr_created_at ON data_subject_requests(created_at DESC);
+
+GRANT ALL PRIVILEGES ON TABLE data_subject_requests TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE data_subject_requests_id_seq TO PUBLIC;
@@ -0,0 +1,46 @@
+-- Foster parents as their own records, plus the history of which pets were
+-- placed with them. pets.foster is kept in sync with the open placement so
+-- existing pet views keep working.
+CREATE TABLE IF NOT EXISTS foster_homes (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    name text NOT NULL,
+    email text NOT NULL DEFAULT '',
+    phone text NOT NULL DEFAULT '',
+    address text NOT NULL DEFAULT '',
+    city text NOT NULL DEFAULT '',
+    zip text NOT NULL DEFAULT '',
+    status text NOT NULL DEFAULT 'pending', -- 'pending', 'approved', 'on_hold', 'inactive', 'rejected'
+    capacity integer NOT NULL DEFAULT 1,
+    species_preferences text[] NOT NULL DEFAULT '{}',
+    age_preferences text[] NOT NULL DEFAULT '{}', -- 'bottle_baby', 'kitten', 'adult', 'senior'
+    other_pets text NOT NULL DEFAULT '',
+    notes text NOT NULL DEFAULT '',
+    approved_at timestamp(0) with time zone,
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_foster_homes_status ON foster_homes(status);
+CREATE INDEX IF NOT EXISTS idx_foster_homes_email ON foster_homes(LOWER(email));
+
+CREATE TABLE IF NOT EXISTS foster_placements (
+    id bigserial PRIMARY KEY,
+    foster_id bigint NOT NULL REFERENCES foster_homes(id) ON DELETE CASCADE,
+    pet_id text NOT NULL, -- pets.id as text; the column type differs between environments
+    start_date date NOT NULL DEFAULT CURRENT_DATE,
+    end_date date,
+    end_reason text NOT NULL DEFAULT '', -- 'adopted', 'returned', 'transferred', ...
+    notes text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+-- A pet can only be in one foster home at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_foster_placements_open_pet ON foster_placements(pet_id) WHERE end_date IS NULL;
+CREATE INDEX IF NOT EXISTS idx_foster_placements_foster ON foster_placements(foster_id, start_date DESC);
+
+GRANT ALL PRIVILEGES ON TABLE foster_homes TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE foster_homes_id_seq TO PUBLIC;
+GRANT ALL PRIVILEGES ON TABLE foster_placements TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE foster_placements_id_seq TO PUBLIC;
@@ -0,0 +1,33 @@
+-- Foster portal: a foster home can be linked to a login, and fosters submit
+-- weights, notes and photos for the pets placed with them. Nothing reaches
+-- the pet's profile until a coordinator approves it.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN (
+    'SUPER_ADMIN', 'ADMIN', 'FOSTER_COORDINATOR', 'MEDICAL_LEAD', 'VOLUNTEER_COORDINATOR',
+    'MARKETING', 'BOARD_MEMBER', 'VOLUNTEER_2', 'VOLUNTEER_1', 'TEEN', 'FOSTER'
+)) NOT VALID;
+
+ALTER TABLE foster_homes ADD COLUMN IF NOT EXISTS user_id text UNIQUE REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS foster_updates (
+    id bigserial PRIMARY KEY,
+    foster_id bigint NOT NULL REFERENCES foster_homes(id) ON DELETE CASCADE,
+    pet_id text NOT NULL,
+    kind text NOT NULL, -- 'weight', 'note', 'photo'
+    weight_lbs numeric(6, 2),
+    note text NOT NULL DEFAULT '',
+    photo_url text,
+    thumbnail_url text,
+    status text NOT NULL DEFAULT 'pending', -- 'pending', 'approved', 'rejected'
+    submitted_by text REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_by text REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at timestamp(0) with time zone,
+    review_note text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_foster_updates_status ON foster_updates(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_foster_updates_pet ON foster_updates(pet_id, created_at DESC);
+
+GRANT ALL PRIVILEGES ON TABLE foster_updates TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE foster_updates_id_seq TO PUBLIC;
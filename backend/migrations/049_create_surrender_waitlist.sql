@@ -0,0 +1,22 @@
+-- Triage and waitlist state for surrender applications. Scores are computed
+-- in Go at submission (or on re-triage) and stored so the waitlist can be
+-- ordered in SQL.
+CREATE TABLE IF NOT EXISTS surrender_waitlist (
+    application_id bigint PRIMARY KEY REFERENCES applications(id) ON DELETE CASCADE,
+    urgency integer NOT NULL DEFAULT 0, -- 0-100, from how soon the animal must go
+    risk integer NOT NULL DEFAULT 0,    -- 0-100, from bite and escape history
+    priority integer NOT NULL DEFAULT 0,
+    level text NOT NULL DEFAULT 'low',  -- 'high', 'medium', 'low'
+    needed_by date,
+    reasons text[] NOT NULL DEFAULT '{}',
+    status text NOT NULL DEFAULT 'waiting', -- 'waiting', 'scheduled', 'accepted', 'declined', 'withdrawn'
+    intake_date date,
+    notes text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_surrender_waitlist_queue ON surrender_waitlist(status, priority DESC, created_at);
+
+GRANT ALL PRIVILEGES ON TABLE surrender_waitlist TO PUBLIC;
@@ -0,0 +1,6 @@
+-- The pet record an application produced, e.g. an accepted surrender
+-- converted at intake. pets.id as text; the column type differs between
+-- environments.
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS pet_id text;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_applications_pet_id ON applications(pet_id) WHERE pet_id IS NOT NULL;
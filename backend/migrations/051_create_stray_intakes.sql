@@ -0,0 +1,32 @@
+-- Strays taken in by the rescue. Each has a mandatory hold so an owner can
+-- reclaim it; the pet cannot be listed as available until hold_until.
+CREATE TABLE IF NOT EXISTS stray_intakes (
+    id bigserial PRIMARY KEY,
+    pet_id text NOT NULL, -- pets.id as text; the column type differs between environments
+    finder_name text NOT NULL DEFAULT '',
+    finder_email text NOT NULL DEFAULT '',
+    finder_phone text NOT NULL DEFAULT '',
+    found_date date NOT NULL,
+    found_location text NOT NULL DEFAULT '',
+    latitude double precision,
+    longitude double precision,
+    microchip_scan text NOT NULL DEFAULT 'not_scanned', -- 'not_scanned', 'none', 'found'
+    microchip_number text NOT NULL DEFAULT '',
+    intake_date date NOT NULL DEFAULT CURRENT_DATE,
+    hold_days integer NOT NULL CHECK (hold_days > 0),
+    hold_until date NOT NULL,
+    status text NOT NULL DEFAULT 'holding', -- 'holding', 'reclaimed', 'released'
+    owner_name text NOT NULL DEFAULT '',
+    owner_contact text NOT NULL DEFAULT '',
+    reclaimed_at timestamp(0) with time zone,
+    notes text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_stray_intakes_pet ON stray_intakes(pet_id);
+CREATE INDEX IF NOT EXISTS idx_stray_intakes_status ON stray_intakes(status, found_date DESC);
+
+GRANT ALL PRIVILEGES ON TABLE stray_intakes TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE stray_intakes_id_seq TO PUBLIC;
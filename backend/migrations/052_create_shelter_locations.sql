@@ -0,0 +1,35 @@
+-- Rooms and kennels at the shelter, and which pet was in which over time.
+-- pets.details.shelterLocation is kept in sync with the open assignment.
+CREATE TABLE IF NOT EXISTS shelter_locations (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    kind text NOT NULL DEFAULT 'room', -- 'room', 'kennel', 'isolation'
+    capacity integer NOT NULL DEFAULT 1 CHECK (capacity >= 0),
+    notes text NOT NULL DEFAULT '',
+    active boolean NOT NULL DEFAULT true,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shelter_locations_name ON shelter_locations(LOWER(name));
+
+CREATE TABLE IF NOT EXISTS pet_locations (
+    id bigserial PRIMARY KEY,
+    pet_id text NOT NULL, -- pets.id as text; the column type differs between environments
+    location_id bigint NOT NULL REFERENCES shelter_locations(id),
+    start_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    end_at timestamp(0) with time zone,
+    notes text NOT NULL DEFAULT '',
+    moved_by text REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (end_at IS NULL OR end_at >= start_at)
+);
+
+-- A pet is in one place at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pet_locations_open_pet ON pet_locations(pet_id) WHERE end_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pet_locations_location ON pet_locations(location_id) WHERE end_at IS NULL;
+
+GRANT ALL PRIVILEGES ON TABLE shelter_locations TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE shelter_locations_id_seq TO PUBLIC;
+GRANT ALL PRIVILEGES ON TABLE pet_locations TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE pet_locations_id_seq TO PUBLIC;
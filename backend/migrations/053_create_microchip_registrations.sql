@@ -0,0 +1,46 @@
+-- Microchip numbers live in pets.medical.microchip.microchipID. They're
+-- compared without spaces, dashes or case, since that's how they get typed.
+
+-- One pet per chip. Older imported records may already share a number; in
+-- that case fall back to a plain index and let the API refuse new duplicates
+-- until staff have sorted the old ones out.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pets
+        WHERE COALESCE(medical->'microchip'->>'microchipID', '') <> ''
+        GROUP BY UPPER(REGEXP_REPLACE(medical->'microchip'->>'microchipID', '[^A-Za-z0-9]', '', 'g'))
+        HAVING COUNT(*) > 1
+    ) THEN
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_pets_microchip ON pets (UPPER(REGEXP_REPLACE(medical->'microchip'->>'microchipID', '[^A-Za-z0-9]', '', 'g')))
+            WHERE COALESCE(medical->'microchip'->>'microchipID', '') <> '';
+    ELSE
+        RAISE NOTICE 'pets share microchip numbers; idx_pets_microchip created without UNIQUE';
+        CREATE INDEX IF NOT EXISTS idx_pets_microchip ON pets (UPPER(REGEXP_REPLACE(medical->'microchip'->>'microchipID', '[^A-Za-z0-9]', '', 'g')))
+            WHERE COALESCE(medical->'microchip'->>'microchipID', '') <> '';
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_stray_intakes_microchip ON stray_intakes (UPPER(REGEXP_REPLACE(microchip_number, '[^A-Za-z0-9]', '', 'g')))
+    WHERE microchip_number <> '';
+
+-- Whether an adopted pet's chip has been moved into the adopter's name.
+-- The adopter's contact stays on pets.adoption rather than being copied here.
+CREATE TABLE IF NOT EXISTS microchip_registrations (
+    pet_id text PRIMARY KEY, -- pets.id as text; the column type differs between environments
+    chip_number text NOT NULL,
+    company text NOT NULL DEFAULT '',
+    adoption_date date NOT NULL,
+    status text NOT NULL DEFAULT 'pending', -- 'pending', 'transferred', 'waived'
+    transferred_at timestamp(0) with time zone,
+    reminders_sent integer NOT NULL DEFAULT 0,
+    last_reminder_at timestamp(0) with time zone,
+    notes text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_microchip_registrations_pending ON microchip_registrations(adoption_date) WHERE status = 'pending';
+
+GRANT ALL PRIVILEGES ON TABLE microchip_registrations TO PUBLIC;
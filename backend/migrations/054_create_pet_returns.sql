@@ -0,0 +1,30 @@
+-- Returned adoptions. The adoption and adopter are copied here when the
+-- pet's own adoption block is cleared for its next home.
+CREATE TABLE IF NOT EXISTS pet_returns (
+    id bigserial PRIMARY KEY,
+    pet_id text NOT NULL, -- pets.id as text; the column type differs between environments
+    application_id bigint REFERENCES applications(id) ON DELETE SET NULL,
+    adopter_name text NOT NULL DEFAULT '',
+    adopter_email text NOT NULL DEFAULT '',
+    adopter_phone text NOT NULL DEFAULT '',
+    adoption_date date,
+    return_date date NOT NULL,
+    days_kept integer,
+    reason text NOT NULL,
+    details text NOT NULL DEFAULT '',
+    fee integer NOT NULL DEFAULT 0,
+    refund integer NOT NULL DEFAULT 0 CHECK (refund >= 0 AND refund <= fee),
+    refund_policy text NOT NULL DEFAULT '',
+    flag_adopter boolean NOT NULL DEFAULT true,
+    processed_by text REFERENCES users(id) ON DELETE SET NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_pet_returns_pet ON pet_returns(pet_id);
+CREATE INDEX IF NOT EXISTS idx_pet_returns_return_date ON pet_returns(return_date);
+CREATE INDEX IF NOT EXISTS idx_pet_returns_adopter_email ON pet_returns(LOWER(adopter_email)) WHERE adopter_email <> '';
+
+GRANT ALL PRIVILEGES ON TABLE pet_returns TO PUBLIC;
+GRANT ALL PRIVILEGES ON SEQUENCE pet_returns_id_seq TO PUBLIC;
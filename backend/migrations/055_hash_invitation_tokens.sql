@@ -0,0 +1,10 @@
+-- Invitation links are stored as a SHA-256 of the token, like MFA challenges
+-- and API keys, so a database read can't be turned into an admin account.
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS token_hash bytea;
+UPDATE invitations SET token_hash = sha256(convert_to(token, 'UTF8')) WHERE token_hash IS NULL;
+ALTER TABLE invitations ALTER COLUMN token_hash SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_token_hash ON invitations(token_hash);
+
+ALTER TABLE invitations DROP CONSTRAINT IF EXISTS invitations_pkey;
+ALTER TABLE invitations DROP COLUMN IF EXISTS token;
+ALTER TABLE invitations ADD PRIMARY KEY (id);
@@ -0,0 +1,14 @@
+-- The audit log stays append-only, with one exception: a privacy erasure may
+-- rewrite an entry's changes to redact the person, and nothing else about it.
+-- The erasure turns this on for its own transaction with
+-- set_config('audit_log.erasure', 'on', true).
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND current_setting('audit_log.erasure', true) = 'on'
+        AND to_jsonb(NEW) - 'changes' = to_jsonb(OLD) - 'changes' THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,20 @@
+-- Foster portal invites name their foster home; accepting one links the new
+-- account to it, instead of linking any account that shares the email.
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS foster_id bigint REFERENCES foster_homes(id) ON DELETE CASCADE;
+
+UPDATE invitations i SET foster_id = f.id
+FROM foster_homes f
+WHERE i.role = 'FOSTER' AND i.foster_id IS NULL AND f.user_id IS NULL AND LOWER(f.email) = LOWER(i.email);
+
+-- Accounts that accepted a foster invite before this were only linked on
+-- their first portal visit; link them now. FOSTER is only granted by invite.
+UPDATE foster_homes f SET user_id = m.user_id
+FROM (
+    SELECT DISTINCT ON (u.id) u.id AS user_id, h.id AS foster_id
+    FROM users u
+    JOIN foster_homes h ON LOWER(h.email) = LOWER(u.email) AND h.user_id IS NULL
+    WHERE u.role = 'FOSTER'
+    AND NOT EXISTS (SELECT 1 FROM foster_homes o WHERE o.user_id = u.id)
+    ORDER BY u.id, h.id
+) m
+WHERE f.id = m.foster_id;
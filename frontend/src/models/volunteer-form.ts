@@ -20,6 +20,8 @@ export interface IVolunteerFormState {
   signatureData: string | null
   signatureDate: string
   parentName: string
+  parentEmail: string
+  parentPhone: string
   parentSignatureData: string | null
   parentSignatureDate: string
 }
@@ -69,26 +69,64 @@ describe('useAuthStore', () => {
             json: async () => ({ token: 'abc-123', user: MOCK_USER })
         } as Response)
 
-        const success = await store.login('test@example.com', 'password')
+        const result = await store.login('test@example.com', 'password')
 
-        expect(success).toBe(true)
+        expect(result).toBe('ok')
         expect(localStorage.setItem).toHaveBeenCalledWith('token', 'abc-123')
         expect(store.user).toEqual(MOCK_USER)
     })
 
-    it('login failure returns false', async () => {
+    it('login failure returns failed', async () => {
         const store = useAuthStore()
         vi.mocked(fetch).mockResolvedValueOnce({
             ok: false,
             json: async () => ({ error: 'Invalid creds' })
         } as Response)
 
-        const success = await store.login('test@example.com', 'badpass')
+        const result = await store.login('test@example.com', 'badpass')
 
-        expect(success).toBe(false)
+        expect(result).toBe('failed')
         expect(store.user).toBeNull()
     })
 
+    it('login with MFA waits for the code', async () => {
+        const store = useAuthStore()
+        vi.mocked(fetch).mockResolvedValueOnce({
+            ok: true,
+            json: async () => ({ mfaRequired: true, challengeToken: 'challenge-1' })
+        } as Response)
+
+        const result = await store.login('test@example.com', 'password')
+
+        expect(result).toBe('mfa')
+        expect(store.mfaChallenge).toBe('challenge-1')
+        expect(store.user).toBeNull()
+
+        vi.mocked(fetch).mockResolvedValueOnce({
+            ok: true,
+            json: async () => ({ token: 'abc-123', user: MOCK_USER })
+        } as Response)
+
+        expect(await store.verifyMFA('123456')).toBe(true)
+        expect(localStorage.setItem).toHaveBeenCalledWith('token', 'abc-123')
+        expect(store.mfaChallenge).toBeNull()
+        expect(store.user).toEqual(MOCK_USER)
+    })
+
+    it('completeSSO swaps the code for a token', async () => {
+        const store = useAuthStore()
+        vi.mocked(fetch).mockResolvedValueOnce({
+            ok: true,
+            json: async () => ({ token: 'sso-token', user: MOCK_USER })
+        } as Response)
+
+        expect(await store.completeSSO('one-time')).toBe(true)
+        expect(fetch).toHaveBeenCalledWith('/api/auth/oidc/session', expect.objectContaining({
+            body: JSON.stringify({ code: 'one-time' })
+        }))
+        expect(localStorage.setItem).toHaveBeenCalledWith('token', 'sso-token')
+    })
+
     it('logout clears state and reloads', async () => {
         const store = useAuthStore()
         store.user = MOCK_USER
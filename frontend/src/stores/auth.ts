@@ -1,6 +1,10 @@
 import { defineStore } from 'pinia'
 import { computed,ref } from 'vue'
 
+import { API_BASE_URL } from '../constants/api'
+
+export type LoginResult = 'ok' | 'mfa' | 'failed'
+
 export const useAuthStore = defineStore('auth', () => {
   const user = ref<{
     ID: number
@@ -30,7 +34,22 @@ export const useAuthStore = defineStore('auth', () => {
     }
   }
 
-  const login = async (email: string, password: string): Promise<boolean> => {
+  const mfaChallenge = ref<string | null>(null)
+
+  const startSession = async (data: { token?: string; user?: typeof user.value }) => {
+    if (data.token) {
+      localStorage.setItem('token', data.token)
+    }
+
+    if (data.user) {
+      user.value = data.user
+    } else {
+      await checkAuth()
+    }
+    mfaChallenge.value = null
+  }
+
+  const login = async (email: string, password: string): Promise<LoginResult> => {
     try {
       const response = await fetch('/api/login', {
         method: 'POST',
@@ -44,21 +63,55 @@ export const useAuthStore = defineStore('auth', () => {
       const data = await response.json()
 
       if (response.ok) {
-
-        if (data.token) {
-          localStorage.setItem('token', data.token)
-        }
-
-        if (data.user) {
-          user.value = data.user
-        } else {
-          await checkAuth()
+        if (data.mfaRequired) {
+          mfaChallenge.value = data.challengeToken
+          return 'mfa'
         }
-        return true
+        await startSession(data)
+        return 'ok'
       }
-      return false
+      return 'failed'
     } catch (error) {
       console.error('Login error:', error)
+      return 'failed'
+    }
+  }
+
+  const verifyMFA = async (code: string): Promise<boolean> => {
+    if (!mfaChallenge.value) return false
+    try {
+      const response = await fetch('/api/login/mfa', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        credentials: 'include',
+        body: JSON.stringify({ challengeToken: mfaChallenge.value, code }),
+      })
+      if (!response.ok) return false
+      await startSession(await response.json())
+      return true
+    } catch (error) {
+      console.error('MFA error:', error)
+      return false
+    }
+  }
+
+  // Single sign-on lands back on /login with a one-time code to swap for the session
+  const ssoLoginURL = (redirect = '/admin') =>
+    `${API_BASE_URL}/api/auth/oidc/login?redirect=${encodeURIComponent(redirect)}`
+
+  const completeSSO = async (code: string): Promise<boolean> => {
+    try {
+      const response = await fetch('/api/auth/oidc/session', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        credentials: 'include',
+        body: JSON.stringify({ code }),
+      })
+      if (!response.ok) return false
+      await startSession(await response.json())
+      return true
+    } catch (error) {
+      console.error('SSO error:', error)
       return false
     }
   }
@@ -89,6 +142,10 @@ export const useAuthStore = defineStore('auth', () => {
     user,
     isAuthenticated,
     login,
+    mfaChallenge,
+    verifyMFA,
+    ssoLoginURL,
+    completeSSO,
     checkAuth,
     logout,
     initialize,
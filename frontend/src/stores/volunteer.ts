@@ -34,6 +34,8 @@ export const useVolunteerStore = defineStore('volunteer', () => {
     signatureData: null,
     signatureDate: '',
     parentName: '',
+    parentEmail: '',
+    parentPhone: '',
     parentSignatureData: null,
     parentSignatureDate: '',
   })
@@ -86,6 +88,10 @@ export const useVolunteerStore = defineStore('volunteer', () => {
 
     if (formState.age !== null && formState.age < 21) {
       if (!formState.parentName) errors.push('Parent Name')
+      if (!formState.parentEmail) errors.push('Parent Email')
+      if (formState.parentEmail && !/^[^\s@]+@[^\s@]+\.[^\s@]+$/.test(formState.parentEmail))
+        errors.push('Valid Parent Email')
+      if (!formState.parentPhone) errors.push('Parent Phone')
       if (!formState.parentSignatureDate) errors.push('Parent Date')
       if (!formState.parentSignatureData) errors.push('Parent Signature')
     }
@@ -120,6 +126,8 @@ export const useVolunteerStore = defineStore('volunteer', () => {
     formState.signatureData = null
     formState.signatureDate = ''
     formState.parentName = ''
+    formState.parentEmail = ''
+    formState.parentPhone = ''
     formState.parentSignatureData = null
     formState.parentSignatureDate = ''
   }
@@ -145,6 +153,8 @@ export const useVolunteerStore = defineStore('volunteer', () => {
 
     if (formState.age !== null && formState.age >= 21) {
       delete payload.parentName
+      delete payload.parentEmail
+      delete payload.parentPhone
       delete payload.parentSignatureData
       delete payload.parentSignatureDate
     }